@@ -3,27 +3,52 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/middleware"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
 	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
 	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+const (
+	testJWTSecret    = "e2e-test-secret-that-is-long-enough"
+	testUserEmail    = "e2e@example.com"
+	testUserPassword = "e2e-password"
 )
 
 type E2ETestSuite struct {
 	suite.Suite
-	db    *sqlx.DB
-	dbURL string
-	echo  *echo.Echo
+	db           *sqlx.DB
+	dbURL        string
+	echo         *echo.Echo
+	dispatcher   *services.EventDispatcher
+	authService  *services.AuthService
+	autocomplete *services.AutocompleteService
+	public       publicRoutes
+	accessToken  string
 }
 
 func (suite *E2ETestSuite) SetupSuite() {
@@ -39,33 +64,103 @@ func (suite *E2ETestSuite) SetupSuite() {
 
 	// Setup Echo app
 	suite.echo = echo.New()
+	suite.echo.HTTPErrorHandler = handlers.HTTPErrorHandler
+	suite.echo.IPExtractor = ipExtractor(nil)
 
 	// Initialize dependencies
-	neighborhoodRepo := repositories.NewNeighborhoodRepository(suite.db)
-	neighborhoodService := services.NewNeighborhoodService(neighborhoodRepo)
-	neighborhoodHandler := handlers.NewNeighborhoodHandler(neighborhoodService)
+	outboxRepo := repositories.NewOutboxRepository(suite.db)
+	auditRepo := repositories.NewAuditRepository(suite.db)
+	txManager := repositories.NewTxManager(suite.db)
+	suite.dispatcher = services.NewEventDispatcher(outboxRepo, txManager)
+
+	auditService := services.NewAuditService(auditRepo)
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(suite.db), auditRepo, txManager)
+	suite.dispatcher.SubscribeAll(webhookService.HandleEvent)
 
+	neighborhoodRepo := repositories.NewNeighborhoodRepository(suite.db)
 	buildingRepo := repositories.NewBuildingRepository(suite.db)
-	buildingService := services.NewBuildingService(buildingRepo, neighborhoodRepo)
-	buildingHandler := handlers.NewBuildingHandler(buildingService)
+	apartmentRepo := repositories.NewApartmentRepository(suite.db)
+	neighborhoodService := services.NewNeighborhoodService(neighborhoodRepo, outboxRepo, auditRepo, txManager)
+	buildingService := services.NewBuildingService(buildingRepo, neighborhoodRepo, outboxRepo, auditRepo, txManager)
+
+	userRepo := repositories.NewUserRepository(suite.db)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(suite.db)
+	suite.authService = services.NewAuthService(userRepo, refreshTokenRepo, txManager, testJWTSecret, 15*time.Minute, time.Hour)
+	userService := services.NewUserService(userRepo, refreshTokenRepo, auditRepo, txManager)
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(suite.db), auditRepo, txManager)
+	inquiryService := services.NewInquiryService(repositories.NewInquiryRepository(suite.db), repositories.NewBuildingRepository(suite.db), auditRepo, txManager)
+	apartmentService := services.NewApartmentService(apartmentRepo, buildingRepo, outboxRepo, auditRepo, txManager)
+	importService := services.NewImportService(repositories.NewImportRepository(suite.db), neighborhoodRepo, buildingRepo, apartmentRepo, neighborhoodService, buildingService, apartmentService, txManager)
+	organizationService := services.NewOrganizationService(repositories.NewOrganizationRepository(suite.db), userRepo, auditRepo, txManager)
+	searchRepo := repositories.NewSearchRepository(suite.db)
+	suite.autocomplete = services.NewAutocompleteService(repositories.NewOrganizationRepository(suite.db), neighborhoodRepo, repositories.NewBuildingRepository(suite.db), searchRepo, repositories.NewNotificationRepository(suite.db))
+	for _, eventType := range []models.EventType{models.EventNeighborhoodCreated, models.EventNeighborhoodDeleted, models.EventBuildingCreated, models.EventBuildingDeleted} {
+		suite.dispatcher.Subscribe(eventType, suite.autocomplete.HandleEvent)
+	}
 
 	// Setup routes
-	suite.echo.POST("/api/v1/neighborhoods", neighborhoodHandler.Create)
-	suite.echo.GET("/api/v1/neighborhoods/:id", neighborhoodHandler.Get)
-	suite.echo.PUT("/api/v1/neighborhoods/:id", neighborhoodHandler.Update)
-	suite.echo.DELETE("/api/v1/neighborhoods/:id", neighborhoodHandler.Delete)
-	suite.echo.GET("/api/v1/neighborhoods", neighborhoodHandler.List)
+	suite.echo.Use(middleware.CORS([]string{"https://www.example.com"}))
+	suite.echo.Use(middleware.RequestID())
+	suite.echo.Use(middleware.Logging(zap.NewNop()))
+	registerRoutes(suite.echo, routeHandlers{
+		health:       handlers.NewHealthHandler(suite.db, zap.NewNop(), nil),
+		auth:         handlers.NewAuthHandler(suite.authService),
+		neighborhood: handlers.NewNeighborhoodHandler(neighborhoodService),
+		building:     handlers.NewBuildingHandler(buildingService),
+		webhook:      handlers.NewWebhookHandler(webhookService),
+		audit:        handlers.NewAuditHandler(auditService),
+		user:         handlers.NewUserHandler(userService),
+		apiKey:       handlers.NewAPIKeyHandler(apiKeyService),
+		inquiry:      handlers.NewInquiryHandler(inquiryService),
+		apartment:    handlers.NewApartmentHandler(apartmentService),
+		organization: handlers.NewOrganizationHandler(organizationService),
+		search:       handlers.NewSearchHandler(services.NewSearchService(searchRepo)),
+		autocomplete: handlers.NewAutocompleteHandler(suite.autocomplete),
+		trash:        handlers.NewTrashHandler(services.NewTrashService(repositories.NewTrashRepository(suite.db), time.Hour)),
+		imports:      handlers.NewImportHandler(importService),
+		exports:      handlers.NewExportHandler(services.NewExportService(repositories.NewExportRepository(suite.db), txManager)),
+		feeds:        handlers.NewFeedHandler(services.NewFeedService(repositories.NewFeedRepository(suite.db), repositories.NewOrganizationRepository(suite.db), "USD", services.XMLFeedFormat{}, services.JSONLDFeedFormat{})),
+	}, middleware.RateLimit(middleware.RateLimitConfig{
+		Name:  "ip",
+		Store: middleware.NewMemoryRateLimitStore(),
+		Quota: models.RateLimitQuota{Rate: 1000, Burst: 1000},
+	}), middleware.Auth(suite.authService, apiKeyService), middleware.RateLimit(middleware.RateLimitConfig{
+		Name:  "api",
+		Store: middleware.NewMemoryRateLimitStore(),
+		Quota: models.RateLimitQuota{Rate: 1000, Burst: 1000},
+	}), middleware.Idempotency(repositories.NewIdempotencyRepository(suite.db)))
+	suite.public = publicRoutes{
+		handler:        handlers.NewPublicHandler(neighborhoodService, buildingService, apartmentService),
+		organizations:  organizationService,
+		cacheTTL:       5 * time.Minute,
+		rateLimit:      models.RateLimitQuota{Rate: 1000, Burst: 1000},
+		rateLimitStore: middleware.NewMemoryRateLimitStore(),
+	}
+	registerPublicRoutes(suite.echo, suite.public)
 
-	suite.echo.POST("/api/v1/buildings", buildingHandler.Create)
-	suite.echo.GET("/api/v1/buildings/:id", buildingHandler.Get)
-	suite.echo.PUT("/api/v1/buildings/:id", buildingHandler.Update)
-	suite.echo.DELETE("/api/v1/buildings/:id", buildingHandler.Delete)
-	suite.echo.GET("/api/v1/buildings", buildingHandler.List)
+	// Log in as the test admin
+	_, err = suite.authService.EnsureUser(context.Background(), models.DefaultOrganizationID, testUserEmail, testUserPassword, models.RoleAdmin)
+	suite.Require().NoError(err)
+	tokens, err := suite.authService.Login(context.Background(), testUserEmail, testUserPassword)
+	suite.Require().NoError(err)
+	suite.accessToken = tokens.AccessToken
+}
+
+// serve sends the request as the test user unless it already carries credentials.
+func (suite *E2ETestSuite) serve(rec *httptest.ResponseRecorder, req *http.Request) {
+	if req.Header.Get(echo.HeaderAuthorization) == "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+suite.accessToken)
+	}
+	suite.echo.ServeHTTP(rec, req)
 }
 
 func (suite *E2ETestSuite) TearDownTest() {
 	// Clean up test data after each test
-	_, err := suite.db.Exec("TRUNCATE TABLE buildings, neighborhoods RESTART IDENTITY")
+	_, err := suite.db.Exec("TRUNCATE TABLE import_errors, imports, apartments, api_keys, inquiries, audit_log, outbox, webhook_delivery_attempts, webhook_deliveries, webhook_subscriptions, buildings, neighborhoods, rate_limit_buckets, idempotency_keys RESTART IDENTITY")
+	suite.NoError(err)
+	_, err = suite.db.Exec("DELETE FROM users WHERE email <> $1", testUserEmail)
+	suite.NoError(err)
+	_, err = suite.db.Exec("DELETE FROM organizations WHERE id <> $1", models.DefaultOrganizationID)
 	suite.NoError(err)
 }
 
@@ -76,7 +171,7 @@ func (suite *E2ETestSuite) createNeighborhood(name string) string {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	var created map[string]string
 	_ = json.Unmarshal(rec.Body.Bytes(), &created) // assume success in helper
@@ -90,7 +185,7 @@ func (suite *E2ETestSuite) createBuilding(name string, neighborhoodID string, ad
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/buildings", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	var created map[string]string
 	_ = json.Unmarshal(rec.Body.Bytes(), &created) // assume success in helper
@@ -107,7 +202,7 @@ func (suite *E2ETestSuite) TestCreateNeighborhood() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
 
@@ -122,7 +217,7 @@ func (suite *E2ETestSuite) TestCreateNeighborhood_InvalidJSON() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -133,7 +228,7 @@ func (suite *E2ETestSuite) TestCreateNeighborhood_MissingName() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -145,7 +240,7 @@ func (suite *E2ETestSuite) TestGetNeighborhood() {
 	// Test Get
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods/"+id, nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -159,7 +254,7 @@ func (suite *E2ETestSuite) TestGetNeighborhood() {
 func (suite *E2ETestSuite) TestGetNeighborhood_NotFound() {
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods/11111111-1111-1111-1111-111111111111", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -167,7 +262,7 @@ func (suite *E2ETestSuite) TestGetNeighborhood_NotFound() {
 func (suite *E2ETestSuite) TestGetNeighborhood_InvalidID() {
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods/invalid", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -182,7 +277,7 @@ func (suite *E2ETestSuite) TestUpdateNeighborhood() {
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/neighborhoods/"+id, bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -199,7 +294,7 @@ func (suite *E2ETestSuite) TestUpdateNeighborhood_NotFound() {
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/neighborhoods/11111111-1111-1111-1111-111111111111", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -211,14 +306,14 @@ func (suite *E2ETestSuite) TestDeleteNeighborhood() {
 	// Test Delete
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/neighborhoods/"+id, nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
 
 	// Verify deleted: get should return 404
 	req = httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods/"+id, nil)
 	rec = httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -226,7 +321,7 @@ func (suite *E2ETestSuite) TestDeleteNeighborhood() {
 func (suite *E2ETestSuite) TestDeleteNeighborhood_NotFound() {
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/neighborhoods/11111111-1111-1111-1111-111111111111", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -241,7 +336,7 @@ func (suite *E2ETestSuite) TestListNeighborhoods() {
 	// Test List
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -259,6 +354,154 @@ func (suite *E2ETestSuite) TestListNeighborhoods() {
 	}
 }
 
+func (suite *E2ETestSuite) TestListNeighborhoods_CursorPagination() {
+	// Duplicate names must still page stably, ordered by (name, id)
+	names := []string{"Delta", "Alpha", "Charlie", "Alpha", "Bravo"}
+	for _, name := range names {
+		suite.createNeighborhood(name)
+	}
+
+	var seen []string
+	target := "/api/v1/neighborhoods?limit=2"
+	for pages := 0; ; pages++ {
+		suite.Require().Less(pages, len(names), "pagination did not terminate")
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, target, nil)
+		suite.Require().Equal(http.StatusOK, rec.Code)
+
+		var page services.ListPage[models.Neighborhood]
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.LessOrEqual(suite.T(), len(page.Data), 2)
+		for _, neighborhood := range page.Data {
+			seen = append(seen, neighborhood.Name)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		target = "/api/v1/neighborhoods?limit=2&cursor=" + *page.NextCursor
+	}
+	assert.Equal(suite.T(), []string{"Alpha", "Alpha", "Bravo", "Charlie", "Delta"}, seen)
+
+	// Clients that send no paging parameters still get a bare array
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/neighborhoods", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var all []models.Neighborhood
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &all))
+	assert.Len(suite.T(), all, len(names))
+	assert.Empty(suite.T(), rec.Header().Get("Link"))
+}
+
+func (suite *E2ETestSuite) TestListNeighborhoods_UnpagedOverMaxPageSize() {
+	_, err := suite.db.Exec(`INSERT INTO neighborhoods (id, tenant_id, name)
+		SELECT gen_random_uuid(), $1, 'Bulk ' || lpad(i::text, 3, '0') FROM generate_series(1, 205) AS i`, models.DefaultOrganizationID)
+	suite.Require().NoError(err)
+
+	// A bare array is cut at MaxPageSize and links to the rest of the list
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/neighborhoods?sort=-name", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var first []models.Neighborhood
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &first))
+	suite.Require().Len(first, services.MaxPageSize)
+	assert.Equal(suite.T(), "Bulk 205", first[0].Name)
+	link := rec.Header().Get("Link")
+	suite.Require().Regexp(`^</api/v1/neighborhoods\?\S+>; rel="next"$`, link)
+
+	next := strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, next, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var rest services.ListPage[models.Neighborhood]
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &rest))
+	suite.Require().Len(rest.Data, 5)
+	assert.Equal(suite.T(), "Bulk 005", rest.Data[0].Name)
+	assert.Equal(suite.T(), "Bulk 001", rest.Data[4].Name)
+	assert.Nil(suite.T(), rest.NextCursor)
+	assert.Empty(suite.T(), rec.Header().Get("Link"))
+}
+
+func (suite *E2ETestSuite) TestListPagination_InvalidParameters() {
+	for _, target := range []string{
+		"/api/v1/buildings?limit=0",
+		"/api/v1/buildings?limit=-1",
+		"/api/v1/buildings?limit=ten",
+		"/api/v1/buildings?cursor=not-a-cursor",
+		"/api/v1/apartments?cursor=eyJrIjoibm90LWEtdGltZSIsImlkIjoiMTExMTExMTEtMTExMS0xMTExLTExMTEtMTExMTExMTExMTExIn0",
+	} {
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, target, nil)
+		assert.Equal(suite.T(), http.StatusBadRequest, rec.Code, target)
+	}
+
+	// Oversized pages are capped rather than rejected
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings?limit=100000", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var page services.ListPage[models.Building]
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.NotNil(suite.T(), page.Data)
+	assert.Nil(suite.T(), page.NextCursor)
+}
+
+func (suite *E2ETestSuite) TestListBuildings_FilterAndSort() {
+	northID := suite.createNeighborhood("North")
+	southID := suite.createNeighborhood("South")
+	suite.createBuilding("Harbor View", northID, "1 Quay St")
+	suite.createBuilding("Hillside", northID, "2 Ridge Rd")
+	suite.createBuilding("Harbor Point", southID, "3 Quay St")
+	suite.createBuilding("50% Off Lofts", southID, "4 Sale St")
+
+	names := func(target string) []string {
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, target, nil)
+		suite.Require().Equal(http.StatusOK, rec.Code, target)
+		var buildings []models.Building
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &buildings))
+		result := []string{}
+		for _, building := range buildings {
+			result = append(result, building.Name)
+		}
+		return result
+	}
+
+	assert.Equal(suite.T(), []string{"Harbor View", "Hillside"}, names("/api/v1/buildings?filter[neighborhood_id]="+northID))
+	assert.Equal(suite.T(), []string{"Harbor View", "Harbor Point"}, names("/api/v1/buildings?filter[name][contains]=harbor&sort=-name"))
+	assert.Equal(suite.T(), []string{"Harbor Point"}, names("/api/v1/buildings?filter[name][contains]=harbor&filter[neighborhood_id]="+southID))
+	// LIKE wildcards in the search term match literally
+	assert.Equal(suite.T(), []string{"50% Off Lofts"}, names("/api/v1/buildings?filter[name][contains]=%25"))
+	assert.Equal(suite.T(), []string{"Harbor View", "Hillside", "Harbor Point", "50% Off Lofts"}, names("/api/v1/buildings?sort=address"))
+
+	// Sorted lists page with cursors too
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings?sort=-name&limit=3", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var page services.ListPage[models.Building]
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+	suite.Require().NotNil(page.NextCursor)
+	assert.Equal(suite.T(), []string{"Hillside", "Harbor View", "Harbor Point"}, []string{page.Data[0].Name, page.Data[1].Name, page.Data[2].Name})
+	assert.Equal(suite.T(), []string{"50% Off Lofts"}, names("/api/v1/buildings?sort=-name&limit=3&cursor="+*page.NextCursor))
+
+	// A cursor only continues the order it was issued for
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings?sort=name&limit=3&cursor="+*page.NextCursor, nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestListBuildings_InvalidQueryNamesParameter() {
+	for target, param := range map[string]string{
+		"/api/v1/buildings?filter[password]=x":                    "filter[password]",
+		"/api/v1/buildings?filter[neighborhood_id]=not-a-uuid":    "filter[neighborhood_id]",
+		"/api/v1/buildings?filter[neighborhood_id][contains]=abc": "filter[neighborhood_id][contains]",
+		"/api/v1/buildings?filter[name][gt]=abc":                  "filter[name][gt]",
+		"/api/v1/buildings?filter=name":                           "filter",
+		"/api/v1/buildings?sort=neighborhood_id":                  "sort",
+		"/api/v1/buildings?sort=name;DROP%20TABLE%20buildings":    "sort",
+		"/api/v1/buildings?limit=0":                               "limit",
+		"/api/v1/apartments?filter[price_from][gte]=cheap":        "filter[price_from][gte]",
+	} {
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, target, nil)
+		assert.Equal(suite.T(), http.StatusBadRequest, rec.Code, target)
+
+		var resp handlers.Problem
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(suite.T(), "invalid query parameter: "+param, resp.Detail, target)
+		suite.Require().Len(resp.Errors, 1, target)
+		assert.Equal(suite.T(), param, resp.Errors[0].Field, target)
+	}
+}
+
 func (suite *E2ETestSuite) TestCreateBuilding() {
 	// Seed: create a neighborhood first
 	neighborhoodID := suite.createNeighborhood("Test Neighborhood")
@@ -268,7 +511,7 @@ func (suite *E2ETestSuite) TestCreateBuilding() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/buildings", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
 
@@ -287,7 +530,7 @@ func (suite *E2ETestSuite) TestCreateBuilding_InvalidNeighborhood() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/buildings", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -296,7 +539,7 @@ func (suite *E2ETestSuite) TestCreateBuilding_InvalidJSON() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/buildings", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -307,7 +550,7 @@ func (suite *E2ETestSuite) TestCreateBuilding_MissingFields() {
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/buildings", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -320,7 +563,7 @@ func (suite *E2ETestSuite) TestGetBuilding() {
 	// Test Get
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings/"+id, nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -336,7 +579,7 @@ func (suite *E2ETestSuite) TestGetBuilding() {
 func (suite *E2ETestSuite) TestGetBuilding_NotFound() {
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings/11111111-1111-1111-1111-111111111111", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -344,7 +587,7 @@ func (suite *E2ETestSuite) TestGetBuilding_NotFound() {
 func (suite *E2ETestSuite) TestGetBuilding_InvalidID() {
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings/invalid", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
 }
@@ -360,7 +603,7 @@ func (suite *E2ETestSuite) TestUpdateBuilding() {
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/buildings/"+id, bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -380,7 +623,7 @@ func (suite *E2ETestSuite) TestUpdateBuilding_NotFound() {
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/buildings/11111111-1111-1111-1111-111111111111", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -393,14 +636,14 @@ func (suite *E2ETestSuite) TestDeleteBuilding() {
 	// Test Delete
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/buildings/"+id, nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
 
 	// Verify deleted: get should return 404
 	req = httptest.NewRequest(http.MethodGet, "/api/v1/buildings/"+id, nil)
 	rec = httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -408,7 +651,7 @@ func (suite *E2ETestSuite) TestDeleteBuilding() {
 func (suite *E2ETestSuite) TestDeleteBuilding_NotFound() {
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/buildings/11111111-1111-1111-1111-111111111111", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
 }
@@ -424,7 +667,7 @@ func (suite *E2ETestSuite) TestListBuildings() {
 	// Test List
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings", nil)
 	rec := httptest.NewRecorder()
-	suite.echo.ServeHTTP(rec, req)
+	suite.serve(rec, req)
 
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 
@@ -442,6 +685,1856 @@ func (suite *E2ETestSuite) TestListBuildings() {
 	}
 }
 
+func (suite *E2ETestSuite) TestCreateWebhook_InvalidEventType() {
+	createReq := map[string]interface{}{"url": "https://example.com/hooks", "event_types": []string{"building.exploded"}}
+	reqBody, _ := json.Marshal(createReq)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestWebhookDeliveryQueuedAndReplayed() {
+	// Seed: subscribe to building creation
+	createReq := map[string]interface{}{"url": "https://example.com/hooks", "event_types": []string{"building.created"}}
+	reqBody, _ := json.Marshal(createReq)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
+
+	var subscription map[string]interface{}
+	err := json.Unmarshal(rec.Body.Bytes(), &subscription)
+	suite.NoError(err)
+	assert.NotEmpty(suite.T(), subscription["secret"])
+	subscriptionID := subscription["id"].(string)
+
+	neighborhoodID := suite.createNeighborhood("Test Neighborhood")
+	suite.createBuilding("Test Building", neighborhoodID, "123 Test St")
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+
+	// A delivery is queued for the created building
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+subscriptionID+"/deliveries", nil)
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	var deliveries []map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &deliveries)
+	suite.NoError(err)
+	suite.Require().Len(deliveries, 1)
+	assert.Equal(suite.T(), "building.created", deliveries[0]["event_type"])
+	assert.Equal(suite.T(), "pending", deliveries[0]["status"])
+
+	// Replay it
+	deliveryID := deliveries[0]["id"].(string)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/deliveries/"+deliveryID+"/replay", nil)
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusAccepted, rec.Code)
+
+	var replay map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &replay)
+	suite.NoError(err)
+	assert.Equal(suite.T(), deliveryID, replay["replay_of"])
+
+	// The replay is audited like any other mutation
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/audit?entity_type=webhook_delivery&entity_id="+replay["id"].(string), nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var audit struct {
+		Data []struct {
+			Actor  string                                `json:"actor"`
+			Action string                                `json:"action"`
+			Diff   map[string]map[string]json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &audit))
+	suite.Require().Len(audit.Data, 1)
+	assert.Equal(suite.T(), "create", audit.Data[0].Action)
+	assert.Equal(suite.T(), testUserEmail, audit.Data[0].Actor)
+	assert.JSONEq(suite.T(), `"`+deliveryID+`"`, string(audit.Data[0].Diff["replay_of"]["after"]))
+
+	// Deliveries are paged by cursor, newest first
+	var page struct {
+		Data       []map[string]any `json:"data"`
+		NextCursor *string          `json:"next_cursor"`
+	}
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/webhooks/"+subscriptionID+"/deliveries?limit=1", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+	suite.Require().Len(page.Data, 1)
+	assert.Equal(suite.T(), replay["id"], page.Data[0]["id"])
+	suite.Require().NotNil(page.NextCursor)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/webhooks/"+subscriptionID+"/deliveries?limit=1&cursor="+*page.NextCursor, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	page.NextCursor = nil
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+	suite.Require().Len(page.Data, 1)
+	assert.Equal(suite.T(), deliveryID, page.Data[0]["id"])
+	assert.Nil(suite.T(), page.NextCursor)
+}
+
+func (suite *E2ETestSuite) TestWebhookDeliveryClaimedOnce() {
+	reqBody, _ := json.Marshal(map[string]any{"url": "https://example.com/hooks", "event_types": []string{"building.created"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	suite.createBuilding("Test Building", suite.createNeighborhood("Test Neighborhood"), "123 Test St")
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+
+	// A due delivery goes to one worker until its lease runs out
+	repo := repositories.NewWebhookRepository(suite.db)
+	now := time.Now().UTC()
+	claimed, err := repo.ClaimDueDeliveries(context.Background(), now, now.Add(time.Minute), 10)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), claimed, 1)
+	claimed, err = repo.ClaimDueDeliveries(context.Background(), now, now.Add(time.Minute), 10)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), claimed)
+	later := now.Add(2 * time.Minute)
+	claimed, err = repo.ClaimDueDeliveries(context.Background(), later, later.Add(time.Minute), 10)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), claimed, 1)
+}
+
+func (suite *E2ETestSuite) TestOutboxDispatchesEventsInOrder() {
+	var received []models.EventType
+	suite.dispatcher.Subscribe(models.EventBuildingCreated, func(_ context.Context, event models.DomainEvent) error {
+		received = append(received, event.Type)
+		return nil
+	})
+	suite.dispatcher.Subscribe(models.EventBuildingDeleted, func(_ context.Context, event models.DomainEvent) error {
+		received = append(received, event.Type)
+		return nil
+	})
+
+	neighborhoodID := suite.createNeighborhood("Test Neighborhood")
+	id := suite.createBuilding("Test Building", neighborhoodID, "123 Test St")
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/buildings/"+id, nil)
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+
+	// Every change wrote an event to the outbox in the same transaction
+	var types []string
+	err := suite.db.Select(&types, "SELECT event_type FROM outbox ORDER BY seq")
+	suite.NoError(err)
+	assert.Equal(suite.T(), []string{"neighborhood.created", "building.created", "building.deleted"}, types)
+
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+	assert.Equal(suite.T(), []models.EventType{models.EventBuildingCreated, models.EventBuildingDeleted}, received)
+
+	var pending int
+	err = suite.db.Get(&pending, "SELECT count(*) FROM outbox WHERE published_at IS NULL")
+	suite.NoError(err)
+	assert.Equal(suite.T(), 0, pending)
+}
+
+func (suite *E2ETestSuite) TestOutboxWaitsForOlderTransactions() {
+	// An older transaction, still running, may yet write events that come first
+	older, err := suite.db.Beginx()
+	suite.Require().NoError(err)
+	defer older.Rollback()
+	_, err = older.Exec("SELECT pg_current_xact_id()")
+	suite.Require().NoError(err)
+
+	suite.createNeighborhood("Test Neighborhood")
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+	var pending int
+	suite.Require().NoError(suite.db.Get(&pending, "SELECT count(*) FROM outbox WHERE published_at IS NULL"))
+	assert.Equal(suite.T(), 1, pending)
+
+	suite.Require().NoError(older.Rollback())
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+	suite.Require().NoError(suite.db.Get(&pending, "SELECT count(*) FROM outbox WHERE published_at IS NULL"))
+	assert.Equal(suite.T(), 0, pending)
+}
+
+func (suite *E2ETestSuite) TestOutboxParksFailingEvents() {
+	dispatcher := services.NewEventDispatcher(repositories.NewOutboxRepository(suite.db), repositories.NewTxManager(suite.db))
+	notifications := repositories.NewNotificationRepository(suite.db)
+	dispatcher.Subscribe(models.EventBuildingCreated, func(ctx context.Context, _ models.DomainEvent) error {
+		return notifications.Notify(ctx, "", "no channel")
+	})
+
+	neighborhoodID := suite.createNeighborhood("Test Neighborhood")
+	suite.createBuilding("Test Building", neighborhoodID, "123 Test St")
+	suite.createNeighborhood("Later Neighborhood")
+
+	var states []struct {
+		Type      string     `db:"event_type"`
+		Attempts  int        `db:"attempts"`
+		LastError *string    `db:"last_error"`
+		Published *time.Time `db:"published_at"`
+		Parked    *time.Time `db:"parked_at"`
+	}
+	load := func() {
+		states = nil
+		suite.Require().NoError(suite.db.Select(&states, "SELECT event_type, attempts, last_error, published_at, parked_at FROM outbox ORDER BY seq"))
+		suite.Require().Len(states, 3)
+	}
+
+	// The failed handler's changes are rolled back alone; the events after it wait
+	assert.Error(suite.T(), dispatcher.DispatchPending(context.Background()))
+	load()
+	assert.NotNil(suite.T(), states[0].Published)
+	assert.Nil(suite.T(), states[1].Published)
+	assert.Equal(suite.T(), 1, states[1].Attempts)
+	assert.NotNil(suite.T(), states[1].LastError)
+	assert.Nil(suite.T(), states[2].Published)
+
+	// Once its attempts run out it is parked, and the others go on
+	_, err := suite.db.Exec("UPDATE outbox SET attempts = 7, next_attempt_at = now() - interval '1 second' WHERE event_type = 'building.created'")
+	suite.Require().NoError(err)
+	assert.Error(suite.T(), dispatcher.DispatchPending(context.Background()))
+	load()
+	assert.Nil(suite.T(), states[1].Published)
+	assert.NotNil(suite.T(), states[1].Parked)
+	assert.Equal(suite.T(), 8, states[1].Attempts)
+	assert.NotNil(suite.T(), states[2].Published)
+}
+
+func (suite *E2ETestSuite) TestAuditLogRecordsBuildingChanges() {
+	oldNeighborhoodID := suite.createNeighborhood("Old Neighborhood")
+	newNeighborhoodID := suite.createNeighborhood("New Neighborhood")
+	id := suite.createBuilding("Test Building", oldNeighborhoodID, "123 Test St")
+
+	// Move the building to another neighborhood
+	updateReq := map[string]string{"name": "Test Building", "neighborhood_id": newNeighborhoodID, "address": "123 Test St"}
+	reqBody, _ := json.Marshal(updateReq)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/buildings/"+id, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/audit?entity_type=building&entity_id="+id, nil)
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	var page struct {
+		Data []struct {
+			Actor  string                                `json:"actor"`
+			Action string                                `json:"action"`
+			Diff   map[string]map[string]json.RawMessage `json:"diff"`
+		} `json:"data"`
+		NextCursor *string `json:"next_cursor"`
+	}
+	err := json.Unmarshal(rec.Body.Bytes(), &page)
+	suite.NoError(err)
+	suite.Require().Len(page.Data, 2)
+	assert.Nil(suite.T(), page.NextCursor)
+
+	// Newest first: the update only changed the neighborhood
+	update := page.Data[0]
+	assert.Equal(suite.T(), "update", update.Action)
+	assert.Equal(suite.T(), testUserEmail, update.Actor)
+	assert.Len(suite.T(), update.Diff, 1)
+	assert.JSONEq(suite.T(), `"`+oldNeighborhoodID+`"`, string(update.Diff["neighborhood_id"]["before"]))
+	assert.JSONEq(suite.T(), `"`+newNeighborhoodID+`"`, string(update.Diff["neighborhood_id"]["after"]))
+	assert.Equal(suite.T(), "create", page.Data[1].Action)
+}
+
+func (suite *E2ETestSuite) TestAuditLog_InvalidEntityType() {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?entity_type=spaceship", nil)
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+// login posts credentials and returns the decoded token pair.
+func (suite *E2ETestSuite) login(email, password string) (*httptest.ResponseRecorder, services.TokenPair) {
+	reqBody, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+
+	var tokens services.TokenPair
+	_ = json.Unmarshal(rec.Body.Bytes(), &tokens)
+	return rec, tokens
+}
+
+// refresh posts a refresh token and returns the response.
+func (suite *E2ETestSuite) refresh(refreshToken string) (*httptest.ResponseRecorder, services.TokenPair) {
+	reqBody, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+
+	var tokens services.TokenPair
+	_ = json.Unmarshal(rec.Body.Bytes(), &tokens)
+	return rec, tokens
+}
+
+func (suite *E2ETestSuite) TestLogin() {
+	rec, tokens := suite.login(testUserEmail, testUserPassword)
+
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.NotEmpty(suite.T(), tokens.AccessToken)
+	assert.NotEmpty(suite.T(), tokens.RefreshToken)
+	assert.Equal(suite.T(), "Bearer", tokens.TokenType)
+}
+
+func (suite *E2ETestSuite) TestLogin_WrongPassword() {
+	rec, _ := suite.login(testUserEmail, "not-the-password")
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestProtectedRoute_RequiresToken() {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings", nil)
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/buildings", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer not-a-jwt")
+	rec = httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestRefreshRotatesToken() {
+	_, tokens := suite.login(testUserEmail, testUserPassword)
+
+	rec, refreshed := suite.refresh(tokens.RefreshToken)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.NotEqual(suite.T(), tokens.RefreshToken, refreshed.RefreshToken)
+
+	// The old refresh token is single-use; replaying it revokes the new one too
+	rec, _ = suite.refresh(tokens.RefreshToken)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+	rec, _ = suite.refresh(refreshed.RefreshToken)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestConcurrentRefreshIsReuse() {
+	_, tokens := suite.login(testUserEmail, testUserPassword)
+
+	// Only one of two refreshes racing with the same token gets a new pair
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 2)
+	pairs := make([]services.TokenPair, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], pairs[i] = suite.refresh(tokens.RefreshToken)
+		}()
+	}
+	wg.Wait()
+
+	var refreshed []services.TokenPair
+	for i, rec := range results {
+		if rec.Code == http.StatusOK {
+			refreshed = append(refreshed, pairs[i])
+		} else {
+			assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+		}
+	}
+	suite.Require().Len(refreshed, 1)
+
+	// and the other is taken for a replay, which revokes that pair as well
+	rec, _ := suite.refresh(refreshed[0].RefreshToken)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestLogoutRevokesRefreshToken() {
+	_, tokens := suite.login(testUserEmail, testUserPassword)
+
+	reqBody, _ := json.Marshal(map[string]string{"refresh_token": tokens.RefreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+
+	rec, _ = suite.refresh(tokens.RefreshToken)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+// loginAs creates a user with the role through the API and returns an access token for it.
+func (suite *E2ETestSuite) loginAs(email string, role models.Role) (string, string) {
+	reqBody, _ := json.Marshal(map[string]string{"email": email, "password": testUserPassword, "role": string(role)})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	var created models.User
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(suite.T(), role, created.Role)
+
+	loginRec, tokens := suite.login(email, testUserPassword)
+	suite.Require().Equal(http.StatusOK, loginRec.Code)
+	return created.ID.String(), tokens.AccessToken
+}
+
+// serveAs sends the request with the given access token.
+func (suite *E2ETestSuite) serveAs(token string, method string, target string, body any) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *E2ETestSuite) TestViewerCanListButNotMutateBuildings() {
+	neighborhoodID := suite.createNeighborhood("Viewer Neighborhood")
+	buildingID := suite.createBuilding("Viewer Building", neighborhoodID, "1 Read Only St")
+	_, token := suite.loginAs("viewer@example.com", models.RoleViewer)
+
+	rec := suite.serveAs(token, http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	var buildings []map[string]string
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &buildings))
+	assert.Len(suite.T(), buildings, 1)
+
+	rec = suite.serveAs(token, http.MethodGet, "/api/v1/buildings/"+buildingID, nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	building := map[string]string{"name": "Changed", "neighborhood_id": neighborhoodID, "address": "2 Read Only St"}
+	rec = suite.serveAs(token, http.MethodPost, "/api/v1/buildings", building)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveAs(token, http.MethodPut, "/api/v1/buildings/"+buildingID, building)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveAs(token, http.MethodDelete, "/api/v1/buildings/"+buildingID, nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	// Nothing changed
+	rec = suite.serveAs(token, http.MethodGet, "/api/v1/buildings/"+buildingID, nil)
+	var got map[string]string
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(suite.T(), "Viewer Building", got["name"])
+}
+
+func (suite *E2ETestSuite) TestAgentCanEditButNotDeleteNeighborhoods() {
+	neighborhoodID := suite.createNeighborhood("Agent Neighborhood")
+	_, token := suite.loginAs("agent@example.com", models.RoleAgent)
+
+	rec := suite.serveAs(token, http.MethodPut, "/api/v1/neighborhoods/"+neighborhoodID, map[string]string{"name": "Renamed"})
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	rec = suite.serveAs(token, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID, nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestOnlyAdminsManageUsers() {
+	_, token := suite.loginAs("manager@example.com", models.RoleManager)
+
+	rec := suite.serveAs(token, http.MethodGet, "/api/v1/users", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveAs(token, http.MethodPost, "/api/v1/users", map[string]string{"email": "x@example.com", "password": testUserPassword, "role": "admin"})
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/roles", nil)
+	adminRec := httptest.NewRecorder()
+	suite.serve(adminRec, req)
+	assert.Equal(suite.T(), http.StatusOK, adminRec.Code)
+	var roles []services.RoleInfo
+	suite.Require().NoError(json.Unmarshal(adminRec.Body.Bytes(), &roles))
+	assert.Len(suite.T(), roles, len(models.Roles))
+}
+
+func (suite *E2ETestSuite) TestSetRole() {
+	neighborhoodID := suite.createNeighborhood("Promotion Neighborhood")
+	userID, _ := suite.loginAs("promoted@example.com", models.RoleViewer)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/"+userID+"/role", bytes.NewBufferString(`{"role": "agent"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	// A fresh token carries the new role
+	_, tokens := suite.login("promoted@example.com", testUserPassword)
+	building := map[string]string{"name": "Agent Building", "neighborhood_id": neighborhoodID, "address": "3 Promotion St"}
+	rec = suite.serveAs(tokens.AccessToken, http.MethodPost, "/api/v1/buildings", building)
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestSetRole_InvalidRole() {
+	userID, _ := suite.loginAs("invalid-role@example.com", models.RoleViewer)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/"+userID+"/role", bytes.NewBufferString(`{"role": "owner"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+// issueAPIKey issues an API key with the scopes as the test admin.
+func (suite *E2ETestSuite) issueAPIKey(scopes ...models.APIKeyScope) services.IssuedAPIKey {
+	reqBody, _ := json.Marshal(map[string]any{"name": "Partner", "scopes": scopes})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	var issued services.IssuedAPIKey
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &issued))
+	return issued
+}
+
+// serveWithAPIKey sends the request with the key in the X-API-Key header.
+func (suite *E2ETestSuite) serveWithAPIKey(key string, method string, target string, body any) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.APIKeyHeader, key)
+	rec := httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *E2ETestSuite) TestAPIKey_ListingsRead() {
+	neighborhoodID := suite.createNeighborhood("Partner Neighborhood")
+	suite.createBuilding("Partner Building", neighborhoodID, "1 Partner St")
+	issued := suite.issueAPIKey(models.ScopeListingsRead)
+	assert.True(suite.T(), strings.HasPrefix(issued.Key, models.APIKeyPrefix))
+	assert.True(suite.T(), strings.HasPrefix(issued.Key, issued.Prefix))
+
+	rec := suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	// Keys are also accepted as bearer tokens
+	rec = suite.serveAs(issued.Key, http.MethodGet, "/api/v1/neighborhoods", nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	rec = suite.serveWithAPIKey(issued.Key, http.MethodPost, "/api/v1/neighborhoods", map[string]string{"name": "Nope"})
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/api-keys", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	// Usage is tracked and the key itself is never returned again
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys/"+issued.ID.String(), nil)
+	getRec := httptest.NewRecorder()
+	suite.serve(getRec, req)
+	assert.Equal(suite.T(), http.StatusOK, getRec.Code)
+	assert.NotContains(suite.T(), getRec.Body.String(), issued.Key)
+	var stored models.APIKey
+	suite.Require().NoError(json.Unmarshal(getRec.Body.Bytes(), &stored))
+	assert.NotNil(suite.T(), stored.LastUsedAt)
+}
+
+func (suite *E2ETestSuite) TestAPIKey_InquiriesWrite() {
+	neighborhoodID := suite.createNeighborhood("Inquiry Neighborhood")
+	buildingID := suite.createBuilding("Inquiry Building", neighborhoodID, "2 Partner St")
+	issued := suite.issueAPIKey(models.ScopeInquiriesWrite)
+
+	inquiry := map[string]string{"building_id": buildingID, "name": "Jane Doe", "email": "jane@example.com", "message": "Is a two-bedroom available?"}
+	rec := suite.serveWithAPIKey(issued.Key, http.MethodPost, "/api/v1/inquiries", inquiry)
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
+	var created models.Inquiry
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(suite.T(), "api_key:"+issued.ID.String(), created.Source)
+
+	rec = suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/inquiries", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestAPIKey_Revoked() {
+	issued := suite.issueAPIKey(models.ScopeListingsRead)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/"+issued.ID.String(), nil)
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+
+	rec = suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestAPIKey_Expired() {
+	issued := suite.issueAPIKey(models.ScopeListingsRead)
+	_, err := suite.db.Exec("UPDATE api_keys SET expires_at = now() - interval '1 minute' WHERE id = $1", issued.ID)
+	suite.Require().NoError(err)
+
+	rec := suite.serveWithAPIKey(issued.Key, http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+
+	rec = suite.serveWithAPIKey(models.APIKeyPrefix+"unknown", http.MethodGet, "/api/v1/buildings", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestAPIKey_InvalidScope() {
+	reqBody, _ := json.Marshal(map[string]any{"name": "Partner", "scopes": []string{"everything"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestApartmentCRUD() {
+	neighborhoodID := suite.createNeighborhood("Apartment Neighborhood")
+	buildingID := suite.createBuilding("Apartment Building", neighborhoodID, "7 Loft St")
+
+	apartment := map[string]any{
+		"building_id": buildingID,
+		"type":        "OneBed",
+		"price_from":  150000,
+		"price_to":    180000,
+		"images":      []string{"https://example.com/1.jpg"},
+	}
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", apartment)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var created models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(suite.T(), models.OneBed, created.Type)
+	assert.Equal(suite.T(), int64(150000), created.Price.From)
+	assert.Empty(suite.T(), created.Videos)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/apartments/"+created.ID.String(), nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var fetched models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &fetched))
+	assert.Equal(suite.T(), created.Price, fetched.Price)
+	assert.Equal(suite.T(), []string{"https://example.com/1.jpg"}, []string(fetched.Images))
+
+	apartment["type"] = "Penthouse"
+	rec = suite.serveAs(suite.accessToken, http.MethodPut, "/api/v1/apartments/"+created.ID.String(), apartment)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+
+	apartment["type"] = "TwoBeds"
+	rec = suite.serveAs(suite.accessToken, http.MethodPut, "/api/v1/apartments/"+created.ID.String(), apartment)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/apartments/"+created.ID.String(), nil)
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/apartments/"+created.ID.String(), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestValidationProblemDetails() {
+	neighborhoodID := suite.createNeighborhood("Problem Neighborhood")
+	buildingID := suite.createBuilding("Problem Building", neighborhoodID, "3 Problem St")
+
+	// Every rejected field is listed, named as in the request
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "Penthouse", "price_from": 200000, "price_to": 150000,
+	})
+	suite.Require().Equal(http.StatusBadRequest, rec.Code)
+	assert.Equal(suite.T(), handlers.MIMEProblem, rec.Header().Get(echo.HeaderContentType))
+	var problem handlers.Problem
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), handlers.ProblemValidation.URI, problem.Type)
+	assert.Equal(suite.T(), http.StatusBadRequest, problem.Status)
+	assert.Equal(suite.T(), "/api/v1/apartments", problem.Instance)
+	assert.Equal(suite.T(), []apperrors.FieldError{
+		{Field: "type", Code: apperrors.CodeInvalid, Message: "must be a known apartment type"},
+		{Field: "price_to", Code: apperrors.CodeOutOfRange, Message: "must be greater than the starting price"},
+	}, problem.Errors)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings", map[string]string{"neighborhood_id": neighborhoodID})
+	suite.Require().Equal(http.StatusBadRequest, rec.Code)
+	problem = handlers.Problem{}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	fields := make([]string, len(problem.Errors))
+	for i, fieldErr := range problem.Errors {
+		fields[i] = fieldErr.Field
+	}
+	assert.Equal(suite.T(), []string{"name", "address"}, fields)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings", map[string]string{"name": "Lost", "neighborhood_id": "nowhere", "address": "1 Lost Rd"})
+	suite.Require().Equal(http.StatusBadRequest, rec.Code)
+	problem = handlers.Problem{}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), []apperrors.FieldError{{Field: "neighborhood_id", Code: apperrors.CodeInvalid, Message: "must be a valid ID"}}, problem.Errors)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings", map[string]string{
+		"name": "Long", "neighborhood_id": neighborhoodID, "address": strings.Repeat("9 Long Rd ", models.MaxAddressLength),
+	})
+	suite.Require().Equal(http.StatusBadRequest, rec.Code)
+	problem = handlers.Problem{}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), []apperrors.FieldError{{Field: "address", Code: apperrors.CodeTooLong, Message: "must be at most 300 characters"}}, problem.Errors)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/users", map[string]string{"email": testUserEmail, "password": testUserPassword, "role": "viewer"})
+	suite.Require().Equal(http.StatusBadRequest, rec.Code)
+	problem = handlers.Problem{}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), []apperrors.FieldError{{Field: "email", Code: apperrors.CodeTaken, Message: "is already in use"}}, problem.Errors)
+
+	// Other failures are problems too, typed after their cause
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+uuid.NewString(), nil)
+	suite.Require().Equal(http.StatusNotFound, rec.Code)
+	assert.Equal(suite.T(), handlers.MIMEProblem, rec.Header().Get(echo.HeaderContentType))
+	problem = handlers.Problem{}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), handlers.ProblemNotFound.URI, problem.Type)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/no-such-route", nil)
+	suite.Require().Equal(http.StatusNotFound, rec.Code)
+	assert.Equal(suite.T(), handlers.MIMEProblem, rec.Header().Get(echo.HeaderContentType))
+}
+
+// createOrganization onboards an organization as the platform admin and returns
+// an access token for its admin user.
+func (suite *E2ETestSuite) createOrganization(slug string) (models.Organization, string) {
+	adminEmail := "admin@" + slug + ".example.com"
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/organizations", map[string]string{
+		"slug":           slug,
+		"name":           "Agency " + slug,
+		"admin_email":    adminEmail,
+		"admin_password": testUserPassword,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	var organization models.Organization
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &organization))
+
+	loginRec, tokens := suite.login(adminEmail, testUserPassword)
+	suite.Require().Equal(http.StatusOK, loginRec.Code)
+	return organization, tokens.AccessToken
+}
+
+func (suite *E2ETestSuite) TestCrossTenantAccessReturnsNotFound() {
+	neighborhoodID := suite.createNeighborhood("Platform Neighborhood")
+	buildingID := suite.createBuilding("Platform Building", neighborhoodID, "1 Platform St")
+	_, token := suite.createOrganization("other-agency")
+
+	for _, target := range []string{"/api/v1/neighborhoods/" + neighborhoodID, "/api/v1/buildings/" + buildingID} {
+		rec := suite.serveAs(token, http.MethodGet, target, nil)
+		assert.Equal(suite.T(), http.StatusNotFound, rec.Code, target)
+
+		rec = suite.serveAs(token, http.MethodDelete, target, nil)
+		assert.Equal(suite.T(), http.StatusNotFound, rec.Code, target)
+	}
+
+	rec := suite.serveAs(token, http.MethodPut, "/api/v1/neighborhoods/"+neighborhoodID, map[string]string{"name": "Hijacked"})
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+	rec = suite.serveAs(token, http.MethodPut, "/api/v1/buildings/"+buildingID, map[string]string{
+		"name": "Hijacked", "neighborhood_id": neighborhoodID, "address": "2 Other St",
+	})
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+
+	// A building cannot be attached to another tenant's neighborhood
+	rec = suite.serveAs(token, http.MethodPost, "/api/v1/buildings", map[string]string{
+		"name": "Foreign Building", "neighborhood_id": neighborhoodID, "address": "3 Other St",
+	})
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+
+	for _, target := range []string{"/api/v1/neighborhoods", "/api/v1/buildings", "/api/v1/users"} {
+		rec = suite.serveAs(token, http.MethodGet, target, nil)
+		suite.Require().Equal(http.StatusOK, rec.Code, target)
+		var items []map[string]any
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &items))
+		if target == "/api/v1/users" {
+			assert.Len(suite.T(), items, 1, target)
+		} else {
+			assert.Empty(suite.T(), items, target)
+		}
+	}
+
+	// The platform's data is untouched
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/neighborhoods/"+neighborhoodID, nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), "Platform Neighborhood")
+}
+
+func (suite *E2ETestSuite) TestOnlyPlatformCreatesOrganizations() {
+	organization, token := suite.createOrganization("first-agency")
+
+	rec := suite.serveAs(token, http.MethodPost, "/api/v1/organizations", map[string]string{
+		"slug": "second-agency", "name": "Second", "admin_email": "admin@second.example.com", "admin_password": testUserPassword,
+	})
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	rec = suite.serveAs(token, http.MethodGet, "/api/v1/organizations", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var organizations []models.Organization
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &organizations))
+	suite.Require().Len(organizations, 1)
+	assert.Equal(suite.T(), organization.ID, organizations[0].ID)
+
+	rec = suite.serveAs(token, http.MethodGet, "/api/v1/organizations/"+models.DefaultOrganizationID.String(), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestSearch() {
+	sunsetParkID := suite.createNeighborhood("Sunset Park")
+	suite.createNeighborhood("Park Slope")
+	harborID := suite.createBuilding("Harbor View", sunsetParkID, "4510 45th St")
+	suite.createBuilding("Hillside & Lofts", sunsetParkID, "12 Ridge Rd")
+
+	search := func(q string) []models.SearchResult {
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/search?q="+url.QueryEscape(q), nil)
+		suite.Require().Equal(http.StatusOK, rec.Code, q)
+		var results []models.SearchResult
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &results))
+		return results
+	}
+
+	// A misspelled neighborhood plus a street number finds both the neighborhood and the address
+	results := search("sunst park 45th")
+	suite.Require().NotEmpty(results)
+	types := map[models.SearchResultType]string{}
+	for _, result := range results {
+		types[result.Type] = result.ID.String()
+	}
+	assert.Equal(suite.T(), sunsetParkID, types[models.SearchNeighborhood])
+	assert.Equal(suite.T(), harborID, types[models.SearchAddress])
+	for i := 1; i < len(results); i++ {
+		assert.GreaterOrEqual(suite.T(), results[i-1].Score, results[i].Score)
+	}
+
+	// Building names match by prefix and the snippet highlights the match, HTML-escaped
+	results = search("hill")
+	suite.Require().Len(results, 1)
+	assert.Equal(suite.T(), models.SearchBuilding, results[0].Type)
+	assert.Equal(suite.T(), "<mark>Hillside</mark> &amp; Lofts", results[0].Snippet)
+
+	// Operators in the query are treated as plain text
+	assert.Empty(suite.T(), search("!:*&|'"))
+
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/search?q=%20", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), "invalid query parameter: q")
+}
+
+func (suite *E2ETestSuite) TestAutocomplete() {
+	// A new organization starts with a cold index
+	_, token := suite.createOrganization("typeahead-agency")
+	create := func(target string, body map[string]string) string {
+		rec := suite.serveAs(token, http.MethodPost, target, body)
+		suite.Require().Equal(http.StatusCreated, rec.Code)
+		var created struct {
+			ID string `json:"id"`
+		}
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &created))
+		return created.ID
+	}
+	sunsetParkID := create("/api/v1/neighborhoods", map[string]string{"name": "Sunset Park"})
+	create("/api/v1/buildings", map[string]string{"name": "Sunrise Tower", "neighborhood_id": sunsetParkID, "address": "9 Sunset Blvd"})
+	create("/api/v1/buildings", map[string]string{"name": "Harbor View", "neighborhood_id": sunsetParkID, "address": "4510 45th St"})
+
+	autocomplete := func(query string) ([]string, string) {
+		rec := suite.serveAs(token, http.MethodGet, "/api/v1/autocomplete?"+query, nil)
+		suite.Require().Equal(http.StatusOK, rec.Code, query)
+		var suggestions []models.Suggestion
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &suggestions))
+		texts := []string{}
+		for _, suggestion := range suggestions {
+			texts = append(texts, string(suggestion.Type)+":"+suggestion.Text)
+		}
+		return texts, rec.Header().Get(handlers.AutocompleteSourceHeader)
+	}
+
+	// Names starting with the prefix come first, then other words starting with it
+	expected := []string{"building:Sunrise Tower", "neighborhood:Sunset Park", "address:9 Sunset Blvd"}
+	texts, source := autocomplete("q=Sun")
+	assert.Equal(suite.T(), "database", source)
+	assert.Equal(suite.T(), expected, texts)
+
+	// Once loaded, the index gives the same answers
+	suite.Require().NoError(suite.autocomplete.Warm(context.Background()))
+	texts, source = autocomplete("q=Sun")
+	assert.Equal(suite.T(), "index", source)
+	assert.Equal(suite.T(), expected, texts)
+	texts, _ = autocomplete("q=sunset&type=address")
+	assert.Equal(suite.T(), []string{"address:9 Sunset Blvd"}, texts)
+
+	// Writes reach the index through domain events, broadcast to every listening instance
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	suite.Require().NoError(suite.autocomplete.Listen(ctx, suite.dbURL, zap.NewNop()))
+	create("/api/v1/buildings", map[string]string{"name": "Sunnyside Court", "neighborhood_id": sunsetParkID, "address": "1 Ridge Rd"})
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+	assert.Eventually(suite.T(), func() bool {
+		texts, _ := autocomplete("q=sunn")
+		return slices.Equal([]string{"building:Sunnyside Court"}, texts)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Deleting the neighborhood drops its buildings too
+	rec := suite.serveAs(token, http.MethodDelete, "/api/v1/neighborhoods/"+sunsetParkID+"?cascade=true", nil)
+	suite.Require().Equal(http.StatusNoContent, rec.Code)
+	suite.Require().NoError(suite.dispatcher.DispatchPending(context.Background()))
+	assert.Eventually(suite.T(), func() bool {
+		texts, _ := autocomplete("q=sun")
+		return len(texts) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+	_, source = autocomplete("q=sun")
+	assert.Equal(suite.T(), "index", source)
+
+	rec = suite.serveAs(token, http.MethodGet, "/api/v1/autocomplete?q=sun&type=street", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), "invalid query parameter: type")
+}
+
+// mergePatch sends a JSON Merge Patch as the test user.
+func (suite *E2ETestSuite) mergePatch(target string, patch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, target, strings.NewReader(patch))
+	req.Header.Set("Content-Type", handlers.MIMEMergePatch)
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	return rec
+}
+
+func (suite *E2ETestSuite) TestPatchBuilding() {
+	neighborhoodID := suite.createNeighborhood("Patch Neighborhood")
+	buildingID := suite.createBuilding("Patch Building", neighborhoodID, "1 Old St")
+
+	// Only the fields sent change
+	rec := suite.mergePatch("/api/v1/buildings/"+buildingID, `{"address": "2 New St"}`)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var building models.Building
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &building))
+	assert.Equal(suite.T(), "Patch Building", building.Name)
+	assert.Equal(suite.T(), neighborhoodID, building.NeighborhoodID.String())
+	assert.Equal(suite.T(), "2 New St", building.Address)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+buildingID, nil)
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &building))
+	assert.Equal(suite.T(), "2 New St", building.Address)
+
+	// The merged building is validated: clearing a required field fails
+	rec = suite.mergePatch("/api/v1/buildings/"+buildingID, `{"name": null}`)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	rec = suite.mergePatch("/api/v1/buildings/"+buildingID, `{"nmae": "Typo"}`)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	rec = suite.mergePatch("/api/v1/buildings/"+buildingID, `["name"]`)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPatch, "/api/v1/buildings/"+buildingID, map[string]string{"name": "Plain JSON"})
+	assert.Equal(suite.T(), http.StatusUnsupportedMediaType, rec.Code)
+	rec = suite.mergePatch("/api/v1/buildings/"+uuid.New().String(), `{"name": "Missing"}`)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestPatchApartment_NullClearsPromotion() {
+	neighborhoodID := suite.createNeighborhood("Promo Neighborhood")
+	buildingID := suite.createBuilding("Promo Building", neighborhoodID, "3 Deal St")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id":       buildingID,
+		"type":              "Studio",
+		"price_from":        100000,
+		"price_to":          120000,
+		"promotional_price": 95000,
+		"images":            []string{"https://example.com/1.jpg"},
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var apartment models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &apartment))
+
+	rec = suite.mergePatch("/api/v1/apartments/"+apartment.ID.String(), `{"promotional_price": null, "price_to": 130000}`)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var patched models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &patched))
+	assert.Nil(suite.T(), patched.PromotionalPrice)
+	assert.Equal(suite.T(), models.PriceRange{From: 100000, To: 130000}, patched.Price)
+	assert.Equal(suite.T(), models.Studio, patched.Type)
+	assert.Equal(suite.T(), []string{"https://example.com/1.jpg"}, []string(patched.Images))
+
+	// A patch leaving an invalid price range is rejected
+	rec = suite.mergePatch("/api/v1/apartments/"+apartment.ID.String(), `{"price_from": 200000}`)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestBuildingIfMatch() {
+	neighborhoodID := suite.createNeighborhood("Versioned Neighborhood")
+	buildingID := suite.createBuilding("Versioned Building", neighborhoodID, "1 Version St")
+
+	send := func(method string, contentType string, body string, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/buildings/"+buildingID, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		suite.serve(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "application/json", "", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), `"1"`, rec.Header().Get("ETag"))
+
+	// The first agent saves version 1 and gets version 2
+	update := `{"name": "First Agent", "neighborhood_id": "` + neighborhoodID + `", "address": "1 Version St"}`
+	rec = send(http.MethodPut, "application/json", update, `"1"`)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), `"2"`, rec.Header().Get("ETag"))
+
+	// The second agent still holds version 1 and is refused
+	rec = send(http.MethodPut, "application/json", update, `"1"`)
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, rec.Code)
+	rec = send(http.MethodPatch, handlers.MIMEMergePatch, `{"name": "Second Agent"}`, `"1"`)
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, rec.Code)
+	rec = send(http.MethodDelete, "application/json", "", `"1"`)
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, rec.Code)
+	rec = send(http.MethodDelete, "application/json", "", `W/"2"`)
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, rec.Code)
+
+	rec = send(http.MethodGet, "application/json", "", "")
+	var building models.Building
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &building))
+	assert.Equal(suite.T(), "First Agent", building.Name)
+	assert.Equal(suite.T(), int64(2), building.Version)
+
+	// Patching the current version, then deleting the one it returns, succeeds
+	rec = send(http.MethodPatch, handlers.MIMEMergePatch, `{"name": "Second Agent"}`, `"2"`)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	rec = send(http.MethodDelete, "application/json", "", rec.Header().Get("ETag"))
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestSoftDeleteAndRestore() {
+	neighborhoodID := suite.createNeighborhood("Trash Neighborhood")
+	keptID := suite.createBuilding("Kept Building", neighborhoodID, "1 Trash St")
+	removedID := suite.createBuilding("Removed Building", neighborhoodID, "2 Trash St")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": keptID, "type": "Studio", "price_from": 100000, "price_to": 110000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var apartment models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &apartment))
+
+	// One building is deleted on its own, then the neighborhood with the rest
+	suite.Require().Equal(http.StatusNoContent, suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/buildings/"+removedID, nil).Code)
+	suite.Require().Equal(http.StatusNoContent, suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID+"?cascade=true", nil).Code)
+
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/neighborhoods/"+neighborhoodID, nil).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+keptID, nil).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/apartments/"+apartment.ID.String(), nil).Code)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings", nil)
+	assert.JSONEq(suite.T(), "[]", rec.Body.String())
+
+	trash := func(query string) []models.TrashItem {
+		rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/trash"+query, nil)
+		suite.Require().Equal(http.StatusOK, rec.Code)
+		var items []models.TrashItem
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &items))
+		return items
+	}
+	assert.Len(suite.T(), trash(""), 4)
+	buildings := trash("?filter[type]=building&sort=label")
+	suite.Require().Len(buildings, 2)
+	assert.Equal(suite.T(), "Kept Building", buildings[0].Label)
+	assert.Equal(suite.T(), neighborhoodID, buildings[0].ParentID.String())
+
+	// A building cannot come back while its neighborhood is in the trash
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings/"+keptID+"/restore", nil)
+	assert.Equal(suite.T(), http.StatusConflict, rec.Code)
+
+	// Restoring the neighborhood brings back what was deleted with it, not the building deleted before
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/neighborhoods/"+neighborhoodID+"/restore", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), http.StatusOK, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+keptID, nil).Code)
+	assert.Equal(suite.T(), http.StatusOK, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/apartments/"+apartment.ID.String(), nil).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+removedID, nil).Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings/"+removedID+"/restore", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Empty(suite.T(), trash(""))
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/buildings/"+removedID+"/restore", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestTrashPurge() {
+	neighborhoodID := suite.createNeighborhood("Purged Neighborhood")
+	suite.Require().Equal(http.StatusNoContent, suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID, nil).Code)
+
+	// Rows are kept for the retention period, then purged for good
+	kept, err := services.NewTrashService(repositories.NewTrashRepository(suite.db), time.Hour).PurgeExpired(context.Background())
+	suite.Require().NoError(err)
+	assert.Zero(suite.T(), kept)
+	purged, err := services.NewTrashService(repositories.NewTrashRepository(suite.db), 0).PurgeExpired(context.Background())
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), purged)
+
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/neighborhoods/"+neighborhoodID+"/restore", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestDeleteNeighborhoodWithDependents() {
+	neighborhoodID := suite.createNeighborhood("Occupied Neighborhood")
+	buildingID := suite.createBuilding("Occupied Building", neighborhoodID, "1 Occupied St")
+	suite.createBuilding("Empty Building", neighborhoodID, "2 Occupied St")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "Studio", "price_from": 100000, "price_to": 110000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID, nil)
+	suite.Require().Equal(http.StatusConflict, rec.Code)
+	var problem handlers.Problem
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), handlers.ProblemHasDependents.URI, problem.Type)
+	assert.Equal(suite.T(), map[string]any{"buildings": 2.0, "apartments": 1.0}, problem.Dependents)
+	assert.Equal(suite.T(), http.StatusOK, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+buildingID, nil).Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID+"?cascade=maybe", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID+"?cascade=true", nil)
+	suite.Require().Equal(http.StatusNoContent, rec.Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+buildingID, nil).Code)
+}
+
+func (suite *E2ETestSuite) TestCascadeDeleteRecordsDependents() {
+	neighborhoodID := suite.createNeighborhood("Cascade Neighborhood")
+	buildingID := suite.createBuilding("Cascade Building", neighborhoodID, "1 Cascade St")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "Studio", "price_from": 100000, "price_to": 110000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var apartment models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &apartment))
+	apartmentID := apartment.ID.String()
+
+	events := func(aggregateID string) []string {
+		var types []string
+		suite.Require().NoError(suite.db.Select(&types, "SELECT event_type FROM outbox WHERE aggregate_id = $1 ORDER BY seq", aggregateID))
+		return types
+	}
+	audits := func(entityType string, entityID string) []string {
+		var actions []string
+		suite.Require().NoError(suite.db.Select(&actions, "SELECT action FROM audit_log WHERE entity_type = $1 AND entity_id = $2 ORDER BY created_at", entityType, entityID))
+		return actions
+	}
+
+	// The buildings and apartments trashed with the neighborhood record their own deletion
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+neighborhoodID+"?cascade=true", nil)
+	suite.Require().Equal(http.StatusNoContent, rec.Code)
+	assert.Equal(suite.T(), []string{"neighborhood.created", "neighborhood.deleted"}, events(neighborhoodID))
+	assert.Equal(suite.T(), []string{"building.created", "building.deleted"}, events(buildingID))
+	assert.Equal(suite.T(), []string{"apartment.created", "apartment.deleted"}, events(apartmentID))
+	assert.Equal(suite.T(), []string{"create", "delete"}, audits("building", buildingID))
+	assert.Equal(suite.T(), []string{"create", "delete"}, audits("apartment", apartmentID))
+
+	var deletedType string
+	suite.Require().NoError(suite.db.Get(&deletedType, "SELECT diff->'type'->>'before' FROM audit_log WHERE entity_id = $1 AND action = 'delete'", apartmentID))
+	assert.Equal(suite.T(), "Studio", deletedType)
+
+	// Restoring the neighborhood records the restoration of each of them
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/neighborhoods/"+neighborhoodID+"/restore", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), []string{"building.created", "building.deleted", "building.restored"}, events(buildingID))
+	assert.Equal(suite.T(), []string{"apartment.created", "apartment.deleted", "apartment.restored"}, events(apartmentID))
+	assert.Equal(suite.T(), []string{"create", "delete", "restore"}, audits("building", buildingID))
+	assert.Equal(suite.T(), []string{"create", "delete", "restore"}, audits("apartment", apartmentID))
+}
+
+func (suite *E2ETestSuite) TestReassignNeighborhood() {
+	fromID := suite.createNeighborhood("Merged Neighborhood")
+	toID := suite.createNeighborhood("Surviving Neighborhood")
+	buildingID := suite.createBuilding("Moving Building", fromID, "1 Merge St")
+
+	reassign := func(id string, target string) *httptest.ResponseRecorder {
+		return suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/neighborhoods/"+id+"/reassign", map[string]string{"neighborhood_id": target})
+	}
+	assert.Equal(suite.T(), http.StatusBadRequest, reassign(fromID, fromID).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, reassign(fromID, uuid.NewString()).Code)
+
+	suite.Require().Equal(http.StatusNoContent, reassign(fromID, toID).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/neighborhoods/"+fromID, nil).Code)
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+buildingID, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var building models.Building
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &building))
+	assert.Equal(suite.T(), toID, building.NeighborhoodID.String())
+	assert.Equal(suite.T(), int64(2), building.Version)
+
+	// The buildings stay put when the emptied neighborhood is restored
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/neighborhoods/"+fromID+"/restore", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/neighborhoods/"+fromID, nil)
+	assert.Equal(suite.T(), http.StatusNoContent, rec.Code)
+}
+
+// importCSV uploads a CSV file to the import endpoint as the test user.
+func (suite *E2ETestSuite) importCSV(query string, file string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/imports?"+query, strings.NewReader(file))
+	req.Header.Set(echo.HeaderContentType, handlers.MIMECSV)
+	rec := httptest.NewRecorder()
+	suite.serve(rec, req)
+	return rec
+}
+
+func (suite *E2ETestSuite) TestImportBuildings() {
+	parkSlopeID := suite.createNeighborhood("Park Slope")
+	existingID := suite.createBuilding("Garfield Arms", parkSlopeID, "1 Old St")
+	file := "Neighborhood,Building Name,Street\n" +
+		"park slope,Garfield Arms,12 Garfield Pl\n" +
+		"Park Slope,Union Lofts,40 Union St\n" +
+		"Nowhere,Lost House,1 Lost Rd\n" +
+		"Park Slope,,2 Blank St\n" +
+		"Park Slope,union lofts,41 Union St\n"
+	mapping := "entity=buildings&mapping[name]=Building+Name&mapping[address]=Street"
+
+	// A dry run reports on every row and writes nothing
+	rec := suite.importCSV(mapping+"&dry_run=true", file)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var dryRun services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &dryRun))
+	assert.Equal(suite.T(), models.ImportFailed, dryRun.Status)
+	assert.Equal(suite.T(), 5, dryRun.Rows)
+	assert.Equal(suite.T(), 1, dryRun.Created)
+	assert.Equal(suite.T(), 1, dryRun.Updated)
+	assert.Equal(suite.T(), 3, dryRun.Failed)
+	suite.Require().Len(dryRun.Report, 5)
+	assert.Equal(suite.T(), 2, dryRun.Report[0].Line)
+	assert.Equal(suite.T(), models.ImportUpdate, dryRun.Report[0].Action)
+	suite.Require().NotNil(dryRun.Report[0].ID)
+	assert.Equal(suite.T(), existingID, dryRun.Report[0].ID.String())
+	assert.Equal(suite.T(), `neighborhood "Nowhere" not found`, dryRun.Report[2].Error)
+	assert.Equal(suite.T(), "invalid input: name: must not be empty", dryRun.Report[3].Error)
+	assert.Equal(suite.T(), "duplicates line 3", dryRun.Report[4].Error)
+
+	// With invalid rows nothing is written either
+	rec = suite.importCSV(mapping, file)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var failed services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &failed))
+	assert.Equal(suite.T(), models.ImportFailed, failed.Status)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+existingID, nil)
+	assert.Contains(suite.T(), rec.Body.String(), "1 Old St")
+
+	// The rejected rows download as CSV
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/imports/"+failed.ID.String()+"/errors", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "Neighborhood,Building Name,Street,line,error\n"+
+		"Nowhere,Lost House,1 Lost Rd,4,\"neighborhood \"\"Nowhere\"\" not found\"\n"+
+		"Park Slope,,2 Blank St,5,invalid input: name: must not be empty\n"+
+		"Park Slope,union lofts,41 Union St,6,duplicates line 3\n", rec.Body.String())
+
+	// Once the file is fixed it is applied in one go
+	rec = suite.importCSV(mapping, "Neighborhood,Building Name,Street\n"+
+		"Park Slope,Garfield Arms,12 Garfield Pl\n"+
+		"Park Slope,Union Lofts,40 Union St\n")
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var completed services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &completed))
+	assert.Equal(suite.T(), models.ImportCompleted, completed.Status)
+	suite.Require().NotNil(completed.Report[1].ID)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+existingID, nil)
+	assert.Contains(suite.T(), rec.Body.String(), "12 Garfield Pl")
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/buildings/"+completed.Report[1].ID.String(), nil)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/imports/"+completed.ID.String(), nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), `"status":"completed"`)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/imports?filter[status]=failed", nil)
+	var imports []models.Import
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &imports))
+	assert.Len(suite.T(), imports, 2)
+}
+
+func (suite *E2ETestSuite) TestImportApartments() {
+	neighborhoodID := suite.createNeighborhood("Astoria")
+	suite.createBuilding("Ditmars Court", neighborhoodID, "1 Ditmars Blvd")
+	file := "neighborhood,building,type,price_from,price_to,promotional_price,images\n" +
+		"Astoria,Ditmars Court,Studio,150000,170000,,https://img.example.com/1.jpg https://img.example.com/2.jpg\n" +
+		"Astoria,Ditmars Court,OneBed,200000,220000,190000,\n"
+
+	rec := suite.importCSV("entity=apartments", file)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var first services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &first))
+	assert.Equal(suite.T(), models.ImportCompleted, first.Status)
+	assert.Equal(suite.T(), 2, first.Created)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/apartments/"+first.Report[0].ID.String(), nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var studio models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &studio))
+	assert.Equal(suite.T(), []string{"https://img.example.com/1.jpg", "https://img.example.com/2.jpg"}, []string(studio.Images))
+
+	// Importing again matches by building and type
+	rec = suite.importCSV("entity=apartments", strings.Replace(file, "200000,220000", "200000,230000", 1))
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var second services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &second))
+	assert.Equal(suite.T(), 0, second.Created)
+	assert.Equal(suite.T(), 1, second.Unchanged)
+	assert.Equal(suite.T(), 1, second.Updated)
+
+	rec = suite.importCSV("entity=apartments", "neighborhood,building,type,price_from,price_to\nAstoria,Ditmars Court,Loft,abc,1\n")
+	var invalid services.ImportResult
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &invalid))
+	assert.Equal(suite.T(), "price_from must be a whole number", invalid.Report[0].Error)
+
+	rec = suite.importCSV("entity=apartments", "neighborhood,building\nAstoria,Ditmars Court\n")
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), "mapping[type]")
+	rec = suite.importCSV("entity=tenants", file)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestExportBuildings() {
+	parkSlopeID := suite.createNeighborhood("Park Slope")
+	astoriaID := suite.createNeighborhood("Astoria")
+	suite.createBuilding("Union Lofts", parkSlopeID, "40 Union St")
+	suite.createBuilding("Garfield Arms, East", parkSlopeID, "12 Garfield Pl")
+	suite.createBuilding("Ditmars Court", astoriaID, "1 Ditmars Blvd")
+
+	rec := suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/buildings?filter[neighborhood_id]="+parkSlopeID, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	suite.Require().Len(lines, 3)
+	assert.Equal(suite.T(), "id,neighborhood_id,neighborhood,name,address", lines[0])
+	assert.True(suite.T(), strings.HasSuffix(lines[1], `,Park Slope,"Garfield Arms, East",12 Garfield Pl`), lines[1])
+	assert.True(suite.T(), strings.HasSuffix(lines[2], ",Park Slope,Union Lofts,40 Union St"), lines[2])
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/buildings?format=jsonl&sort=-name", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), handlers.MIMEJSONLines, rec.Header().Get(echo.HeaderContentType))
+	var names []string
+	decoder := json.NewDecoder(rec.Body)
+	for decoder.More() {
+		var building models.BuildingExport
+		suite.Require().NoError(decoder.Decode(&building))
+		names = append(names, building.Neighborhood+"/"+building.Name)
+	}
+	assert.Equal(suite.T(), []string{"Park Slope/Union Lofts", "Park Slope/Garfield Arms, East", "Astoria/Ditmars Court"}, names)
+
+	// An empty export still has its header; bad parameters are rejected before streaming starts
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/neighborhoods?filter[name]=Nowhere", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "id,name\n", rec.Body.String())
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/apartments?sort=type", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/apartments?format=xlsx", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+
+	// Text a spreadsheet would run as a formula is exported as text
+	formulaID := suite.createNeighborhood("@Formula Flats")
+	suite.createBuilding(`=HYPERLINK("https://evil.example","Click")`, formulaID, "-1 Minus St")
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/buildings?filter[neighborhood_id]="+formulaID, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	suite.Require().Len(lines, 2)
+	assert.True(suite.T(), strings.HasSuffix(lines[1], `,'@Formula Flats,"'=HYPERLINK(""https://evil.example"",""Click"")",'-1 Minus St`), lines[1])
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/exports/buildings?format=jsonl&filter[neighborhood_id]="+formulaID, nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var building models.BuildingExport
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &building))
+	assert.Equal(suite.T(), `=HYPERLINK("https://evil.example","Click")`, building.Name)
+}
+
+func (suite *E2ETestSuite) TestListingsFeeds() {
+	neighborhoodID := suite.createNeighborhood("Feed Heights")
+	buildingID := suite.createBuilding("Feed Tower", neighborhoodID, "5 Feed Ave")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "OneBed", "price_from": 150000, "price_to": 172550,
+		"promotional_price": 140000, "images": []string{"https://example.com/1.jpg"}, "available": true,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	// Neither unavailable nor trashed apartments are published
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "TwoBeds", "price_from": 250000, "price_to": 260000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "Studio", "price_from": 100000, "price_to": 110000, "available": true,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var trashed models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &trashed))
+	rec = suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/apartments/"+trashed.ID.String(), nil)
+	suite.Require().Equal(http.StatusNoContent, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/feeds/listings.xml", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "application/xml; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	lastModified := rec.Header().Get(echo.HeaderLastModified)
+	suite.Require().NotEmpty(lastModified)
+	var feed struct {
+		Listings []struct {
+			Type         string `xml:"type"`
+			Neighborhood string `xml:"neighborhood"`
+			Address      string `xml:"building>address"`
+			Price        struct {
+				Currency string `xml:"currency,attr"`
+				From     string `xml:"from,attr"`
+				To       string `xml:"to,attr"`
+			} `xml:"price"`
+			Promotion struct {
+				Price string `xml:"price,attr"`
+			} `xml:"promotion"`
+			Images []string `xml:"images>image"`
+		} `xml:"listing"`
+	}
+	suite.Require().NoError(xml.Unmarshal(rec.Body.Bytes(), &feed))
+	suite.Require().Len(feed.Listings, 1)
+	listing := feed.Listings[0]
+	assert.Equal(suite.T(), "OneBed", listing.Type)
+	assert.Equal(suite.T(), "Feed Heights", listing.Neighborhood)
+	assert.Equal(suite.T(), "5 Feed Ave", listing.Address)
+	assert.Equal(suite.T(), "USD", listing.Price.Currency)
+	assert.Equal(suite.T(), "1500.00", listing.Price.From)
+	assert.Equal(suite.T(), "1725.50", listing.Price.To)
+	assert.Equal(suite.T(), "1400.00", listing.Promotion.Price)
+	assert.Equal(suite.T(), []string{"https://example.com/1.jpg"}, listing.Images)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/feeds/listings.jsonld", nil)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "application/ld+json", rec.Header().Get(echo.HeaderContentType))
+	var itemList struct {
+		Type            string `json:"@type"`
+		NumberOfItems   int    `json:"numberOfItems"`
+		ItemListElement []struct {
+			Item struct {
+				Type             string `json:"@type"`
+				ContainedInPlace struct {
+					Name             string `json:"name"`
+					ContainedInPlace struct {
+						Name string `json:"name"`
+					} `json:"containedInPlace"`
+				} `json:"containedInPlace"`
+				Offers []struct {
+					Price              json.Number `json:"price"`
+					PriceSpecification struct {
+						MinPrice json.Number `json:"minPrice"`
+					} `json:"priceSpecification"`
+				} `json:"offers"`
+			} `json:"item"`
+		} `json:"itemListElement"`
+	}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &itemList))
+	assert.Equal(suite.T(), "ItemList", itemList.Type)
+	suite.Require().Equal(1, itemList.NumberOfItems)
+	apartment := itemList.ItemListElement[0].Item
+	assert.Equal(suite.T(), "Apartment", apartment.Type)
+	assert.Equal(suite.T(), "Feed Tower", apartment.ContainedInPlace.Name)
+	assert.Equal(suite.T(), "Feed Heights", apartment.ContainedInPlace.ContainedInPlace.Name)
+	suite.Require().Len(apartment.Offers, 2)
+	assert.Equal(suite.T(), json.Number("1500.00"), apartment.Offers[0].PriceSpecification.MinPrice)
+	assert.Equal(suite.T(), json.Number("1400.00"), apartment.Offers[1].Price)
+
+	// Portals polling with the last Last-Modified get 304 until a listing changes
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/feeds/listings.xml", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, lastModified)
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusNotModified, rec.Code)
+	assert.Empty(suite.T(), rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/feeds/listings.xml", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	rec = suite.serveAs(suite.accessToken, http.MethodGet, "/api/v1/feeds/listings.csv", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestPublicListings() {
+	neighborhoodID := suite.createNeighborhood("Public Park")
+	buildingID := suite.createBuilding("Public House", neighborhoodID, "9 Open St")
+	rec := suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "TwoBeds", "price_from": 250000, "price_to": 260000, "available": true,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var apartment models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &apartment))
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "OneBed", "price_from": 150000, "price_to": 160000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var draft models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &draft))
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": buildingID, "type": "Studio", "price_from": 100000, "price_to": 110000, "available": true,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var trashed models.Apartment
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &trashed))
+	suite.Require().Equal(http.StatusNoContent, suite.serveAs(suite.accessToken, http.MethodDelete, "/api/v1/apartments/"+trashed.ID.String(), nil).Code)
+
+	publicGet := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(echo.HeaderOrigin, "https://www.example.com")
+		rec := httptest.NewRecorder()
+		suite.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// No credentials needed; only live, available apartments, without back office fields, always paged
+	rec = publicGet("/public/v1/bruschi/apartments?filter[building_id]=" + buildingID)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "public, max-age=300, stale-while-revalidate=300", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(suite.T(), "https://www.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	var page struct {
+		Data       []map[string]any `json:"data"`
+		NextCursor *string          `json:"next_cursor"`
+	}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+	suite.Require().Len(page.Data, 1)
+	assert.Equal(suite.T(), apartment.ID.String(), page.Data[0]["id"])
+	assert.NotContains(suite.T(), page.Data[0], "version")
+
+	rec = publicGet("/public/v1/bruschi/buildings/" + buildingID)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.JSONEq(suite.T(), `{"id": "`+buildingID+`", "neighborhood_id": "`+neighborhoodID+`", "name": "Public House", "address": "9 Open St"}`, rec.Body.String())
+	rec = publicGet("/public/v1/bruschi/apartments/" + trashed.ID.String())
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+	assert.Equal(suite.T(), "no-store", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(suite.T(), http.StatusNotFound, publicGet("/public/v1/bruschi/apartments/"+draft.ID.String()).Code)
+
+	// Buildings and neighborhoods are only listed while they have an available apartment
+	emptyNeighborhoodID := suite.createNeighborhood("Private Park")
+	emptyBuildingID := suite.createBuilding("Private House", emptyNeighborhoodID, "1 Closed St")
+	rec = suite.serveAs(suite.accessToken, http.MethodPost, "/api/v1/apartments", map[string]any{
+		"building_id": emptyBuildingID, "type": "OneBed", "price_from": 150000, "price_to": 160000,
+	})
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	listedIDs := func(target string) []any {
+		rec := publicGet(target)
+		suite.Require().Equal(http.StatusOK, rec.Code)
+		var page struct {
+			Data []map[string]any `json:"data"`
+		}
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &page))
+		ids := make([]any, 0, len(page.Data))
+		for _, record := range page.Data {
+			ids = append(ids, record["id"])
+		}
+		return ids
+	}
+	buildingIDs := listedIDs("/public/v1/bruschi/buildings?limit=200")
+	assert.Contains(suite.T(), buildingIDs, buildingID)
+	assert.NotContains(suite.T(), buildingIDs, emptyBuildingID)
+	neighborhoodIDs := listedIDs("/public/v1/bruschi/neighborhoods?limit=200")
+	assert.Contains(suite.T(), neighborhoodIDs, neighborhoodID)
+	assert.NotContains(suite.T(), neighborhoodIDs, emptyNeighborhoodID)
+	assert.Equal(suite.T(), http.StatusNotFound, publicGet("/public/v1/bruschi/buildings/"+emptyBuildingID).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, publicGet("/public/v1/bruschi/neighborhoods/"+emptyNeighborhoodID).Code)
+	assert.Equal(suite.T(), http.StatusOK, publicGet("/public/v1/bruschi/neighborhoods/"+neighborhoodID).Code)
+
+	// Back office fields cannot be filtered or sorted by
+	assert.Equal(suite.T(), http.StatusBadRequest, publicGet("/public/v1/bruschi/apartments?filter[available]=false").Code)
+	assert.Equal(suite.T(), http.StatusBadRequest, publicGet("/public/v1/bruschi/buildings?sort=-updated_at").Code)
+	assert.Equal(suite.T(), http.StatusOK, publicGet("/public/v1/bruschi/apartments?sort=price_from").Code)
+	assert.Equal(suite.T(), http.StatusNotFound, publicGet("/public/v1/nobody/neighborhoods").Code)
+
+	// Other sites are not allowed by CORS; the back office API still answers any origin
+	req := httptest.NewRequest(http.MethodOptions, "/public/v1/bruschi/neighborhoods", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://evil.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	rec = httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+	assert.Empty(suite.T(), rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	suite.echo.ServeHTTP(rec, req)
+	assert.Equal(suite.T(), "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	// The public API has a rate limit of its own
+	limited := suite.public
+	limited.rateLimit = models.RateLimitQuota{Rate: 0.001, Burst: 2}
+	limited.rateLimitStore = middleware.NewMemoryRateLimitStore()
+	e := echo.New()
+	registerPublicRoutes(e, limited)
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/public/v1/bruschi/neighborhoods", nil))
+		assert.Equal(suite.T(), want, rec.Code, "request %d", i+1)
+	}
+}
+
+func (suite *E2ETestSuite) TestConditionalGet() {
+	neighborhoodID := suite.createNeighborhood("Cached Neighborhood")
+
+	get := func(target string, header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		suite.serve(rec, req)
+		return rec
+	}
+
+	// A single neighborhood has a strong ETag of its version
+	rec := get("/api/v1/neighborhoods/"+neighborhoodID, "", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), `"1"`, rec.Header().Get("ETag"))
+	lastModified := rec.Header().Get("Last-Modified")
+	suite.Require().NotEmpty(lastModified)
+
+	rec = get("/api/v1/neighborhoods/"+neighborhoodID, "If-None-Match", `"1"`)
+	assert.Equal(suite.T(), http.StatusNotModified, rec.Code)
+	assert.Empty(suite.T(), rec.Body.Bytes())
+	rec = get("/api/v1/neighborhoods/"+neighborhoodID, "If-Modified-Since", lastModified)
+	assert.Equal(suite.T(), http.StatusNotModified, rec.Code)
+	rec = get("/api/v1/neighborhoods/"+neighborhoodID, "If-None-Match", `"0"`)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	// The list has a weak ETag of its content
+	rec = get("/api/v1/neighborhoods", "", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.True(suite.T(), strings.HasPrefix(etag, `W/"`), etag)
+	lastModified = rec.Header().Get("Last-Modified")
+	suite.Require().NotEmpty(lastModified)
+
+	rec = get("/api/v1/neighborhoods", "If-None-Match", etag)
+	assert.Equal(suite.T(), http.StatusNotModified, rec.Code)
+	rec = get("/api/v1/neighborhoods", "If-Modified-Since", lastModified)
+	assert.Equal(suite.T(), http.StatusNotModified, rec.Code)
+	rec = get("/api/v1/neighborhoods", "If-Modified-Since", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	// Any change to the list invalidates the copy held by the client
+	time.Sleep(time.Second)
+	suite.createNeighborhood("Another Neighborhood")
+	rec = get("/api/v1/neighborhoods", "If-None-Match", etag)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.NotEqual(suite.T(), etag, rec.Header().Get("ETag"))
+	rec = get("/api/v1/neighborhoods", "If-Modified-Since", lastModified)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+}
+
+func (suite *E2ETestSuite) TestCacheInvalidationAcrossInstances() {
+	neighborhoodID := suite.createNeighborhood("Cached Once")
+	ctx, cancel := context.WithCancel(requestctx.WithTenant(context.Background(), models.DefaultOrganizationID))
+	defer cancel()
+
+	// Two server instances, each with its own cache
+	first := repositories.NewCache(suite.db, 100, time.Hour)
+	second := repositories.NewCache(suite.db, 100, time.Hour)
+	suite.Require().NoError(second.Listen(ctx, suite.dbURL, zap.NewNop()))
+	firstRepo := first.Neighborhoods(repositories.NewNeighborhoodRepository(suite.db))
+	secondRepo := second.Neighborhoods(repositories.NewNeighborhoodRepository(suite.db))
+
+	cached, err := secondRepo.GetByID(ctx, neighborhoodID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Cached Once", cached.Name)
+
+	// A write on the first instance reaches the second once committed
+	txManager := repositories.NewTxManager(suite.db)
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		updated := cached
+		updated.Name = "Cached Twice"
+		updated.Version++
+		return firstRepo.Save(ctx, updated)
+	})
+	suite.Require().NoError(err)
+
+	assert.Eventually(suite.T(), func() bool {
+		neighborhood, err := secondRepo.GetByID(ctx, neighborhoodID)
+		return err == nil && neighborhood.Name == "Cached Twice"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func (suite *E2ETestSuite) TestRateLimitByClientIP() {
+	limited := func(trustedProxies []string) *echo.Echo {
+		e := echo.New()
+		e.IPExtractor = ipExtractor(trustedProxies)
+		e.POST("/api/v1/auth/login", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+			middleware.RateLimit(middleware.RateLimitConfig{
+				Name:  "ip",
+				Store: middleware.NewMemoryRateLimitStore(),
+				Quota: models.RateLimitQuota{Rate: 0.001, Burst: 2},
+			}))
+		return e
+	}
+	login := func(e *echo.Echo, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Made-up forwarding headers do not buy a fresh bucket
+	direct := limited(nil)
+	assert.Equal(suite.T(), http.StatusOK, login(direct, "203.0.113.1"))
+	assert.Equal(suite.T(), http.StatusOK, login(direct, "203.0.113.2"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, login(direct, "203.0.113.3"))
+
+	// unless they come from a trusted proxy, httptest's 192.0.2.1
+	proxied := limited([]string{"192.0.2.0/24"})
+	for i := 1; i <= 3; i++ {
+		assert.Equal(suite.T(), http.StatusOK, login(proxied, fmt.Sprintf("203.0.113.%d", i)))
+	}
+}
+
+func (suite *E2ETestSuite) TestRateLimit() {
+	// Two instances sharing the Postgres store count against the same buckets
+	limited := func() *echo.Echo {
+		e := echo.New()
+		e.GET("/api/v1/buildings", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+			func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if key := c.Request().Header.Get(middleware.APIKeyHeader); key != "" {
+						principal := models.Principal{TenantID: models.DefaultOrganizationID, APIKeyID: uuid.MustParse(key)}
+						c.SetRequest(c.Request().WithContext(requestctx.WithPrincipal(c.Request().Context(), principal)))
+					}
+					return next(c)
+				}
+			},
+			middleware.RateLimit(middleware.RateLimitConfig{
+				Name:   "test",
+				Store:  repositories.NewRateLimitRepository(suite.db),
+				Quota:  models.RateLimitQuota{Rate: 1000, Burst: 1000},
+				Routes: map[string]models.RateLimitQuota{"GET /api/v1/buildings": {Rate: 0.001, Burst: 2}},
+			}))
+		return e
+	}
+	instances := []*echo.Echo{limited(), limited()}
+	get := func(instance int, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/buildings", nil)
+		if apiKey != "" {
+			req.Header.Set(middleware.APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		instances[instance].ServeHTTP(rec, req)
+		return rec
+	}
+
+	apiKey := uuid.NewString()
+	rec := get(0, apiKey)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "2", rec.Header().Get(middleware.HeaderRateLimitLimit))
+	assert.Equal(suite.T(), "1", rec.Header().Get(middleware.HeaderRateLimitRemaining))
+	rec = get(1, apiKey)
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.Equal(suite.T(), "0", rec.Header().Get(middleware.HeaderRateLimitRemaining))
+	rec = get(0, apiKey)
+	assert.Equal(suite.T(), http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(suite.T(), rec.Header().Get(echo.HeaderRetryAfter))
+	assert.NotEmpty(suite.T(), rec.Header().Get(middleware.HeaderRateLimitReset))
+
+	// Other clients have buckets of their own
+	assert.Equal(suite.T(), http.StatusOK, get(1, uuid.NewString()).Code)
+	assert.Equal(suite.T(), http.StatusOK, get(1, "").Code)
+}
+
+func (suite *E2ETestSuite) TestIdempotencyKey() {
+	post := func(key string, name string) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(map[string]string{"name": name})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+		rec := httptest.NewRecorder()
+		suite.serve(rec, req)
+		return rec
+	}
+
+	first := post("create-once", "Idempotent Neighborhood")
+	suite.Require().Equal(http.StatusCreated, first.Code)
+	assert.Empty(suite.T(), first.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	// A retry gets the original response and creates nothing
+	retry := post("create-once", "Idempotent Neighborhood")
+	assert.Equal(suite.T(), http.StatusCreated, retry.Code)
+	assert.Equal(suite.T(), "true", retry.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.JSONEq(suite.T(), first.Body.String(), retry.Body.String())
+	assert.Equal(suite.T(), first.Header().Get("ETag"), retry.Header().Get("ETag"))
+	var count int
+	suite.Require().NoError(suite.db.Get(&count, `SELECT count(*) FROM neighborhoods WHERE name = 'Idempotent Neighborhood'`))
+	assert.Equal(suite.T(), 1, count)
+
+	// The same key with another body is refused
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, post("create-once", "Another Neighborhood").Code)
+
+	// Another key creates another neighborhood
+	assert.Equal(suite.T(), http.StatusCreated, post("create-twice", "Idempotent Neighborhood").Code)
+
+	// An expired key starts over
+	_, err := suite.db.Exec(`UPDATE idempotency_keys SET created_at = created_at - interval '25 hours' WHERE key = 'create-once'`)
+	suite.Require().NoError(err)
+	rec := post("create-once", "Another Neighborhood")
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
+	assert.Empty(suite.T(), rec.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	// A request that never finished holds its key until its lease runs out
+	_, err = suite.db.Exec(`UPDATE idempotency_keys SET status = NULL, headers = NULL, body = NULL WHERE key = 'create-twice'`)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), http.StatusConflict, post("create-twice", "Idempotent Neighborhood").Code)
+	_, err = suite.db.Exec(`UPDATE idempotency_keys SET locked_until = now() - interval '1 second' WHERE key = 'create-twice'`)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, post("create-twice", "Another Neighborhood").Code)
+	rec = post("create-twice", "Idempotent Neighborhood")
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code)
+	assert.Empty(suite.T(), rec.Header().Get(middleware.HeaderIdempotentReplayed))
+}
+
+func (suite *E2ETestSuite) TestRequestID() {
+	get := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/neighborhoods/"+uuid.NewString(), nil)
+		if requestID != "" {
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+		}
+		rec := httptest.NewRecorder()
+		suite.serve(rec, req)
+		return rec
+	}
+
+	// The client's request ID is kept and quoted in error bodies
+	rec := get("support-ticket-42")
+	suite.Require().Equal(http.StatusNotFound, rec.Code)
+	assert.Equal(suite.T(), "support-ticket-42", rec.Header().Get(echo.HeaderXRequestID))
+	var body handlers.Problem
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(suite.T(), "support-ticket-42", body.RequestID)
+
+	// Missing or malformed ones are replaced
+	for _, requestID := range []string{"", "not a valid id!"} {
+		rec = get(requestID)
+		generated := rec.Header().Get(echo.HeaderXRequestID)
+		_, err := uuid.Parse(generated)
+		assert.NoError(suite.T(), err)
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(suite.T(), generated, body.RequestID)
+	}
+
+	// Audit entries record the request ID of the change
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/neighborhoods", strings.NewReader(`{"name": "Traced"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(echo.HeaderXRequestID, "create-traced")
+	rec = httptest.NewRecorder()
+	suite.serve(rec, req)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+	var requestID string
+	suite.Require().NoError(suite.db.Get(&requestID, `SELECT request_id FROM audit_log WHERE entity_type = 'neighborhood' ORDER BY created_at DESC LIMIT 1`))
+	assert.Equal(suite.T(), "create-traced", requestID)
+}
+
 func TestE2ETestSuite(t *testing.T) {
 	suite.Run(t, new(E2ETestSuite))
 }
@@ -9,6 +9,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,6 +21,7 @@ import (
 	"github.com/Andre385/bruschirentals-backend/internal/handlers"
 	"github.com/Andre385/bruschirentals-backend/internal/logging"
 	"github.com/Andre385/bruschirentals-backend/internal/middleware"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
 	"github.com/Andre385/bruschirentals-backend/internal/repositories"
 	"github.com/Andre385/bruschirentals-backend/internal/services"
 	"github.com/Andre385/bruschirentals-backend/internal/tracing"
@@ -54,6 +56,7 @@ func main() {
 	// Initialize logger
 	logger := logging.InitLogger()
 	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
 
 	// Database connection
 	db, err := sqlx.Connect("postgres", cfg.DatabaseURL)
@@ -71,10 +74,13 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	e := echo.New()
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
+	e.IPExtractor = ipExtractor(cfg.TrustedProxies)
 
 	// Add middlewares
-	e.Use(echomw.CORS())
+	e.Use(middleware.CORS(cfg.PublicOrigins))
 	e.Use(echomw.Recover())
+	e.Use(middleware.RequestID())
 
 	// Add tracing middleware if tracing enabled
 	if cfg.OTLPEndpoint != "" {
@@ -85,12 +91,87 @@ func main() {
 	e.Use(middleware.Logging(logger))
 
 	// Initialize repositories
-	neighborhoodRepo := repositories.NewNeighborhoodRepository(db)
-	buildingRepo := repositories.NewBuildingRepository(db)
+	cache := repositories.NewCache(db, cfg.CacheSize, cfg.CacheTTL)
+	neighborhoodRepo := cache.Neighborhoods(repositories.NewNeighborhoodRepository(db))
+	buildingRepo := cache.Buildings(repositories.NewBuildingRepository(db))
+	webhookRepo := repositories.NewWebhookRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	inquiryRepo := repositories.NewInquiryRepository(db)
+	apartmentRepo := repositories.NewApartmentRepository(db)
+	organizationRepo := repositories.NewOrganizationRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	trashRepo := repositories.NewTrashRepository(db)
+	importRepo := repositories.NewImportRepository(db)
+	exportRepo := repositories.NewExportRepository(db)
+	feedRepo := repositories.NewFeedRepository(db)
+	txManager := repositories.NewTxManager(db)
 
 	// Initialize services
-	neighborhoodService := services.NewNeighborhoodService(neighborhoodRepo)
-	buildingService := services.NewBuildingService(buildingRepo, neighborhoodRepo)
+	eventDispatcher := services.NewEventDispatcher(outboxRepo, txManager)
+	auditService := services.NewAuditService(auditRepo)
+	webhookService := services.NewWebhookService(webhookRepo, auditRepo, txManager)
+	neighborhoodService := services.NewNeighborhoodService(neighborhoodRepo, outboxRepo, auditRepo, txManager)
+	buildingService := services.NewBuildingService(buildingRepo, neighborhoodRepo, outboxRepo, auditRepo, txManager)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, txManager, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	userService := services.NewUserService(userRepo, refreshTokenRepo, auditRepo, txManager)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, auditRepo, txManager)
+	inquiryService := services.NewInquiryService(inquiryRepo, buildingRepo, auditRepo, txManager)
+	apartmentService := services.NewApartmentService(apartmentRepo, buildingRepo, outboxRepo, auditRepo, txManager)
+	organizationService := services.NewOrganizationService(organizationRepo, userRepo, auditRepo, txManager)
+	searchService := services.NewSearchService(searchRepo)
+	// The index reads past the cache, whose invalidations may reach an instance
+	// after the index changes they go with
+	autocompleteService := services.NewAutocompleteService(organizationRepo, repositories.NewNeighborhoodRepository(db), repositories.NewBuildingRepository(db), searchRepo, repositories.NewNotificationRepository(db))
+	trashService := services.NewTrashService(trashRepo, cfg.TrashRetention)
+	importService := services.NewImportService(importRepo, neighborhoodRepo, buildingRepo, apartmentRepo, neighborhoodService, buildingService, apartmentService, txManager)
+	exportService := services.NewExportService(exportRepo, txManager)
+	feedService := services.NewFeedService(feedRepo, organizationRepo, cfg.FeedCurrency, services.XMLFeedFormat{}, services.JSONLDFeedFormat{})
+
+	// Bootstrap the admin account of the platform organization
+	if cfg.AdminEmail != "" {
+		if _, err := authService.EnsureUser(ctx, models.DefaultOrganizationID, cfg.AdminEmail, cfg.AdminPassword, models.RoleAdmin); err != nil {
+			logger.Fatal("Failed to create admin user", zap.Error(err))
+		}
+	}
+
+	// Subscribe to domain events
+	eventDispatcher.SubscribeAll(webhookService.HandleEvent)
+	for _, eventType := range []models.EventType{
+		models.EventNeighborhoodCreated, models.EventNeighborhoodUpdated, models.EventNeighborhoodDeleted, models.EventNeighborhoodRestored,
+		models.EventBuildingCreated, models.EventBuildingUpdated, models.EventBuildingDeleted, models.EventBuildingRestored,
+	} {
+		eventDispatcher.Subscribe(eventType, autocompleteService.HandleEvent)
+	}
+
+	// Start background workers
+	if err := cache.Listen(ctx, cfg.DatabaseURL, logger); err != nil {
+		logger.Fatal("Failed to listen for cache invalidations", zap.Error(err))
+	}
+	if err := autocompleteService.Listen(ctx, cfg.DatabaseURL, logger); err != nil {
+		logger.Fatal("Failed to listen for autocomplete index changes", zap.Error(err))
+	}
+	go eventDispatcher.Run(ctx, time.Second, logger)
+	go webhookService.Run(ctx, 5*time.Second, logger)
+	go trashService.Run(ctx, time.Hour, logger)
+	go func() {
+		if err := autocompleteService.Warm(ctx); err != nil {
+			logger.Error("Failed to load autocomplete index", zap.Error(err))
+		}
+	}()
+
+	// Rate limits, shared by every instance when kept in Postgres
+	var rateLimitStore middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if cfg.RateLimitStore == "postgres" {
+		rateLimitStore = repositories.NewRateLimitRepository(db)
+	}
+	rateLimitRoutes, err := middleware.ParseRateLimitRoutes(cfg.RateLimitRoutes)
+	if err != nil {
+		logger.Fatal("Failed to parse rate limit routes", zap.Error(err))
+	}
 
 	// Initialize handlers
 	var tracer trace.Tracer
@@ -100,22 +181,57 @@ func main() {
 	healthHandler := handlers.NewHealthHandler(db, logger, tracer)
 	neighborhoodHandler := handlers.NewNeighborhoodHandler(neighborhoodService)
 	buildingHandler := handlers.NewBuildingHandler(buildingService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(userService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	inquiryHandler := handlers.NewInquiryHandler(inquiryService)
+	apartmentHandler := handlers.NewApartmentHandler(apartmentService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	autocompleteHandler := handlers.NewAutocompleteHandler(autocompleteService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	importHandler := handlers.NewImportHandler(importService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	feedHandler := handlers.NewFeedHandler(feedService)
+	publicHandler := handlers.NewPublicHandler(neighborhoodService, buildingService, apartmentService)
 
-	e.GET("/api/v1/health", healthHandler.CheckHealth)
-
-	// Neighborhood routes
-	e.POST("/api/v1/neighborhoods", neighborhoodHandler.Create)
-	e.GET("/api/v1/neighborhoods/:id", neighborhoodHandler.Get)
-	e.PUT("/api/v1/neighborhoods/:id", neighborhoodHandler.Update)
-	e.DELETE("/api/v1/neighborhoods/:id", neighborhoodHandler.Delete)
-	e.GET("/api/v1/neighborhoods", neighborhoodHandler.List)
-
-	// Building routes
-	e.POST("/api/v1/buildings", buildingHandler.Create)
-	e.GET("/api/v1/buildings/:id", buildingHandler.Get)
-	e.PUT("/api/v1/buildings/:id", buildingHandler.Update)
-	e.DELETE("/api/v1/buildings/:id", buildingHandler.Delete)
-	e.GET("/api/v1/buildings", buildingHandler.List)
+	registerRoutes(e, routeHandlers{
+		health:       healthHandler,
+		auth:         authHandler,
+		neighborhood: neighborhoodHandler,
+		building:     buildingHandler,
+		webhook:      webhookHandler,
+		audit:        auditHandler,
+		user:         userHandler,
+		apiKey:       apiKeyHandler,
+		inquiry:      inquiryHandler,
+		apartment:    apartmentHandler,
+		organization: organizationHandler,
+		search:       searchHandler,
+		autocomplete: autocompleteHandler,
+		trash:        trashHandler,
+		imports:      importHandler,
+		exports:      exportHandler,
+		feeds:        feedHandler,
+	}, middleware.RateLimit(middleware.RateLimitConfig{
+		Name:  "ip",
+		Store: rateLimitStore,
+		Quota: models.RateLimitQuota{Rate: cfg.IPRateLimit, Burst: cfg.IPRateBurst},
+	}), middleware.Auth(authService, apiKeyService), middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "api",
+		Store:  rateLimitStore,
+		Quota:  models.RateLimitQuota{Rate: cfg.RateLimit, Burst: cfg.RateLimitBurst},
+		Routes: rateLimitRoutes,
+	}), middleware.Idempotency(repositories.NewIdempotencyRepository(db)))
+	registerPublicRoutes(e, publicRoutes{
+		handler:        publicHandler,
+		organizations:  organizationService,
+		cacheTTL:       cfg.PublicCacheTTL,
+		rateLimit:      models.RateLimitQuota{Rate: cfg.PublicRateLimit, Burst: cfg.PublicRateBurst},
+		rateLimitStore: rateLimitStore,
+	})
 
 	// Swagger docs
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
@@ -131,9 +247,10 @@ func main() {
 	// Wait for signal
 	<-sigChan
 	logger.Info("Shutting down server...")
+	cancel()
 
 	// Shutdown server
-	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 10*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Failed to shutdown server", zap.Error(err))
@@ -148,3 +265,180 @@ func main() {
 
 	logger.Info("Server stopped")
 }
+
+// routeHandlers holds the handlers mounted by registerRoutes.
+type routeHandlers struct {
+	health       *handlers.HealthHandler
+	auth         *handlers.AuthHandler
+	neighborhood *handlers.NeighborhoodHandler
+	building     *handlers.BuildingHandler
+	webhook      *handlers.WebhookHandler
+	audit        *handlers.AuditHandler
+	user         *handlers.UserHandler
+	apiKey       *handlers.APIKeyHandler
+	inquiry      *handlers.InquiryHandler
+	apartment    *handlers.ApartmentHandler
+	organization *handlers.OrganizationHandler
+	search       *handlers.SearchHandler
+	autocomplete *handlers.AutocompleteHandler
+	trash        *handlers.TrashHandler
+	imports      *handlers.ImportHandler
+	exports      *handlers.ExportHandler
+	feeds        *handlers.FeedHandler
+}
+
+// registerRoutes mounts the API routes. Everything except the health check and
+// the auth endpoints requires a valid access token or API key whose role or
+// scopes grant the route's permission. Everything except the health check is
+// rate limited by limit, per API key or user once authenticated and per IP
+// before. limitIP counts authenticated routes per IP ahead of authenticate, so
+// that requests with made-up credentials are turned away before they are
+// looked up. Authenticated POST requests may be retried safely with an
+// Idempotency-Key, which idempotent handles.
+func registerRoutes(e *echo.Echo, h routeHandlers, limitIP, authenticate, limit, idempotent echo.MiddlewareFunc) {
+	can := middleware.RequirePermission
+
+	e.GET("/api/v1/health", h.health.CheckHealth)
+
+	// Auth routes
+	e.POST("/api/v1/auth/login", h.auth.Login, limit)
+	e.POST("/api/v1/auth/refresh", h.auth.Refresh, limit)
+	e.POST("/api/v1/auth/logout", h.auth.Logout, limit)
+
+	api := e.Group("/api/v1", limitIP, authenticate, limit, idempotent)
+
+	// Neighborhood routes
+	api.POST("/neighborhoods", h.neighborhood.Create, can(models.PermNeighborhoodsWrite))
+	api.GET("/neighborhoods/:id", h.neighborhood.Get, can(models.PermNeighborhoodsRead))
+	api.PUT("/neighborhoods/:id", h.neighborhood.Update, can(models.PermNeighborhoodsWrite))
+	api.PATCH("/neighborhoods/:id", h.neighborhood.Patch, can(models.PermNeighborhoodsWrite))
+	api.DELETE("/neighborhoods/:id", h.neighborhood.Delete, can(models.PermNeighborhoodsDelete))
+	api.POST("/neighborhoods/:id/restore", h.neighborhood.Restore, can(models.PermNeighborhoodsDelete))
+	api.POST("/neighborhoods/:id/reassign", h.neighborhood.Reassign, can(models.PermNeighborhoodsDelete))
+	api.GET("/neighborhoods", h.neighborhood.List, can(models.PermNeighborhoodsRead))
+
+	// Building routes
+	api.POST("/buildings", h.building.Create, can(models.PermBuildingsWrite))
+	api.GET("/buildings/:id", h.building.Get, can(models.PermBuildingsRead))
+	api.PUT("/buildings/:id", h.building.Update, can(models.PermBuildingsWrite))
+	api.PATCH("/buildings/:id", h.building.Patch, can(models.PermBuildingsWrite))
+	api.DELETE("/buildings/:id", h.building.Delete, can(models.PermBuildingsDelete))
+	api.POST("/buildings/:id/restore", h.building.Restore, can(models.PermBuildingsDelete))
+	api.GET("/buildings", h.building.List, can(models.PermBuildingsRead))
+
+	// Apartment routes
+	api.POST("/apartments", h.apartment.Create, can(models.PermApartmentsWrite))
+	api.GET("/apartments/:id", h.apartment.Get, can(models.PermApartmentsRead))
+	api.PUT("/apartments/:id", h.apartment.Update, can(models.PermApartmentsWrite))
+	api.PATCH("/apartments/:id", h.apartment.Patch, can(models.PermApartmentsWrite))
+	api.DELETE("/apartments/:id", h.apartment.Delete, can(models.PermApartmentsDelete))
+	api.POST("/apartments/:id/restore", h.apartment.Restore, can(models.PermApartmentsDelete))
+	api.GET("/apartments", h.apartment.List, can(models.PermApartmentsRead))
+
+	// Trash routes
+	api.GET("/trash", h.trash.List, can(models.PermNeighborhoodsRead), can(models.PermBuildingsRead), can(models.PermApartmentsRead))
+
+	// Import routes
+	importers := []echo.MiddlewareFunc{can(models.PermNeighborhoodsWrite), can(models.PermBuildingsWrite), can(models.PermApartmentsWrite)}
+	api.POST("/imports", h.imports.Create, importers...)
+	api.GET("/imports/:id", h.imports.Get, importers...)
+	api.GET("/imports/:id/errors", h.imports.Errors, importers...)
+	api.GET("/imports", h.imports.List, importers...)
+
+	// Export routes
+	api.GET("/exports/neighborhoods", h.exports.Neighborhoods, can(models.PermNeighborhoodsRead))
+	api.GET("/exports/buildings", h.exports.Buildings, can(models.PermBuildingsRead), can(models.PermNeighborhoodsRead))
+	api.GET("/exports/apartments", h.exports.Apartments, can(models.PermApartmentsRead), can(models.PermBuildingsRead), can(models.PermNeighborhoodsRead))
+
+	// Feed routes
+	api.GET("/feeds/:name", h.feeds.Get, can(models.PermApartmentsRead), can(models.PermBuildingsRead), can(models.PermNeighborhoodsRead))
+
+	// Search routes
+	api.GET("/search", h.search.Search, can(models.PermNeighborhoodsRead), can(models.PermBuildingsRead))
+	api.GET("/autocomplete", h.autocomplete.Autocomplete, can(models.PermNeighborhoodsRead), can(models.PermBuildingsRead))
+
+	// Inquiry routes
+	api.POST("/inquiries", h.inquiry.Create, can(models.PermInquiriesWrite))
+	api.GET("/inquiries/:id", h.inquiry.Get, can(models.PermInquiriesRead))
+	api.GET("/inquiries", h.inquiry.List, can(models.PermInquiriesRead))
+
+	// Webhook routes
+	api.POST("/webhooks", h.webhook.Create, can(models.PermWebhooksManage))
+	api.GET("/webhooks/:id", h.webhook.Get, can(models.PermWebhooksManage))
+	api.PUT("/webhooks/:id", h.webhook.Update, can(models.PermWebhooksManage))
+	api.DELETE("/webhooks/:id", h.webhook.Delete, can(models.PermWebhooksManage))
+	api.GET("/webhooks", h.webhook.List, can(models.PermWebhooksManage))
+	api.GET("/webhooks/:id/deliveries", h.webhook.ListDeliveries, can(models.PermWebhooksManage))
+	api.GET("/webhooks/deliveries/:id/attempts", h.webhook.ListAttempts, can(models.PermWebhooksManage))
+	api.POST("/webhooks/deliveries/:id/replay", h.webhook.Replay, can(models.PermWebhooksManage))
+
+	// Audit routes
+	api.GET("/audit", h.audit.List, can(models.PermAuditRead))
+
+	// User and role routes
+	api.GET("/roles", h.user.ListRoles, can(models.PermUsersManage))
+	api.POST("/users", h.user.Create, can(models.PermUsersManage))
+	api.GET("/users/:id", h.user.Get, can(models.PermUsersManage))
+	api.GET("/users", h.user.List, can(models.PermUsersManage))
+	api.PUT("/users/:id/role", h.user.SetRole, can(models.PermUsersManage))
+
+	// API key routes
+	api.POST("/api-keys", h.apiKey.Create, can(models.PermAPIKeysManage))
+	api.GET("/api-keys/:id", h.apiKey.Get, can(models.PermAPIKeysManage))
+	api.GET("/api-keys", h.apiKey.List, can(models.PermAPIKeysManage))
+	api.DELETE("/api-keys/:id", h.apiKey.Revoke, can(models.PermAPIKeysManage))
+
+	// Organization routes
+	api.POST("/organizations", h.organization.Create, can(models.PermOrganizationsManage))
+	api.GET("/organizations/:id", h.organization.Get, can(models.PermOrganizationsManage))
+	api.GET("/organizations", h.organization.List, can(models.PermOrganizationsManage))
+}
+
+// publicRoutes configures the public listings API of the website.
+type publicRoutes struct {
+	handler        *handlers.PublicHandler
+	organizations  middleware.OrganizationResolver
+	cacheTTL       time.Duration
+	rateLimit      models.RateLimitQuota
+	rateLimitStore middleware.RateLimitStore
+}
+
+// registerPublicRoutes mounts the public listings API. It needs no credentials
+// and only reads, so responses may be cached by browsers and CDNs; its rate
+// limit is kept apart from the back office API's. CORS is applied to the whole
+// server by middleware.CORS.
+func registerPublicRoutes(e *echo.Echo, p publicRoutes) {
+	public := e.Group(middleware.PublicPrefix+"/:organization",
+		middleware.RateLimit(middleware.RateLimitConfig{Name: "public", Store: p.rateLimitStore, Quota: p.rateLimit}),
+		middleware.CacheControl(p.cacheTTL),
+		middleware.PublicTenant(p.organizations),
+	)
+
+	public.GET("/neighborhoods", p.handler.ListNeighborhoods)
+	public.GET("/neighborhoods/:id", p.handler.GetNeighborhood)
+	public.GET("/buildings", p.handler.ListBuildings)
+	public.GET("/buildings/:id", p.handler.GetBuilding)
+	public.GET("/apartments", p.handler.ListApartments)
+	public.GET("/apartments/:id", p.handler.GetApartment)
+}
+
+// ipExtractor returns how the client IP, which rate limits are counted
+// against, is read from a request. It is the address of the connection, unless
+// that is one of the trusted proxies: then it is the last address in
+// X-Forwarded-For not added by a trusted proxy. Forwarding headers sent by
+// anyone else are ignored, so that clients cannot pick their own IP.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue // rejected by config validation
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
@@ -10,10 +11,30 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
-	DatabaseURL  string `mapstructure:"DATABASE_URL" validate:"required"`
-	Port         string `mapstructure:"PORT" validate:"required"`
-	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
-	Environment  string `mapstructure:"ENV" validate:"required"`
+	DatabaseURL     string        `mapstructure:"DATABASE_URL" validate:"required"`
+	Port            string        `mapstructure:"PORT" validate:"required"`
+	OTLPEndpoint    string        `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Environment     string        `mapstructure:"ENV" validate:"required"`
+	JWTSecret       string        `mapstructure:"JWT_SECRET" validate:"required,min=32"`
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL" validate:"required"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL" validate:"required"`
+	TrashRetention  time.Duration `mapstructure:"TRASH_RETENTION" validate:"required"`
+	FeedCurrency    string        `mapstructure:"FEED_CURRENCY" validate:"required,len=3,uppercase"`
+	PublicOrigins   []string      `mapstructure:"PUBLIC_CORS_ORIGINS" validate:"dive,url"`
+	PublicCacheTTL  time.Duration `mapstructure:"PUBLIC_CACHE_TTL" validate:"required"`
+	PublicRateLimit float64       `mapstructure:"PUBLIC_RATE_LIMIT" validate:"gt=0"`
+	PublicRateBurst int           `mapstructure:"PUBLIC_RATE_BURST" validate:"gt=0"`
+	TrustedProxies  []string      `mapstructure:"TRUSTED_PROXIES" validate:"dive,cidr"`
+	IPRateLimit     float64       `mapstructure:"IP_RATE_LIMIT" validate:"gt=0"`
+	IPRateBurst     int           `mapstructure:"IP_RATE_BURST" validate:"gt=0"`
+	RateLimit       float64       `mapstructure:"RATE_LIMIT" validate:"gt=0"`
+	RateLimitBurst  int           `mapstructure:"RATE_LIMIT_BURST" validate:"gt=0"`
+	RateLimitRoutes string        `mapstructure:"RATE_LIMIT_ROUTES"`
+	RateLimitStore  string        `mapstructure:"RATE_LIMIT_STORE" validate:"oneof=memory postgres"`
+	CacheSize       int           `mapstructure:"CACHE_SIZE" validate:"gt=0"`
+	CacheTTL        time.Duration `mapstructure:"CACHE_TTL" validate:"required"`
+	AdminEmail      string        `mapstructure:"ADMIN_EMAIL" validate:"omitempty,email"`
+	AdminPassword   string        `mapstructure:"ADMIN_PASSWORD" validate:"required_with=AdminEmail"`
 }
 
 // Validate checks the configuration for required fields.
@@ -27,6 +48,23 @@ func Load() (*Config, error) {
 	// Set defaults
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("ENV", "development")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "720h")
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("FEED_CURRENCY", "USD")
+	viper.SetDefault("PUBLIC_CORS_ORIGINS", "")
+	viper.SetDefault("PUBLIC_CACHE_TTL", "5m")
+	viper.SetDefault("PUBLIC_RATE_LIMIT", 10)
+	viper.SetDefault("PUBLIC_RATE_BURST", 30)
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("IP_RATE_LIMIT", 100)
+	viper.SetDefault("IP_RATE_BURST", 200)
+	viper.SetDefault("RATE_LIMIT", 20)
+	viper.SetDefault("RATE_LIMIT_BURST", 40)
+	viper.SetDefault("RATE_LIMIT_ROUTES", "")
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("CACHE_SIZE", 10000)
+	viper.SetDefault("CACHE_TTL", "5m")
 
 	// Load .env file if exists
 	viper.SetConfigName(".env")
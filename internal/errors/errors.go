@@ -1,7 +1,10 @@
 // Package errors centralize our app errors in one place
 package errors
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // Common errors used across the application.
 var (
@@ -11,4 +14,109 @@ var (
 	ErrInvalidPriceRange = errors.New("invalid price range")
 	ErrInvalidPromotion  = errors.New("invalid promotion")
 	ErrInvalidApartment  = errors.New("invalid apartment")
+	ErrInvalidWebhook    = errors.New("invalid webhook")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden")
+	ErrVersionConflict   = errors.New("version conflict")
+	ErrConflict          = errors.New("conflict")
+)
+
+// ParamError reports an invalid query parameter by name. It wraps
+// ErrInvalidInput, so errors.Is(err, ErrInvalidInput) holds for it.
+type ParamError struct {
+	Param string
+}
+
+// NewParamError returns a ParamError for the named query parameter.
+func NewParamError(param string) error {
+	return &ParamError{Param: param}
+}
+
+// Error implements error.
+func (e *ParamError) Error() string {
+	return "invalid query parameter " + e.Param
+}
+
+// Unwrap returns ErrInvalidInput.
+func (e *ParamError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// DependentsError reports that a record cannot be deleted while other records
+// depend on it; Dependents describes them. It wraps ErrConflict.
+type DependentsError struct {
+	Dependents any
+}
+
+// Error implements error.
+func (e *DependentsError) Error() string {
+	return "record has dependents"
+}
+
+// Unwrap returns ErrConflict.
+func (e *DependentsError) Unwrap() error {
+	return ErrConflict
+}
+
+// Field error codes, stable for clients to act on.
+const (
+	CodeRequired   = "required"
+	CodeInvalid    = "invalid"
+	CodeTooShort   = "too_short"
+	CodeTooLong    = "too_long"
+	CodeOutOfRange = "out_of_range"
+	CodeTaken      = "taken"
 )
+
+// FieldError reports why the value of one request field was rejected. Field
+// is named as in the request; Message reads on its own, without the name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports the fields of a record that failed validation. It
+// wraps Kind, one of the ErrInvalid* errors, and ErrInvalidInput.
+type ValidationError struct {
+	Kind   error
+	Fields []FieldError
+}
+
+// NewFieldError returns a ValidationError for a single request field.
+func NewFieldError(field string, code string, message string) error {
+	return &ValidationError{Kind: ErrInvalidInput, Fields: []FieldError{{Field: field, Code: code, Message: message}}}
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return e.Kind.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns Kind and ErrInvalidInput.
+func (e *ValidationError) Unwrap() []error {
+	if e.Kind == ErrInvalidInput {
+		return []error{ErrInvalidInput}
+	}
+	return []error{e.Kind, ErrInvalidInput}
+}
+
+// PrefixFields returns err with prefix added to the name of each of its
+// fields if it is a ValidationError, and err itself otherwise. It renames the
+// fields of a record sent nested in a request.
+func PrefixFields(prefix string, err error) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	fields := make([]FieldError, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		field.Field = prefix + field.Field
+		fields[i] = field
+	}
+	return &ValidationError{Kind: validationErr.Kind, Fields: fields}
+}
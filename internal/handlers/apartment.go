@@ -0,0 +1,249 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// ApartmentHandler handles apartment-related HTTP requests.
+type ApartmentHandler struct {
+	service *services.ApartmentService
+}
+
+// NewApartmentHandler creates a new apartment handler.
+func NewApartmentHandler(service *services.ApartmentService) *ApartmentHandler {
+	return &ApartmentHandler{service: service}
+}
+
+// Create handles POST /api/v1/apartments
+// @Summary Create a new apartment
+// @Description Create a new apartment in a building. Prices are in cents.
+// @Tags apartments
+// @Accept json
+// @Produce json
+// @Param request body services.ApartmentInput true "Apartment details"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} models.Apartment
+// @Header 201 {string} ETag "Version of the apartment"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments [post]
+func (h *ApartmentHandler) Create(c echo.Context) error {
+	var req services.ApartmentInput
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	apartment, err := h.service.CreateApartment(c.Request().Context(), req)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, apartment.Version)
+	return c.JSON(http.StatusCreated, apartment)
+}
+
+// Get handles GET /api/v1/apartments/:id
+// @Summary Get an apartment by ID
+// @Description Retrieve an apartment by its ID
+// @Tags apartments
+// @Produce json
+// @Param id path string true "Apartment ID"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the copy held by the client; 304 if unchanged since"
+// @Success 200 {object} models.Apartment
+// @Header 200 {string} ETag "Version of the apartment"
+// @Header 200 {string} Last-Modified "When the apartment was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments/{id} [get]
+func (h *ApartmentHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	apartment, err := h.service.GetApartment(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendResource(c, apartment.Version, apartment.UpdatedAt, apartment)
+}
+
+// Update handles PUT /api/v1/apartments/:id
+// @Summary Update an apartment
+// @Description Replace the details of an existing apartment
+// @Tags apartments
+// @Accept json
+// @Produce json
+// @Param id path string true "Apartment ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
+// @Param request body services.ApartmentInput true "Updated apartment details"
+// @Success 200 {object} models.Apartment
+// @Header 200 {string} ETag "Version of the apartment"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments/{id} [put]
+func (h *ApartmentHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req services.ApartmentInput
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	apartment, err := h.service.UpdateApartment(c.Request().Context(), id, req, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, apartment.Version)
+	return c.JSON(http.StatusOK, apartment)
+}
+
+// Patch handles PATCH /api/v1/apartments/:id
+// @Summary Partially update an apartment
+// @Description Apply a JSON Merge Patch (RFC 7396) to an apartment: only the fields sent (the fields of services.ApartmentInput) change, null clears a field. The merged apartment is validated and returned.
+// @Tags apartments
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Apartment ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
+// @Param request body map[string]interface{} true "Fields to change"
+// @Success 200 {object} models.Apartment
+// @Header 200 {string} ETag "Version of the apartment"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments/{id} [patch]
+func (h *ApartmentHandler) Patch(c echo.Context) error {
+	id := c.Param("id")
+
+	patch, status, message := readMergePatch(c)
+	if status != 0 {
+		return SendError(c, status, message)
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	apartment, err := h.service.PatchApartment(c.Request().Context(), id, patch, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, apartment.Version)
+	return c.JSON(http.StatusOK, apartment)
+}
+
+// Delete handles DELETE /api/v1/apartments/:id
+// @Summary Delete an apartment
+// @Description Move an apartment to the trash. It can be restored until the trash retention period ends.
+// @Tags apartments
+// @Param id path string true "Apartment ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments/{id} [delete]
+func (h *ApartmentHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	err = h.service.DeleteApartment(c.Request().Context(), id, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Restore handles POST /api/v1/apartments/:id/restore
+// @Summary Restore an apartment from the trash
+// @Description Undo the deletion of an apartment. Returns 409 while its building is in the trash.
+// @Tags apartments
+// @Produce json
+// @Param id path string true "Apartment ID"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 200 {object} models.Apartment
+// @Header 200 {string} ETag "Version of the apartment"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments/{id}/restore [post]
+func (h *ApartmentHandler) Restore(c echo.Context) error {
+	id := c.Param("id")
+
+	apartment, err := h.service.RestoreApartment(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, apartment.Version)
+	return c.JSON(http.StatusOK, apartment)
+}
+
+// List handles GET /api/v1/apartments
+// @Summary List all apartments
+// @Description Retrieve all apartments, most recently updated first. Filter with filter[field] or filter[field][op] on: id, building_id, type, price_from, price_to, last_update, updated_at (the last four sortable).
+// @Tags apartments
+// @Produce json
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the list held by the client; 304 if no apartment changed since"
+// @Success 200 {object} services.ListPage[models.Apartment]
+// @Header 200 {string} ETag "Weak tag of the response body"
+// @Header 200 {string} Last-Modified "When any apartment was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/apartments [get]
+func (h *ApartmentHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	lastModified, err := h.service.ApartmentsLastModified(ctx)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	if fresh(c, "", lastModified) {
+		setLastModified(c, lastModified)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	page, err := h.service.ListApartments(ctx, params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendListModified(c, page, paged, lastModified)
+}
@@ -0,0 +1,124 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler handles API key management HTTP requests.
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler.
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// Create handles POST /api/v1/api-keys
+// @Summary Issue an API key
+// @Description Issue an API key with scopes (listings:read, inquiries:write) and an optional expiry. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "Key details (name, scopes, optional expires_at)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} services.IssuedAPIKey
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) Create(c echo.Context) error {
+	var req struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	key, err := h.service.IssueKey(c.Request().Context(), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+// Get handles GET /api/v1/api-keys/:id
+// @Summary Get an API key by ID
+// @Description Retrieve an API key's metadata, including when it was last used
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/api-keys/{id} [get]
+func (h *APIKeyHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	key, err := h.service.GetKey(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
+
+// List handles GET /api/v1/api-keys
+// @Summary List all API keys
+// @Description Retrieve all API keys, newest first, including revoked and expired ones. Filter with filter[field] or filter[field][op] on: name, created_at (both sortable).
+// @Tags api-keys
+// @Produce json
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.APIKey]
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListKeys(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
+
+// Revoke handles DELETE /api/v1/api-keys/:id
+// @Summary Revoke an API key
+// @Description Revoke an API key. Its metadata is kept for auditing.
+// @Tags api-keys
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c echo.Context) error {
+	id := c.Param("id")
+
+	err := h.service.RevokeKey(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
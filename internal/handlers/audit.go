@@ -0,0 +1,60 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// AuditHandler handles audit log HTTP requests.
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler.
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// List handles GET /api/v1/audit
+// @Summary List audit log entries
+// @Description Retrieve a page of audit entries, newest first, optionally filtered by entity and actor. Also filters with filter[field] or filter[field][op] on: entity_type, entity_id, actor, action, created_at (sortable). Pages default to 50 entries.
+// @Tags audit
+// @Produce json
+// @Param entity_type query string false "Entity type (neighborhood, building, apartment, webhook_subscription, webhook_delivery, user, api_key, inquiry, organization)"
+// @Param entity_id query string false "Entity ID"
+// @Param actor query string false "Actor"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -created_at)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.AuditEntry]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	if !paged {
+		params.Limit = services.DefaultPageSize
+	}
+
+	page, err := h.service.ListEntries(c.Request().Context(), c.QueryParam("entity_type"), c.QueryParam("entity_id"), c.QueryParam("actor"), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, true)
+}
+
+// queryInt parses an optional integer query parameter, returning 0 when absent.
+func queryInt(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,102 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// AuthHandler handles authentication HTTP requests.
+type AuthHandler struct {
+	service *services.AuthService
+}
+
+// NewAuthHandler creates a new auth handler.
+func NewAuthHandler(service *services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// Login handles POST /api/v1/auth/login
+// @Summary Log in
+// @Description Exchange email and password for an access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body map[string]string true "Credentials (email, password)"
+// @Success 200 {object} services.TokenPair
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c echo.Context) error {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	tokens, err := h.service.Login(c.Request().Context(), req.Email, req.Password)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh handles POST /api/v1/auth/refresh
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair. The old refresh token stops working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body map[string]string true "Refresh token (refresh_token)"
+// @Success 200 {object} services.TokenPair
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	tokens, err := h.service.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles POST /api/v1/auth/logout
+// @Summary Log out
+// @Description Revoke a refresh token
+// @Tags auth
+// @Accept json
+// @Param request body map[string]string true "Refresh token (refresh_token)"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	err := h.service.Logout(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
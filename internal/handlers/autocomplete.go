@@ -0,0 +1,51 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// AutocompleteSourceHeader tells clients whether suggestions came from the
+// in-memory index or the database.
+const AutocompleteSourceHeader = "X-Autocomplete-Source"
+
+// AutocompleteHandler handles typeahead HTTP requests.
+type AutocompleteHandler struct {
+	service *services.AutocompleteService
+}
+
+// NewAutocompleteHandler creates a new autocomplete handler.
+func NewAutocompleteHandler(service *services.AutocompleteService) *AutocompleteHandler {
+	return &AutocompleteHandler{service: service}
+}
+
+// Autocomplete handles GET /api/v1/autocomplete
+// @Summary Autocomplete listings
+// @Description Typeahead suggestions for neighborhood names, building names and addresses with a word starting with q. Names starting with q come first. The X-Autocomplete-Source header is index, or database while the in-memory index is loading.
+// @Tags search
+// @Produce json
+// @Param q query string true "Prefix typed so far, e.g. sun"
+// @Param type query string false "Restrict to one type" Enums(neighborhood, building, address)
+// @Param limit query int false "Maximum number of suggestions (default 10, max 25)"
+// @Success 200 {array} models.Suggestion
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/autocomplete [get]
+func (h *AutocompleteHandler) Autocomplete(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return sendServiceError(c, apperrors.NewParamError("limit"))
+	}
+
+	suggestions, source, err := h.service.Suggest(c.Request().Context(), c.QueryParam("q"), c.QueryParam("type"), limit)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	c.Response().Header().Set(AutocompleteSourceHeader, string(source))
+	return c.JSON(http.StatusOK, suggestions)
+}
@@ -14,6 +14,8 @@ type Building struct {
 	Name           string `json:"name"`
 	NeighborhoodID string `json:"neighborhood_id"`
 	Address        string `json:"address"`
+	Version        int64  `json:"version"`
+	UpdatedAt      string `json:"updated_at"`
 }
 
 // BuildingHandler handles building-related HTTP requests.
@@ -33,9 +35,12 @@ func NewBuildingHandler(service *services.BuildingService) *BuildingHandler {
 // @Accept json
 // @Produce json
 // @Param request body map[string]string true "Building details"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
 // @Success 201 {object} Building
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 201 {string} ETag "Version of the building"
+// @Failure 400 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/buildings [post]
 func (h *BuildingHandler) Create(c echo.Context) error {
 	var req struct {
@@ -49,10 +54,10 @@ func (h *BuildingHandler) Create(c echo.Context) error {
 
 	building, err := h.service.CreateBuilding(c.Request().Context(), req.Name, req.NeighborhoodID, req.Address)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
 	}
 
+	setETag(c, building.Version)
 	return c.JSON(http.StatusCreated, building)
 }
 
@@ -62,21 +67,25 @@ func (h *BuildingHandler) Create(c echo.Context) error {
 // @Tags buildings
 // @Produce json
 // @Param id path string true "Building ID"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the copy held by the client; 304 if unchanged since"
 // @Success 200 {object} Building
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "Version of the building"
+// @Header 200 {string} Last-Modified "When the building was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/buildings/{id} [get]
 func (h *BuildingHandler) Get(c echo.Context) error {
 	id := c.Param("id")
 
 	building, err := h.service.GetBuilding(c.Request().Context(), id)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, building)
+	return sendResource(c, building.Version, building.UpdatedAt, building)
 }
 
 // Update handles PUT /api/v1/buildings/:id
@@ -86,11 +95,14 @@ func (h *BuildingHandler) Get(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Building ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
 // @Param request body map[string]string true "Updated building details"
 // @Success 200 {object} Building
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "Version of the building"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/buildings/{id} [put]
 func (h *BuildingHandler) Update(c echo.Context) error {
 	id := c.Param("id")
@@ -104,51 +116,153 @@ func (h *BuildingHandler) Update(c echo.Context) error {
 		return SendError(c, http.StatusBadRequest, "invalid request")
 	}
 
-	building, err := h.service.UpdateBuilding(c.Request().Context(), id, req.Name, req.NeighborhoodID, req.Address)
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	building, err := h.service.UpdateBuilding(c.Request().Context(), id, req.Name, req.NeighborhoodID, req.Address, version)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, building.Version)
+	return c.JSON(http.StatusOK, building)
+}
+
+// Patch handles PATCH /api/v1/buildings/:id
+// @Summary Partially update a building
+// @Description Apply a JSON Merge Patch (RFC 7396) to a building: only the fields sent (name, neighborhood_id, address) change, null clears a field. The merged building is validated and returned.
+// @Tags buildings
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Building ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
+// @Param request body map[string]interface{} true "Fields to change"
+// @Success 200 {object} Building
+// @Header 200 {string} ETag "Version of the building"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/buildings/{id} [patch]
+func (h *BuildingHandler) Patch(c echo.Context) error {
+	id := c.Param("id")
+
+	patch, status, message := readMergePatch(c)
+	if status != 0 {
 		return SendError(c, status, message)
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	building, err := h.service.PatchBuilding(c.Request().Context(), id, patch, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, building.Version)
 	return c.JSON(http.StatusOK, building)
 }
 
 // Delete handles DELETE /api/v1/buildings/:id
 // @Summary Delete a building
-// @Description Delete a building by its ID
+// @Description Move a building to the trash, with its apartments. It can be restored until the trash retention period ends.
 // @Tags buildings
 // @Param id path string true "Building ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
 // @Success 204
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/buildings/{id} [delete]
 func (h *BuildingHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
 
-	err := h.service.DeleteBuilding(c.Request().Context(), id)
+	version, err := ifMatch(c)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
+	}
+
+	err = h.service.DeleteBuilding(c.Request().Context(), id, version)
+	if err != nil {
+		return sendServiceError(c, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// Restore handles POST /api/v1/buildings/:id/restore
+// @Summary Restore a building from the trash
+// @Description Undo the deletion of a building, bringing back the apartments deleted with it. Returns 409 while its neighborhood is in the trash.
+// @Tags buildings
+// @Produce json
+// @Param id path string true "Building ID"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 200 {object} Building
+// @Header 200 {string} ETag "Version of the building"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/buildings/{id}/restore [post]
+func (h *BuildingHandler) Restore(c echo.Context) error {
+	id := c.Param("id")
+
+	building, err := h.service.RestoreBuilding(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, building.Version)
+	return c.JSON(http.StatusOK, building)
+}
+
 // List handles GET /api/v1/buildings
 // @Summary List all buildings
-// @Description Retrieve a list of all buildings
+// @Description Retrieve buildings ordered by name. Without limit or cursor the whole list is returned as an array. Filter with filter[field] or filter[field][op] on: id, name (sortable), neighborhood_id, address (sortable), updated_at (sortable).
 // @Tags buildings
 // @Produce json
-// @Success 200 {array} Building
-// @Failure 500 {object} map[string]string
+// @Param filter[neighborhood_id] query string false "Only buildings in this neighborhood"
+// @Param filter[name][contains] query string false "Only buildings whose name contains this text"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the list held by the client; 304 if no building changed since"
+// @Success 200 {object} services.ListPage[models.Building]
+// @Header 200 {string} ETag "Weak tag of the response body"
+// @Header 200 {string} Last-Modified "When any building was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/buildings [get]
 func (h *BuildingHandler) List(c echo.Context) error {
-	buildings, err := h.service.ListBuildings(c.Request().Context())
+	params, paged, err := listParams(c)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	lastModified, err := h.service.BuildingsLastModified(ctx)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	if fresh(c, "", lastModified) {
+		setLastModified(c, lastModified)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	page, err := h.service.ListBuildings(ctx, params)
+	if err != nil {
+		return sendServiceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, buildings)
+	return sendListModified(c, page, paged, lastModified)
 }
@@ -5,30 +5,170 @@ import (
 	"net/http"
 
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
-// ErrorResponse represents a standardized error response.
-type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  int    `json:"code"`
+// MIMEProblem is the media type of error responses, problem details as
+// described by RFC 7807.
+const MIMEProblem = "application/problem+json"
+
+// ProblemType is a kind of failure. Its URI stays the same across releases, so
+// that clients can tell failures apart without reading the detail.
+type ProblemType struct {
+	URI   string
+	Title string
+}
+
+// Problem types returned by the API.
+var (
+	ProblemValidation           = ProblemType{URI: "/problems/validation-error", Title: "Request failed validation"}
+	ProblemMalformedRequest     = ProblemType{URI: "/problems/malformed-request", Title: "Request body could not be read"}
+	ProblemUnauthorized         = ProblemType{URI: "/problems/unauthorized", Title: "Authentication required"}
+	ProblemForbidden            = ProblemType{URI: "/problems/forbidden", Title: "Permission denied"}
+	ProblemNotFound             = ProblemType{URI: "/problems/not-found", Title: "Resource not found"}
+	ProblemConflict             = ProblemType{URI: "/problems/conflict", Title: "Conflict with the current state of the resource"}
+	ProblemHasDependents        = ProblemType{URI: "/problems/has-dependents", Title: "Resource has dependents"}
+	ProblemPreconditionFailed   = ProblemType{URI: "/problems/precondition-failed", Title: "Resource has changed"}
+	ProblemRequestTooLarge      = ProblemType{URI: "/problems/request-too-large", Title: "Request too large"}
+	ProblemUnsupportedMediaType = ProblemType{URI: "/problems/unsupported-media-type", Title: "Unsupported media type"}
+	ProblemIdempotencyKeyReused = ProblemType{URI: "/problems/idempotency-key-reused", Title: "Idempotency key reused with a different request"}
+	ProblemTooManyRequests      = ProblemType{URI: "/problems/too-many-requests", Title: "Too many requests"}
+	ProblemInternal             = ProblemType{URI: "/problems/internal-error", Title: "Internal server error"}
+	ProblemUnavailable          = ProblemType{URI: "/problems/service-unavailable", Title: "Service unavailable"}
+)
+
+// problemTypes is the problem type of each status sent without one.
+var problemTypes = map[int]ProblemType{
+	http.StatusBadRequest:            ProblemMalformedRequest,
+	http.StatusUnauthorized:          ProblemUnauthorized,
+	http.StatusForbidden:             ProblemForbidden,
+	http.StatusNotFound:              ProblemNotFound,
+	http.StatusConflict:              ProblemConflict,
+	http.StatusPreconditionFailed:    ProblemPreconditionFailed,
+	http.StatusRequestEntityTooLarge: ProblemRequestTooLarge,
+	http.StatusUnsupportedMediaType:  ProblemUnsupportedMediaType,
+	http.StatusTooManyRequests:       ProblemTooManyRequests,
+	http.StatusInternalServerError:   ProblemInternal,
+	http.StatusServiceUnavailable:    ProblemUnavailable,
+}
+
+// Problem is the body of every error response. Errors lists the rejected
+// fields of a validation error, so that forms can point at them; Dependents
+// describes what keeps a record from being deleted. RequestID lets the client
+// quote the request when reporting the error.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Errors     []apperrors.FieldError `json:"errors,omitempty"`
+	Dependents any                    `json:"dependents,omitempty"`
 }
 
-// SendError sends a standardized JSON error response.
-func SendError(c echo.Context, code int, message string) error {
-	return c.JSON(code, ErrorResponse{
-		Error: message,
-		Code:  code,
-	})
+// newProblem builds a problem of type t answering the request in c.
+func newProblem(c echo.Context, status int, t ProblemType, detail string) Problem {
+	return Problem{
+		Type:      t.URI,
+		Title:     t.Title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request().URL.Path,
+		RequestID: requestctx.RequestID(c.Request().Context()),
+	}
+}
+
+// sendProblem sends problem as the response.
+func sendProblem(c echo.Context, problem Problem) error {
+	c.Response().Header().Set(echo.HeaderContentType, MIMEProblem)
+	return c.JSON(problem.Status, problem)
+}
+
+// SendError sends a problem response with the given status and detail, typed
+// after the status.
+func SendError(c echo.Context, status int, detail string) error {
+	t, ok := problemTypes[status]
+	if !ok {
+		t = ProblemType{URI: "about:blank", Title: http.StatusText(status)}
+	}
+	return sendProblem(c, newProblem(c, status, t, detail))
 }
 
-// mapErrorToResponse maps service errors to HTTP status codes and sanitized messages.
-func mapErrorToResponse(err error) (int, string) {
-	if errors.Is(err, apperrors.ErrInvalidID) || errors.Is(err, apperrors.ErrInvalidInput) || errors.Is(err, apperrors.ErrInvalidPriceRange) || errors.Is(err, apperrors.ErrInvalidPromotion) || errors.Is(err, apperrors.ErrInvalidApartment) {
-		return http.StatusBadRequest, "invalid request"
+// SendProblem sends a problem response of type t.
+func SendProblem(c echo.Context, status int, t ProblemType, detail string) error {
+	return sendProblem(c, newProblem(c, status, t, detail))
+}
+
+// sendServiceError maps a service error to a problem response. Validation
+// errors list the rejected fields; the cause of an internal error is logged
+// rather than sent.
+func sendServiceError(c echo.Context, err error) error {
+	var validationErr *apperrors.ValidationError
+	if errors.As(err, &validationErr) {
+		problem := newProblem(c, http.StatusBadRequest, ProblemValidation, "one or more fields are invalid")
+		problem.Errors = validationErr.Fields
+		return sendProblem(c, problem)
+	}
+	var paramErr *apperrors.ParamError
+	if errors.As(err, &paramErr) {
+		problem := newProblem(c, http.StatusBadRequest, ProblemValidation, "invalid query parameter: "+paramErr.Param)
+		problem.Errors = []apperrors.FieldError{{Field: paramErr.Param, Code: apperrors.CodeInvalid, Message: "must be a valid value"}}
+		return sendProblem(c, problem)
+	}
+	if errors.Is(err, apperrors.ErrInvalidID) {
+		problem := newProblem(c, http.StatusBadRequest, ProblemValidation, "invalid ID")
+		problem.Errors = []apperrors.FieldError{{Field: "id", Code: apperrors.CodeInvalid, Message: "must be a valid ID"}}
+		return sendProblem(c, problem)
+	}
+	var dependentsErr *apperrors.DependentsError
+	if errors.As(err, &dependentsErr) {
+		problem := newProblem(c, http.StatusConflict, ProblemHasDependents, "other records depend on this one")
+		problem.Dependents = dependentsErr.Dependents
+		return sendProblem(c, problem)
+	}
+
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidInput), errors.Is(err, apperrors.ErrInvalidPriceRange), errors.Is(err, apperrors.ErrInvalidPromotion), errors.Is(err, apperrors.ErrInvalidApartment), errors.Is(err, apperrors.ErrInvalidWebhook):
+		return SendProblem(c, http.StatusBadRequest, ProblemValidation, "invalid request")
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		return SendError(c, http.StatusUnauthorized, "unauthorized")
+	case errors.Is(err, apperrors.ErrForbidden):
+		return SendError(c, http.StatusForbidden, "forbidden")
+	case errors.Is(err, apperrors.ErrNotFound):
+		return SendError(c, http.StatusNotFound, "not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		return SendError(c, http.StatusConflict, "conflict")
+	case errors.Is(err, apperrors.ErrVersionConflict):
+		return SendError(c, http.StatusPreconditionFailed, "precondition failed")
+	}
+	logging.FromContext(c.Request().Context()).Error("Request failed", zap.Error(err))
+	return SendError(c, http.StatusInternalServerError, "internal server error")
+}
+
+// HTTPErrorHandler is an Echo error handler that answers the errors returned
+// by middleware and the router, such as unknown routes, with problem responses.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && c.Request().Method == http.MethodHead {
+		err = c.NoContent(httpErr.Code)
+	} else if errors.As(err, &httpErr) {
+		detail := http.StatusText(httpErr.Code)
+		if message, ok := httpErr.Message.(string); ok {
+			detail = message
+		}
+		err = SendError(c, httpErr.Code, detail)
+	} else {
+		err = sendServiceError(c, err)
 	}
-	if errors.Is(err, apperrors.ErrNotFound) {
-		return http.StatusNotFound, "not found"
+	if err != nil {
+		logging.FromContext(c.Request().Context()).Error("Failed to send error response", zap.Error(err))
 	}
-	return http.StatusInternalServerError, "internal server error"
 }
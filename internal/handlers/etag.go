@@ -0,0 +1,90 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/labstack/echo/v4"
+)
+
+// etag formats a record version as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag sends the version of the returned record as its ETag.
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", etag(version))
+}
+
+// ifMatch returns the version named by the If-Match header, or 0 when the
+// header is absent or "*". Anything but a single entity tag we issued cannot
+// match the current version, so it fails with ErrVersionConflict.
+func ifMatch(c echo.Context) (int64, error) {
+	value := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	unquoted, ok := strings.CutPrefix(value, `"`)
+	if !ok {
+		return 0, apperrors.ErrVersionConflict
+	}
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return 0, apperrors.ErrVersionConflict
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, apperrors.ErrVersionConflict
+	}
+	return version, nil
+}
+
+// setLastModified sends t as the Last-Modified header, unless it is zero.
+func setLastModified(c echo.Context, t time.Time) {
+	if !t.IsZero() {
+		c.Response().Header().Set(echo.HeaderLastModified, t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// fresh reports whether the client's cached copy is still current. As RFC 9110
+// orders it, If-None-Match is checked against tag when present, comparing tags
+// weakly, and If-Modified-Since against lastModified otherwise. HTTP dates have
+// whole seconds, so lastModified is truncated before comparing.
+func fresh(c echo.Context, tag string, lastModified time.Time) bool {
+	if header := c.Request().Header.Get("If-None-Match"); header != "" {
+		if tag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.Request().Header.Get(echo.HeaderIfModifiedSince))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// sendResource sends a single record with its version as a strong ETag and
+// when it was last modified, or 304 Not Modified when the client's copy is
+// current.
+func sendResource(c echo.Context, version int64, updatedAt time.Time, record any) error {
+	tag := etag(version)
+	c.Response().Header().Set("ETag", tag)
+	setLastModified(c, updatedAt)
+	if fresh(c, tag, updatedAt) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,193 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEJSONLines is the media type of JSON Lines bodies: one JSON value per line.
+const MIMEJSONLines = "application/x-ndjson"
+
+// spreadsheetText escapes a text cell of a CSV file meant for spreadsheets.
+// Spreadsheets run a cell starting with =, +, -, @, tab or carriage return as
+// a formula, so such cells are prefixed with ' to be shown as text instead.
+// Imports drop the ' again.
+func spreadsheetText(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// exportColumns is the CSV layout of an export: its header and how a row fills it.
+type exportColumns[T any] struct {
+	header []string
+	record func(T) []string
+}
+
+// neighborhoodExport lays out exported neighborhoods.
+var neighborhoodExport = exportColumns[models.Neighborhood]{
+	header: []string{"id", "name"},
+	record: func(n models.Neighborhood) []string {
+		return []string{n.ID.String(), spreadsheetText(n.Name)}
+	},
+}
+
+// buildingExport lays out exported buildings. The names match the columns
+// read by imports, so an edited export can be imported again.
+var buildingExport = exportColumns[models.BuildingExport]{
+	header: []string{"id", "neighborhood_id", "neighborhood", "name", "address"},
+	record: func(b models.BuildingExport) []string {
+		return []string{b.ID.String(), b.NeighborhoodID.String(), spreadsheetText(b.Neighborhood), spreadsheetText(b.Name), spreadsheetText(b.Address)}
+	},
+}
+
+// apartmentExport lays out exported apartments like buildingExport; media
+// URLs are separated by spaces.
+var apartmentExport = exportColumns[models.ApartmentExport]{
+	header: []string{"id", "building_id", "neighborhood", "building", "type", "price_from", "price_to", "promotional_price", "images", "videos", "last_update"},
+	record: func(a models.ApartmentExport) []string {
+		promotionalPrice := ""
+		if a.PromotionalPrice != nil {
+			promotionalPrice = strconv.FormatInt(*a.PromotionalPrice, 10)
+		}
+		return []string{
+			a.ID.String(), a.BuildingID.String(), spreadsheetText(a.Neighborhood), spreadsheetText(a.Building), a.Type.String(),
+			strconv.FormatInt(a.Price.From, 10), strconv.FormatInt(a.Price.To, 10), promotionalPrice,
+			spreadsheetText(strings.Join(a.Images, " ")), spreadsheetText(strings.Join(a.Videos, " ")), a.LastUpdate.UTC().Format(time.RFC3339),
+		}
+	},
+}
+
+// ExportHandler handles bulk export HTTP requests.
+type ExportHandler struct {
+	service *services.ExportService
+}
+
+// NewExportHandler creates a new export handler.
+func NewExportHandler(service *services.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// Neighborhoods handles GET /api/v1/exports/neighborhoods
+// @Summary Export neighborhoods
+// @Description Stream every neighborhood as CSV (id, name) or JSON Lines. Takes the filters and sort of the neighborhood list; the export is never paged.
+// @Tags exports
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Success 200 {string} string "CSV or JSON Lines file"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/exports/neighborhoods [get]
+func (h *ExportHandler) Neighborhoods(c echo.Context) error {
+	return sendExport(c, "neighborhoods", neighborhoodExport, h.service.ExportNeighborhoods)
+}
+
+// Buildings handles GET /api/v1/exports/buildings
+// @Summary Export buildings
+// @Description Stream every building, with the name of its neighborhood, as CSV (id, neighborhood_id, neighborhood, name, address) or JSON Lines. Takes the filters and sort of the building list; the export is never paged.
+// @Tags exports
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Param filter[neighborhood_id] query string false "Only buildings in this neighborhood"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Success 200 {string} string "CSV or JSON Lines file"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/exports/buildings [get]
+func (h *ExportHandler) Buildings(c echo.Context) error {
+	return sendExport(c, "buildings", buildingExport, h.service.ExportBuildings)
+}
+
+// Apartments handles GET /api/v1/exports/apartments
+// @Summary Export apartments
+// @Description Stream every apartment, with the names of its building and neighborhood, as CSV (id, building_id, neighborhood, building, type, price_from, price_to, promotional_price, images, videos, last_update) or JSON Lines. Takes the filters and sort of the apartment list; the export is never paged.
+// @Tags exports
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Param filter[building_id] query string false "Only apartments in this building"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. price_from)"
+// @Success 200 {string} string "CSV or JSON Lines file"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/exports/apartments [get]
+func (h *ExportHandler) Apartments(c echo.Context) error {
+	return sendExport(c, "apartments", apartmentExport, h.service.ExportApartments)
+}
+
+// sendExport streams the rows of an export to the response in the requested
+// format as they are read. Nothing is sent before the first row, or the end of
+// an empty export, so a rejected query still gets an error response; an error
+// after that can only cut the download short.
+func sendExport[T any](c echo.Context, name string, columns exportColumns[T], export func(context.Context, services.ListParams, func(T) error) error) error {
+	params, _, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	var contentType, extension string
+	var begin func() error
+	var write func(T) error
+	var end func() error
+	switch format := c.QueryParam("format"); format {
+	case "", "csv":
+		contentType, extension = MIMECSV+"; charset=utf-8", "csv"
+		writer := csv.NewWriter(c.Response())
+		begin = func() error { return writer.Write(columns.header) }
+		write = func(row T) error { return writer.Write(columns.record(row)) }
+		end = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "jsonl":
+		contentType, extension = MIMEJSONLines, "jsonl"
+		encoder := json.NewEncoder(c.Response())
+		begin = func() error { return nil }
+		write = func(row T) error { return encoder.Encode(row) }
+		end = func() error { return nil }
+	default:
+		return sendServiceError(c, apperrors.NewParamError("format"))
+	}
+
+	started := false
+	start := func() error {
+		started = true
+		c.Response().Header().Set(echo.HeaderContentType, contentType)
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`.`+extension+`"`)
+		c.Response().WriteHeader(http.StatusOK)
+		return begin()
+	}
+
+	err = export(c.Request().Context(), params, func(row T) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return write(row)
+	})
+	if err != nil {
+		if started {
+			return err
+		}
+		return sendServiceError(c, err)
+	}
+	if !started {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	return end()
+}
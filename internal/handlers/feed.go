@@ -0,0 +1,61 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// FeedHandler handles listings feed HTTP requests.
+type FeedHandler struct {
+	service *services.FeedService
+}
+
+// NewFeedHandler creates a new feed handler.
+func NewFeedHandler(service *services.FeedService) *FeedHandler {
+	return &FeedHandler{service: service}
+}
+
+// Get handles GET /api/v1/feeds/:name
+// @Summary Get a listings feed
+// @Description Requires authentication: give each portal an API key with the listings:read scope, sent as X-API-Key. Retrieve the organization's available apartments, with their building, neighborhood, price, promotional price and images, for listing portals. listings.xml is a plain XML feed; listings.jsonld is a schema.org ItemList of Apartment items with their Offers. Last-Modified is when any listing last changed; a request with If-Modified-Since gets 304 when nothing changed since.
+// @Tags feeds
+// @Produce xml,json
+// @Param name path string true "Feed file: listings.xml or listings.jsonld"
+// @Param If-Modified-Since header string false "Last-Modified of the copy held by the client"
+// @Success 200 {string} string "Feed"
+// @Success 304 "Not modified"
+// @Header 200 {string} Last-Modified "When a listing last changed"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/feeds/{name} [get]
+func (h *FeedHandler) Get(c echo.Context) error {
+	format, err := h.service.Format(c.Param("name"))
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	lastModified, err := h.service.LastModified(ctx)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	if fresh(c, "", lastModified) {
+		setLastModified(c, lastModified)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	feed, err := h.service.GetFeed(ctx)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setLastModified(c, feed.Updated)
+	c.Response().Header().Set(echo.HeaderContentType, format.ContentType())
+	c.Response().WriteHeader(http.StatusOK)
+	return format.Write(c.Response(), feed)
+}
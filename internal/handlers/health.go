@@ -33,7 +33,7 @@ func NewHealthHandler(db *sqlx.DB, logger *zap.Logger, tracer trace.Tracer) *Hea
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]string "status: healthy"
-// @Failure 503 {object} ErrorResponse "Database unhealthy"
+// @Failure 503 {object} Problem "Database unhealthy"
 // @Router /api/v1/health [get]
 func (h *HealthHandler) CheckHealth(c echo.Context) error {
 	var span trace.Span
@@ -0,0 +1,180 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// MIMECSV is the media type of CSV bodies.
+const MIMECSV = "text/csv"
+
+// maxImportBytes caps the size of an uploaded CSV file.
+const maxImportBytes = 10 << 20
+
+// mappingParamPattern matches mapping[field].
+var mappingParamPattern = regexp.MustCompile(`^mapping\[([a-z_]+)\]$`)
+
+// ImportHandler handles CSV import HTTP requests.
+type ImportHandler struct {
+	service *services.ImportService
+}
+
+// NewImportHandler creates a new import handler.
+func NewImportHandler(service *services.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// Create handles POST /api/v1/imports
+// @Summary Import listings from CSV
+// @Description Create or update neighborhoods, buildings or apartments from a CSV file with a header row, one listing per row. Rows are matched to existing listings by natural key: a neighborhood by name, a building by neighborhood and name, an apartment by neighborhood, building and type. Columns are found by field name unless mapping[field] names the header to read instead. If any row is invalid nothing is written; with dry_run=true nothing is written either way. The report lists what each row did, or would do, or why it was rejected; rejected rows can be downloaded from /imports/{id}/errors.
+// @Tags imports
+// @Accept text/csv
+// @Produce json
+// @Param entity query string true "What the rows are: neighborhoods, buildings or apartments"
+// @Param dry_run query bool false "Validate and report without writing anything"
+// @Param mapping[name] query string false "Header of the column holding a field, here name; one parameter per remapped field"
+// @Param file body string true "CSV file. Fields: name (neighborhoods); neighborhood, name, address (buildings); neighborhood, building, type, price_from, price_to, promotional_price, images, videos (apartments; prices in cents, media URLs separated by spaces)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} services.ImportResult
+// @Header 201 {string} Location "URL of the import record"
+// @Failure 400 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/imports [post]
+func (h *ImportHandler) Create(c echo.Context) error {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || mediaType != MIMECSV {
+		return SendError(c, http.StatusUnsupportedMediaType, "unsupported media type")
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return sendServiceError(c, apperrors.NewParamError("dry_run"))
+		}
+	}
+
+	mapping := make(map[string]string)
+	for name, values := range c.QueryParams() {
+		if !strings.HasPrefix(name, "mapping") {
+			continue
+		}
+		match := mappingParamPattern.FindStringSubmatch(name)
+		if match == nil || len(values) != 1 {
+			return sendServiceError(c, apperrors.NewParamError(name))
+		}
+		mapping[match[1]] = values[0]
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBytes)
+	result, err := h.service.Import(c.Request().Context(), models.ImportEntity(c.QueryParam("entity")), mapping, body, dryRun)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/imports/"+result.ID.String())
+	return c.JSON(http.StatusCreated, result)
+}
+
+// Get handles GET /api/v1/imports/:id
+// @Summary Get an import
+// @Description Retrieve the record of an import: its status and how many rows were created, updated, left unchanged or rejected.
+// @Tags imports
+// @Produce json
+// @Param id path string true "Import ID"
+// @Success 200 {object} models.Import
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/imports/{id} [get]
+func (h *ImportHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	imp, err := h.service.GetImport(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, imp)
+}
+
+// Errors handles GET /api/v1/imports/:id/errors
+// @Summary Download the rejected rows of an import
+// @Description Download the rows an import rejected as CSV, with the uploaded header followed by line and error columns. Once fixed, the file can be imported again as is.
+// @Tags imports
+// @Produce text/csv
+// @Param id path string true "Import ID"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/imports/{id}/errors [get]
+func (h *ImportHandler) Errors(c echo.Context) error {
+	id := c.Param("id")
+
+	imp, rejected, err := h.service.ImportErrors(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, MIMECSV+"; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="import-`+imp.ID.String()+`-errors.csv"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write(append(slices.Clone(imp.Header), "line", "error")); err != nil {
+		return err
+	}
+	for _, row := range rejected {
+		cells := make([]string, len(imp.Header))
+		for i, cell := range row.Cells[:min(len(row.Cells), len(cells))] {
+			cells[i] = spreadsheetText(cell)
+		}
+		if err := writer.Write(append(cells, strconv.Itoa(row.Line), row.Error)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// List handles GET /api/v1/imports
+// @Summary List imports
+// @Description Retrieve imports, most recent first. Filter with filter[field] or filter[field][op] on: entity, status, created_at (sortable).
+// @Tags imports
+// @Produce json
+// @Param filter[status] query string false "Only imports with this status (validated, completed or failed)"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -created_at)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.Import]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/imports [get]
+func (h *ImportHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListImports(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
@@ -0,0 +1,102 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// InquiryHandler handles inquiry-related HTTP requests.
+type InquiryHandler struct {
+	service *services.InquiryService
+}
+
+// NewInquiryHandler creates a new inquiry handler.
+func NewInquiryHandler(service *services.InquiryService) *InquiryHandler {
+	return &InquiryHandler{service: service}
+}
+
+// Create handles POST /api/v1/inquiries
+// @Summary Submit an inquiry
+// @Description Submit a prospective tenant's inquiry about a building
+// @Tags inquiries
+// @Accept json
+// @Produce json
+// @Param request body map[string]string true "Inquiry (building_id, name, email, optional phone, message)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} models.Inquiry
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/inquiries [post]
+func (h *InquiryHandler) Create(c echo.Context) error {
+	var req struct {
+		BuildingID string `json:"building_id"`
+		Name       string `json:"name"`
+		Email      string `json:"email"`
+		Phone      string `json:"phone"`
+		Message    string `json:"message"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	inquiry, err := h.service.CreateInquiry(c.Request().Context(), req.BuildingID, req.Name, req.Email, req.Phone, req.Message)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, inquiry)
+}
+
+// Get handles GET /api/v1/inquiries/:id
+// @Summary Get an inquiry by ID
+// @Description Retrieve an inquiry by its ID
+// @Tags inquiries
+// @Produce json
+// @Param id path string true "Inquiry ID"
+// @Success 200 {object} models.Inquiry
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/inquiries/{id} [get]
+func (h *InquiryHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	inquiry, err := h.service.GetInquiry(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, inquiry)
+}
+
+// List handles GET /api/v1/inquiries
+// @Summary List inquiries
+// @Description Retrieve inquiries, newest first, optionally for a single building. Filter with filter[field] or filter[field][op] on: building_id, email, source, created_at (sortable).
+// @Tags inquiries
+// @Produce json
+// @Param building_id query string false "Building ID"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.Inquiry]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/inquiries [get]
+func (h *InquiryHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListInquiries(c.Request().Context(), c.QueryParam("building_id"), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
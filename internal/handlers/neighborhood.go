@@ -3,7 +3,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/services"
 	"github.com/labstack/echo/v4"
 )
@@ -12,8 +14,10 @@ import (
 // @Description Neighborhood model
 // @Success 200 {object} Neighborhood
 type Neighborhood struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Version   int64  `json:"version"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 // NeighborhoodHandler handles neighborhood-related HTTP requests.
@@ -33,9 +37,12 @@ func NewNeighborhoodHandler(service *services.NeighborhoodService) *Neighborhood
 // @Accept json
 // @Produce json
 // @Param request body map[string]string true "Neighborhood name"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
 // @Success 201 {object} Neighborhood
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 201 {string} ETag "Version of the neighborhood"
+// @Failure 400 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/neighborhoods [post]
 func (h *NeighborhoodHandler) Create(c echo.Context) error {
 	var req struct {
@@ -47,10 +54,10 @@ func (h *NeighborhoodHandler) Create(c echo.Context) error {
 
 	neighborhood, err := h.service.CreateNeighborhood(c.Request().Context(), req.Name)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
 	}
 
+	setETag(c, neighborhood.Version)
 	return c.JSON(http.StatusCreated, neighborhood)
 }
 
@@ -60,21 +67,25 @@ func (h *NeighborhoodHandler) Create(c echo.Context) error {
 // @Tags neighborhoods
 // @Produce json
 // @Param id path string true "Neighborhood ID"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the copy held by the client; 304 if unchanged since"
 // @Success 200 {object} Neighborhood
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "Version of the neighborhood"
+// @Header 200 {string} Last-Modified "When the neighborhood was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/neighborhoods/{id} [get]
 func (h *NeighborhoodHandler) Get(c echo.Context) error {
 	id := c.Param("id")
 
 	neighborhood, err := h.service.GetNeighborhood(c.Request().Context(), id)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, neighborhood)
+	return sendResource(c, neighborhood.Version, neighborhood.UpdatedAt, neighborhood)
 }
 
 // Update handles PUT /api/v1/neighborhoods/:id
@@ -84,11 +95,14 @@ func (h *NeighborhoodHandler) Get(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Neighborhood ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
 // @Param request body map[string]string true "Updated neighborhood name"
 // @Success 200 {object} Neighborhood
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "Version of the neighborhood"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/neighborhoods/{id} [put]
 func (h *NeighborhoodHandler) Update(c echo.Context) error {
 	id := c.Param("id")
@@ -100,51 +114,202 @@ func (h *NeighborhoodHandler) Update(c echo.Context) error {
 		return SendError(c, http.StatusBadRequest, "invalid request")
 	}
 
-	neighborhood, err := h.service.UpdateNeighborhood(c.Request().Context(), id, req.Name)
+	version, err := ifMatch(c)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
+		return sendServiceError(c, err)
+	}
+
+	neighborhood, err := h.service.UpdateNeighborhood(c.Request().Context(), id, req.Name, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, neighborhood.Version)
+	return c.JSON(http.StatusOK, neighborhood)
+}
+
+// Patch handles PATCH /api/v1/neighborhoods/:id
+// @Summary Partially update a neighborhood
+// @Description Apply a JSON Merge Patch (RFC 7396) to a neighborhood: only the fields sent (name) change, null clears a field. The merged neighborhood is validated and returned.
+// @Tags neighborhoods
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Neighborhood ID"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
+// @Param request body map[string]interface{} true "Fields to change"
+// @Success 200 {object} Neighborhood
+// @Header 200 {string} ETag "Version of the neighborhood"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/neighborhoods/{id} [patch]
+func (h *NeighborhoodHandler) Patch(c echo.Context) error {
+	id := c.Param("id")
+
+	patch, status, message := readMergePatch(c)
+	if status != 0 {
 		return SendError(c, status, message)
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	neighborhood, err := h.service.PatchNeighborhood(c.Request().Context(), id, patch, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, neighborhood.Version)
 	return c.JSON(http.StatusOK, neighborhood)
 }
 
 // Delete handles DELETE /api/v1/neighborhoods/:id
 // @Summary Delete a neighborhood
-// @Description Delete a neighborhood by its ID
+// @Description Move a neighborhood to the trash. A neighborhood that still has buildings is refused with 409 and the number of buildings and apartments that depend on it, unless cascade=true is passed, which trashes its buildings and their apartments with it. It can be restored until the trash retention period ends.
 // @Tags neighborhoods
+// @Produce json
 // @Param id path string true "Neighborhood ID"
+// @Param cascade query bool false "Also delete the neighborhood's buildings and their apartments"
+// @Param If-Match header string false "ETag of the version being changed; 412 if it has moved on"
 // @Success 204
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/neighborhoods/{id} [delete]
 func (h *NeighborhoodHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
 
-	err := h.service.DeleteNeighborhood(c.Request().Context(), id)
+	cascade := false
+	if value := c.QueryParam("cascade"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return sendServiceError(c, apperrors.NewParamError("cascade"))
+		}
+		cascade = parsed
+	}
+
+	version, err := ifMatch(c)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
+	}
+
+	err = h.service.DeleteNeighborhood(c.Request().Context(), id, version, cascade)
+	if err != nil {
+		return sendServiceError(c, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// Reassign handles POST /api/v1/neighborhoods/:id/reassign
+// @Summary Reassign a neighborhood's buildings and delete it
+// @Description Move every building of a neighborhood to another neighborhood and then move the emptied neighborhood to the trash, all in one transaction.
+// @Tags neighborhoods
+// @Accept json
+// @Param id path string true "Neighborhood ID"
+// @Param If-Match header string false "ETag of the neighborhood being deleted; 412 if it has moved on"
+// @Param request body map[string]string true "Target neighborhood_id"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/neighborhoods/{id}/reassign [post]
+func (h *NeighborhoodHandler) Reassign(c echo.Context) error {
+	id := c.Param("id")
+
+	var req struct {
+		NeighborhoodID string `json:"neighborhood_id"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	err = h.service.ReassignNeighborhood(c.Request().Context(), id, req.NeighborhoodID, version)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Restore handles POST /api/v1/neighborhoods/:id/restore
+// @Summary Restore a neighborhood from the trash
+// @Description Undo the deletion of a neighborhood, bringing back the buildings and apartments deleted with it.
+// @Tags neighborhoods
+// @Produce json
+// @Param id path string true "Neighborhood ID"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 200 {object} Neighborhood
+// @Header 200 {string} ETag "Version of the neighborhood"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/neighborhoods/{id}/restore [post]
+func (h *NeighborhoodHandler) Restore(c echo.Context) error {
+	id := c.Param("id")
+
+	neighborhood, err := h.service.RestoreNeighborhood(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	setETag(c, neighborhood.Version)
+	return c.JSON(http.StatusOK, neighborhood)
+}
+
 // List handles GET /api/v1/neighborhoods
 // @Summary List all neighborhoods
-// @Description Retrieve a list of all neighborhoods
+// @Description Retrieve neighborhoods ordered by name. Without limit or cursor the whole list is returned as an array. Filter with filter[field] or filter[field][op] on: id, name, updated_at (sortable).
 // @Tags neighborhoods
 // @Produce json
-// @Success 200 {array} Neighborhood
-// @Failure 500 {object} map[string]string
+// @Param filter[name][contains] query string false "Only neighborhoods whose name contains this text"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param If-None-Match header string false "ETag of the copy held by the client; 304 if it is current"
+// @Param If-Modified-Since header string false "Last-Modified of the list held by the client; 304 if no neighborhood changed since"
+// @Success 200 {object} services.ListPage[models.Neighborhood]
+// @Header 200 {string} ETag "Weak tag of the response body"
+// @Header 200 {string} Last-Modified "When any neighborhood was last changed"
+// @Success 304 "Not modified"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /api/v1/neighborhoods [get]
 func (h *NeighborhoodHandler) List(c echo.Context) error {
-	neighborhoods, err := h.service.ListNeighborhoods(c.Request().Context())
+	params, paged, err := listParams(c)
 	if err != nil {
-		status, message := mapErrorToResponse(err)
-		return SendError(c, status, message)
+		return sendServiceError(c, err)
+	}
+
+	ctx := c.Request().Context()
+	lastModified, err := h.service.NeighborhoodsLastModified(ctx)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	if fresh(c, "", lastModified) {
+		setLastModified(c, lastModified)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	page, err := h.service.ListNeighborhoods(ctx, params)
+	if err != nil {
+		return sendServiceError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, neighborhoods)
+	return sendListModified(c, page, paged, lastModified)
 }
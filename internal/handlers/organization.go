@@ -0,0 +1,102 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// OrganizationHandler handles organization-related HTTP requests.
+type OrganizationHandler struct {
+	service *services.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler.
+func NewOrganizationHandler(service *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service: service}
+}
+
+// Create handles POST /api/v1/organizations
+// @Summary Create an organization
+// @Description Onboard an organization and its first admin user. Only the platform organization may do this.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body map[string]string true "Organization details (slug, name, admin_email, admin_password)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} models.Organization
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/organizations [post]
+func (h *OrganizationHandler) Create(c echo.Context) error {
+	var req struct {
+		Slug          string `json:"slug"`
+		Name          string `json:"name"`
+		AdminEmail    string `json:"admin_email"`
+		AdminPassword string `json:"admin_password"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	organization, err := h.service.CreateOrganization(c.Request().Context(), req.Slug, req.Name, req.AdminEmail, req.AdminPassword)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, organization)
+}
+
+// Get handles GET /api/v1/organizations/:id
+// @Summary Get an organization by ID
+// @Description Retrieve an organization by its ID
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/organizations/{id} [get]
+func (h *OrganizationHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	organization, err := h.service.GetOrganization(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, organization)
+}
+
+// List handles GET /api/v1/organizations
+// @Summary List organizations
+// @Description Retrieve every organization for the platform, or the caller's own organization. Filter with filter[field] or filter[field][op] on: slug, name, created_at (all sortable).
+// @Tags organizations
+// @Produce json
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.Organization]
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/organizations [get]
+func (h *OrganizationHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListOrganizations(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
@@ -0,0 +1,127 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// filterParamPattern matches filter[field] and filter[field][operator].
+var filterParamPattern = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// listParams reads the filter, sort, limit and cursor query parameters. paged
+// is false when neither limit nor cursor is given; such requests get a bare
+// array, as before pagination existed, of at most MaxPageSize items, with a
+// Link header to the rest when there are more.
+func listParams(c echo.Context) (params services.ListParams, paged bool, err error) {
+	query := c.QueryParams()
+
+	params.Filters, err = parseFilters(query)
+	if err != nil {
+		return params, false, err
+	}
+	params.Sort = query.Get("sort")
+	if query.Has("sort") && (params.Sort == "" || strings.Contains(params.Sort, ",")) {
+		return params, false, apperrors.NewParamError("sort")
+	}
+
+	params.Cursor = query.Get("cursor")
+	paged = query.Has("limit") || params.Cursor != ""
+	if !paged {
+		params.Limit = services.MaxPageSize
+		return params, false, nil
+	}
+
+	params.Limit, err = queryInt(c, "limit")
+	if err != nil {
+		return params, true, apperrors.NewParamError("limit")
+	}
+	if !query.Has("limit") {
+		params.Limit = services.DefaultPageSize
+	} else if params.Limit < 1 {
+		return params, true, apperrors.NewParamError("limit")
+	}
+	return params, true, nil
+}
+
+// parseFilters reads every filter[...] query parameter. Repeated parameters
+// each add a condition; all conditions must hold.
+func parseFilters(query map[string][]string) ([]repositories.Filter, error) {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if strings.HasPrefix(name, "filter") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var filters []repositories.Filter
+	for _, name := range names {
+		match := filterParamPattern.FindStringSubmatch(name)
+		if match == nil {
+			return nil, apperrors.NewParamError(name)
+		}
+		for _, value := range query[name] {
+			filters = append(filters, repositories.Filter{Field: match[1], Operator: repositories.Operator(match[2]), Value: value, Param: name})
+		}
+	}
+	return filters, nil
+}
+
+// sendList writes a page as the {data, next_cursor} envelope, or as a bare
+// array when the client did not ask for a page.
+func sendList[T any](c echo.Context, page services.ListPage[T], paged bool) error {
+	return sendListModified(c, page, paged, time.Time{})
+}
+
+// sendListModified writes a page like sendList, with a weak ETag hashed from
+// the body and lastModified, if known, as Last-Modified. A client whose copy
+// matches gets 304 Not Modified instead; the list is still read, but not sent
+// again.
+func sendListModified[T any](c echo.Context, page services.ListPage[T], paged bool, lastModified time.Time) error {
+	var body any = page
+	if !paged {
+		body = page.Data
+		if page.NextCursor != nil {
+			setNextLink(c, *page.NextCursor)
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Response().Header().Set("ETag", tag)
+	setLastModified(c, lastModified)
+	if fresh(c, tag, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(http.StatusOK, data)
+}
+
+// setNextLink sets a Link header to the page after a bare array that was cut
+// at MaxPageSize items, so that clients which do not paginate can tell the
+// list is incomplete. The link asks for the envelope, with the same filters
+// and sort.
+func setNextLink(c echo.Context, cursor string) {
+	next := *c.Request().URL
+	query := next.Query()
+	query.Set("cursor", cursor)
+	query.Set("limit", strconv.Itoa(services.MaxPageSize))
+	next.RawQuery = query.Encode()
+	c.Response().Header().Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
+}
@@ -0,0 +1,28 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEMergePatch is the media type of JSON Merge Patch (RFC 7396) bodies.
+const MIMEMergePatch = "application/merge-patch+json"
+
+// readMergePatch returns the body of a PATCH request, or the status and message
+// to reject it with when it is not a merge patch.
+func readMergePatch(c echo.Context) ([]byte, int, string) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || mediaType != MIMEMergePatch {
+		return nil, http.StatusUnsupportedMediaType, "unsupported media type"
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, "invalid request"
+	}
+	return patch, 0, ""
+}
@@ -0,0 +1,243 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// PublicNeighborhood is a neighborhood as shown on the public website.
+type PublicNeighborhood struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PublicBuilding is a building as shown on the public website.
+type PublicBuilding struct {
+	ID             string `json:"id"`
+	NeighborhoodID string `json:"neighborhood_id"`
+	Name           string `json:"name"`
+	Address        string `json:"address"`
+}
+
+// PublicApartment is an apartment as shown on the public website.
+type PublicApartment struct {
+	ID               string            `json:"id"`
+	BuildingID       string            `json:"building_id"`
+	Type             string            `json:"type"`
+	Price            models.PriceRange `json:"price"`
+	PromotionalPrice *int64            `json:"promotional_price,omitempty"`
+	Images           []string          `json:"images"`
+	Videos           []string          `json:"videos"`
+	LastUpdate       time.Time         `json:"last_update"`
+}
+
+// Fields the public lists may be filtered and sorted by. Back office fields
+// such as updated_at are left out, so that they cannot be probed through
+// filters either.
+var (
+	publicNeighborhoodFields = []string{"id", "name"}
+	publicBuildingFields     = []string{"id", "neighborhood_id", "name", "address"}
+	publicApartmentFields    = []string{"id", "building_id", "type", "price_from", "price_to", "last_update"}
+)
+
+// publicNeighborhood copies the public fields of a neighborhood.
+func publicNeighborhood(n models.Neighborhood) PublicNeighborhood {
+	return PublicNeighborhood{ID: n.ID.String(), Name: n.Name}
+}
+
+// publicBuilding copies the public fields of a building.
+func publicBuilding(b models.Building) PublicBuilding {
+	return PublicBuilding{ID: b.ID.String(), NeighborhoodID: b.NeighborhoodID.String(), Name: b.Name, Address: b.Address}
+}
+
+// publicApartment copies the public fields of an apartment.
+func publicApartment(a models.Apartment) PublicApartment {
+	return PublicApartment{
+		ID:               a.ID.String(),
+		BuildingID:       a.BuildingID.String(),
+		Type:             a.Type.String(),
+		Price:            a.Price,
+		PromotionalPrice: a.PromotionalPrice,
+		Images:           a.Images,
+		Videos:           a.Videos,
+		LastUpdate:       a.LastUpdate,
+	}
+}
+
+// PublicHandler serves the read-only listings API of the public website. It
+// only returns available apartments and the buildings and neighborhoods that
+// have one, copied field by field into the Public types so that fields added to the
+// models later stay in the back office until they are deliberately published.
+type PublicHandler struct {
+	neighborhoods *services.NeighborhoodService
+	buildings     *services.BuildingService
+	apartments    *services.ApartmentService
+}
+
+// NewPublicHandler creates a new public listings handler.
+func NewPublicHandler(neighborhoods *services.NeighborhoodService, buildings *services.BuildingService, apartments *services.ApartmentService) *PublicHandler {
+	return &PublicHandler{neighborhoods: neighborhoods, buildings: buildings, apartments: apartments}
+}
+
+// ListNeighborhoods handles GET /public/v1/:organization/neighborhoods
+// @Summary List an organization's neighborhoods
+// @Description Public, unauthenticated. Retrieve a page of the neighborhoods with available apartments, ordered by name. Filters and sorts by id and name only; pages default to 50 items.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[PublicNeighborhood]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/neighborhoods [get]
+func (h *PublicHandler) ListNeighborhoods(c echo.Context) error {
+	return sendPublicList(c, h.neighborhoods.ListListedNeighborhoods, publicNeighborhood, publicNeighborhoodFields)
+}
+
+// GetNeighborhood handles GET /public/v1/:organization/neighborhoods/:id
+// @Summary Get a neighborhood
+// @Description Public, unauthenticated. Retrieve a neighborhood with available apartments by its ID.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param id path string true "Neighborhood ID"
+// @Success 200 {object} PublicNeighborhood
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/neighborhoods/{id} [get]
+func (h *PublicHandler) GetNeighborhood(c echo.Context) error {
+	return sendPublic(c, h.neighborhoods.GetListedNeighborhood, publicNeighborhood)
+}
+
+// ListBuildings handles GET /public/v1/:organization/buildings
+// @Summary List an organization's buildings
+// @Description Public, unauthenticated. Retrieve a page of the buildings with available apartments, ordered by name. Filters and sorts by the fields of PublicBuilding only; pages default to 50 items.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param filter[neighborhood_id] query string false "Only buildings in this neighborhood"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[PublicBuilding]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/buildings [get]
+func (h *PublicHandler) ListBuildings(c echo.Context) error {
+	return sendPublicList(c, h.buildings.ListListedBuildings, publicBuilding, publicBuildingFields)
+}
+
+// GetBuilding handles GET /public/v1/:organization/buildings/:id
+// @Summary Get a building
+// @Description Public, unauthenticated. Retrieve a building with available apartments by its ID.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param id path string true "Building ID"
+// @Success 200 {object} PublicBuilding
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/buildings/{id} [get]
+func (h *PublicHandler) GetBuilding(c echo.Context) error {
+	return sendPublic(c, h.buildings.GetListedBuilding, publicBuilding)
+}
+
+// ListApartments handles GET /public/v1/:organization/apartments
+// @Summary List an organization's available apartments
+// @Description Public, unauthenticated. Retrieve a page of available apartments, most recently updated first. Filters and sorts by id, building_id, type, price_from, price_to and last_update only; pages default to 50 items.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param filter[building_id] query string false "Only apartments in this building"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. price_from)"
+// @Param limit query int false "Page size (max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[PublicApartment]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/apartments [get]
+func (h *PublicHandler) ListApartments(c echo.Context) error {
+	return sendPublicList(c, h.apartments.ListAvailableApartments, publicApartment, publicApartmentFields)
+}
+
+// GetApartment handles GET /public/v1/:organization/apartments/:id
+// @Summary Get an available apartment
+// @Description Public, unauthenticated. Retrieve an available apartment by its ID.
+// @Tags public
+// @Produce json
+// @Param organization path string true "Organization slug"
+// @Param id path string true "Apartment ID"
+// @Success 200 {object} PublicApartment
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /public/v1/{organization}/apartments/{id} [get]
+func (h *PublicHandler) GetApartment(c echo.Context) error {
+	return sendPublic(c, h.apartments.GetAvailableApartment, publicApartment)
+}
+
+// sendPublic fetches the record named by the :id path parameter and sends its
+// public fields.
+func sendPublic[T, P any](c echo.Context, get func(context.Context, string) (T, error), public func(T) P) error {
+	record, err := get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, public(record))
+}
+
+// sendPublicList fetches a page and sends the public fields of its records.
+// Only the given fields may be filtered and sorted by. Public lists are always
+// paged, so a request without limit gets the default page size rather than
+// every record.
+func sendPublicList[T, P any](c echo.Context, list func(context.Context, services.ListParams) (services.ListPage[T], error), public func(T) P, fields []string) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+	for _, filter := range params.Filters {
+		if !slices.Contains(fields, filter.Field) {
+			return sendServiceError(c, apperrors.NewParamError(filter.Param))
+		}
+	}
+	if params.Sort != "" && !slices.Contains(fields, strings.TrimPrefix(params.Sort, "-")) {
+		return sendServiceError(c, apperrors.NewParamError("sort"))
+	}
+	if !paged {
+		params.Limit = services.DefaultPageSize
+	}
+
+	page, err := list(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	result := services.ListPage[P]{Data: make([]P, 0, len(page.Data)), NextCursor: page.NextCursor}
+	for _, record := range page.Data {
+		result.Data = append(result.Data, public(record))
+	}
+	return sendList(c, result, true)
+}
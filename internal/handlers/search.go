@@ -0,0 +1,45 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// SearchHandler handles search HTTP requests.
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+// NewSearchHandler creates a new search handler.
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search handles GET /api/v1/search
+// @Summary Search listings
+// @Description Full-text and typo-tolerant search over neighborhood names, building names and addresses. Results are typed (neighborhood, building, address), ranked by score, and carry an HTML snippet with matches in <mark> tags.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search terms, e.g. sunset park 45th"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {array} models.SearchResult
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return sendServiceError(c, apperrors.NewParamError("limit"))
+	}
+
+	results, err := h.service.Search(c.Request().Context(), c.QueryParam("q"), limit)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,44 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// TrashHandler handles trash HTTP requests.
+type TrashHandler struct {
+	service *services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler.
+func NewTrashHandler(service *services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// List handles GET /api/v1/trash
+// @Summary List the trash
+// @Description Retrieve deleted neighborhoods, buildings and apartments that can still be restored, most recently deleted first. Filter with filter[field] or filter[field][op] on: type, parent_id, label, deleted_at (the last two sortable).
+// @Tags trash
+// @Produce json
+// @Param filter[type] query string false "Only items of this type (neighborhood, building or apartment)"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -deleted_at)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.TrashItem]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/trash [get]
+func (h *TrashHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListTrash(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
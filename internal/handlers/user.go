@@ -0,0 +1,145 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// UserHandler handles user and role management HTTP requests.
+type UserHandler struct {
+	service *services.UserService
+}
+
+// NewUserHandler creates a new user handler.
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// ListRoles handles GET /api/v1/roles
+// @Summary List roles
+// @Description Retrieve every role with the permissions it grants
+// @Tags users
+// @Produce json
+// @Success 200 {array} services.RoleInfo
+// @Failure 403 {object} Problem
+// @Router /api/v1/roles [get]
+func (h *UserHandler) ListRoles(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.ListRoles())
+}
+
+// Create handles POST /api/v1/users
+// @Summary Create a user
+// @Description Create a user with an email, password and role (admin, manager, agent, viewer)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body map[string]string true "User details (email, password, role)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} models.User
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/users [post]
+func (h *UserHandler) Create(c echo.Context) error {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	user, err := h.service.CreateUser(c.Request().Context(), req.Email, req.Password, req.Role)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, user)
+}
+
+// Get handles GET /api/v1/users/:id
+// @Summary Get a user by ID
+// @Description Retrieve a user by its ID
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/users/{id} [get]
+func (h *UserHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	user, err := h.service.GetUser(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// List handles GET /api/v1/users
+// @Summary List all users
+// @Description Retrieve all users with their roles. Filter with filter[field] or filter[field][op] on: email (sortable), role, created_at (sortable).
+// @Tags users
+// @Produce json
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.User]
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/users [get]
+func (h *UserHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListUsers(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
+
+// SetRole handles PUT /api/v1/users/:id/role
+// @Summary Change a user's role
+// @Description Assign a new role to a user and revoke their refresh tokens. Users cannot change their own role.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body map[string]string true "New role (role)"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/users/{id}/role [put]
+func (h *UserHandler) SetRole(c echo.Context) error {
+	id := c.Param("id")
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	user, err := h.service.SetRole(c.Request().Context(), id, req.Role)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
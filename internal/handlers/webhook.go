@@ -0,0 +1,242 @@
+// Package handlers provides HTTP handlers for the API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// CreatedWebhookSubscription is returned once on creation and includes the signing secret.
+type CreatedWebhookSubscription struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookHandler handles webhook-related HTTP requests.
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler.
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// Create handles POST /api/v1/webhooks
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to event types. The signing secret is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "Subscription details (url, event_types, optional secret)"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 201 {object} CreatedWebhookSubscription
+// @Failure 400 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(c echo.Context) error {
+	var req struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Secret     string   `json:"secret"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Request().Context(), req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, CreatedWebhookSubscription{
+		WebhookSubscription: subscription,
+		Secret:              subscription.Secret,
+	})
+}
+
+// Get handles GET /api/v1/webhooks/:id
+// @Summary Get a webhook subscription by ID
+// @Description Retrieve a webhook subscription by its ID
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	subscription, err := h.service.GetSubscription(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+// Update handles PUT /api/v1/webhooks/:id
+// @Summary Update a webhook subscription
+// @Description Update the URL, event types or active flag. Re-activating resets the failure count.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body map[string]interface{} true "Updated subscription (url, event_types, active)"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Active     bool     `json:"active"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return SendError(c, http.StatusBadRequest, "invalid request")
+	}
+
+	subscription, err := h.service.UpdateSubscription(c.Request().Context(), id, req.URL, req.EventTypes, req.Active)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+// Delete handles DELETE /api/v1/webhooks/:id
+// @Summary Delete a webhook subscription
+// @Description Delete a webhook subscription and its delivery history
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	err := h.service.DeleteSubscription(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// List handles GET /api/v1/webhooks
+// @Summary List all webhook subscriptions
+// @Description Retrieve a list of all webhook subscriptions. Filter with filter[field] or filter[field][op] on: url (sortable), active, created_at (sortable).
+// @Tags webhooks
+// @Produce json
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -name)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.WebhookSubscription]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) List(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListSubscriptions(c.Request().Context(), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries
+// @Summary List deliveries of a webhook subscription
+// @Description Retrieve the deliveries queued for a subscription, newest first. Filter with filter[field] or filter[field][op] on: event_type, status, created_at (sortable).
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -created_at)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.WebhookDelivery]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListDeliveries(c.Request().Context(), c.Param("id"), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
+
+// ListAttempts handles GET /api/v1/webhooks/deliveries/:id/attempts
+// @Summary List attempts of a webhook delivery
+// @Description Retrieve the HTTP attempts recorded for a delivery, oldest first. Filter with filter[field] or filter[field][op] on: response_status, attempted_at (sortable).
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Param sort query string false "Field to sort by, prefixed with - for descending (e.g. -attempted_at)"
+// @Param limit query int false "Page size (max 200); returns the {data, next_cursor} envelope"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} services.ListPage[models.WebhookDeliveryAttempt]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/deliveries/{id}/attempts [get]
+func (h *WebhookHandler) ListAttempts(c echo.Context) error {
+	params, paged, err := listParams(c)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	page, err := h.service.ListAttempts(c.Request().Context(), c.Param("id"), params)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return sendList(c, page, paged)
+}
+
+// Replay handles POST /api/v1/webhooks/deliveries/:id/replay
+// @Summary Replay a webhook delivery
+// @Description Queue a new delivery carrying the same payload as an earlier one
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Param Idempotency-Key header string false "Unique key of the request; retries with it get the first response again"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /api/v1/webhooks/deliveries/{id}/replay [post]
+func (h *WebhookHandler) Replay(c echo.Context) error {
+	id := c.Param("id")
+
+	delivery, err := h.service.ReplayDelivery(c.Request().Context(), id)
+	if err != nil {
+		return sendServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// loggerKey is the context key under which the request logger is stored.
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger, which FromContext returns.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by the Logging middleware, which
+// tags every line with the request ID. Outside a request it returns the global
+// logger, tagged with the request ID in ctx if there is one.
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	if requestID := requestctx.RequestID(ctx); requestID != "" {
+		return zap.L().With(zap.String("request_id", requestID))
+	}
+	return zap.L()
+}
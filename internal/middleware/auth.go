@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticator validates a bearer token and returns the principal it belongs to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (models.Principal, error)
+}
+
+// APIKeyHeader is the header partners may send their API key in instead of
+// "Authorization: Bearer".
+const APIKeyHeader = "X-API-Key"
+
+// Auth returns an Echo middleware that rejects requests without valid
+// credentials and places the authenticated principal on the request context.
+// Access tokens are checked by users; API keys, sent either as a bearer token
+// or in the X-API-Key header, are checked by apiKeys.
+func Auth(users Authenticator, apiKeys Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authenticator := users
+			token, ok := bearerToken(c.Request())
+			if key := c.Request().Header.Get(APIKeyHeader); key != "" {
+				token, ok = key, true
+				authenticator = apiKeys
+			} else if strings.HasPrefix(token, models.APIKeyPrefix) {
+				authenticator = apiKeys
+			}
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return handlers.SendError(c, http.StatusUnauthorized, "unauthorized")
+			}
+
+			principal, err := authenticator.Authenticate(c.Request().Context(), token)
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return handlers.SendError(c, http.StatusUnauthorized, "unauthorized")
+			}
+
+			ctx := requestctx.WithPrincipal(c.Request().Context(), principal)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get(echo.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
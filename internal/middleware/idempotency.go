@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Idempotency headers. Clients send HeaderIdempotencyKey; replayed responses
+// carry HeaderIdempotentReplayed.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKey is the longest idempotency key accepted.
+const maxIdempotencyKey = 255
+
+// maxIdempotentBody caps the size of a request body sent with an idempotency
+// key, which is read into memory to be hashed. It leaves room for the largest
+// CSV import.
+const maxIdempotentBody = 16 << 20
+
+// replayedHeaders lists the response headers recorded to be replayed.
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag", echo.HeaderLastModified}
+
+// IdempotencyStore remembers idempotency keys and the responses to them.
+type IdempotencyStore interface {
+	Claim(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record models.IdempotencyRecord) error
+	Release(ctx context.Context, scope string, key string) error
+}
+
+// Idempotency returns an Echo middleware that makes POST requests sent with an
+// Idempotency-Key header safe to retry. The first request with a key runs and
+// its response is recorded; retries get that response again instead of
+// running twice. Reusing a key with a different request is refused with 422,
+// and retrying while the first request is still running with 409; a retry
+// after models.IdempotencyLease runs the request again, in case the first one
+// died with its instance. Keys belong to the principal authenticated by an
+// earlier middleware and expire after models.IdempotencyTTL. Requests that fail
+// with a server error release their key so that they can be retried.
+func Idempotency(store IdempotencyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			principal, authenticated := requestctx.Principal(c.Request().Context())
+			if c.Request().Method != http.MethodPost || key == "" || !authenticated {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKey {
+				return handlers.SendProblem(c, http.StatusBadRequest, handlers.ProblemValidation, "invalid idempotency key")
+			}
+
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIdempotentBody+1))
+			if err != nil {
+				return handlers.SendError(c, http.StatusBadRequest, "invalid request")
+			}
+			if len(body) > maxIdempotentBody {
+				return handlers.SendError(c, http.StatusRequestEntityTooLarge, "request too large")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			hash := requestHash(c.Request(), body)
+			record, claimed, err := store.Claim(ctx, models.IdempotencyRecord{
+				Scope:       principal.TenantID.String() + ":" + principal.Actor(),
+				Key:         key,
+				RequestHash: hash,
+				CreatedAt:   time.Now().UTC(),
+			})
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to claim idempotency key", zap.Error(err))
+				return handlers.SendError(c, http.StatusInternalServerError, "internal server error")
+			}
+			if !claimed {
+				return replay(c, record, hash)
+			}
+
+			// Record the response whatever happens to the client's connection
+			ctx = context.WithoutCancel(ctx)
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil || c.Response().Status >= http.StatusInternalServerError {
+				_ = store.Release(ctx, record.Scope, record.Key)
+				return err
+			}
+
+			status := c.Response().Status
+			headers := http.Header{}
+			for _, name := range replayedHeaders {
+				if value := c.Response().Header().Get(name); value != "" {
+					headers.Set(name, value)
+				}
+			}
+			record.Status, record.Body = &status, recorder.body.Bytes()
+			if record.Headers, err = json.Marshal(headers); err == nil {
+				err = store.Complete(ctx, record)
+			}
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to record idempotent response", zap.Error(err))
+				_ = store.Release(ctx, record.Scope, record.Key)
+			}
+			return nil
+		}
+	}
+}
+
+// replay answers a retry with the response recorded for its key.
+func replay(c echo.Context, record models.IdempotencyRecord, hash string) error {
+	if record.RequestHash != hash {
+		return handlers.SendProblem(c, http.StatusUnprocessableEntity, handlers.ProblemIdempotencyKeyReused, "idempotency key reused with a different request")
+	}
+	if !record.Completed() {
+		return handlers.SendError(c, http.StatusConflict, "request with this idempotency key is in progress")
+	}
+
+	var headers http.Header
+	if err := json.Unmarshal(record.Headers, &headers); err != nil {
+		return handlers.SendError(c, http.StatusInternalServerError, "internal server error")
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			c.Response().Header().Add(name, value)
+		}
+	}
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	c.Response().WriteHeader(*record.Status)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// requestHash identifies a request by its method, target and body.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes b to the response and keeps a copy.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
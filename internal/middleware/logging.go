@@ -3,18 +3,25 @@ package middleware
 import (
 	"time"
 
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
 
-// Logging returns an Echo middleware that logs HTTP requests.
+// Logging returns an Echo middleware that logs HTTP requests. Each line is
+// tagged with the request ID, and so is every line logged by the services
+// through logging.FromContext while handling the request.
 func Logging(logger *zap.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
+			requestLogger := logger.With(zap.String("request_id", requestctx.RequestID(c.Request().Context())))
+			c.SetRequest(c.Request().WithContext(logging.WithLogger(c.Request().Context(), requestLogger)))
+
 			err := next(c)
 			duration := time.Since(start)
-			logger.Info("request",
+			requestLogger.Info("request",
 				zap.String("method", c.Request().Method),
 				zap.String("path", c.Request().URL.Path),
 				zap.Int("status", c.Response().Status),
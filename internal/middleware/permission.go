@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePermission returns an Echo middleware that rejects requests whose
+// principal's role does not grant the permission. It must run after Auth.
+func RequirePermission(permission models.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := requestctx.Principal(c.Request().Context())
+			if !ok {
+				return handlers.SendError(c, http.StatusUnauthorized, "unauthorized")
+			}
+			if !principal.Can(permission) {
+				return handlers.SendError(c, http.StatusForbidden, "forbidden")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+// PublicPrefix is the path the public listings API is mounted under.
+const PublicPrefix = "/public/v1"
+
+// OrganizationResolver finds the organization a public request is for.
+type OrganizationResolver interface {
+	GetOrganizationBySlug(ctx context.Context, slug string) (models.Organization, error)
+}
+
+// isPublic reports whether the request is for the public API.
+func isPublic(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().URL.Path, PublicPrefix+"/")
+}
+
+// CORS returns the server's CORS middleware. The back office API answers any
+// origin since every request to it carries credentials; the public API only
+// answers publicOrigins, and no other site at all when there are none. It has
+// to run on the whole server, before routing, to answer preflight requests.
+func CORS(publicOrigins []string) echo.MiddlewareFunc {
+	backOffice := echomw.CORSWithConfig(echomw.CORSConfig{Skipper: isPublic, ExposeHeaders: []string{"Link"}})
+	public := echomw.CORSWithConfig(echomw.CORSConfig{
+		AllowOrigins: publicOrigins,
+		AllowMethods: []string{http.MethodGet, http.MethodHead},
+		MaxAge:       int((24 * time.Hour).Seconds()),
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		backOfficeNext, publicNext := backOffice(next), public(next)
+		return func(c echo.Context) error {
+			if !isPublic(c) {
+				return backOfficeNext(c)
+			}
+			if len(publicOrigins) == 0 {
+				return next(c)
+			}
+			return publicNext(c)
+		}
+	}
+}
+
+// PublicTenant returns an Echo middleware that scopes a public request to the
+// organization named by the :organization path parameter, standing in for the
+// tenant an authenticated principal would bring.
+func PublicTenant(organizations OrganizationResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			organization, err := organizations.GetOrganizationBySlug(c.Request().Context(), c.Param("organization"))
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return handlers.SendError(c, http.StatusNotFound, "not found")
+			}
+			if err != nil {
+				return handlers.SendError(c, http.StatusInternalServerError, "internal server error")
+			}
+
+			ctx := requestctx.WithTenant(c.Request().Context(), organization.ID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// CacheControl returns an Echo middleware that lets browsers and CDNs cache
+// successful responses for maxAge, and serve them stale for as long again
+// while they revalidate. Errors are never cached.
+func CacheControl(maxAge time.Duration) echo.MiddlewareFunc {
+	seconds := int(maxAge.Seconds())
+	cacheable := fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", seconds, seconds)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Before(func() {
+				if status := c.Response().Status; status < http.StatusMultipleChoices || status == http.StatusNotModified {
+					c.Response().Header().Set(echo.HeaderCacheControl, cacheable)
+				} else {
+					c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+				}
+			})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/handlers"
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Rate limit response headers, from the IETF RateLimit header fields draft.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// RateLimitStore keeps the token buckets of rate limited clients.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, quota models.RateLimitQuota) (models.RateLimitStatus, error)
+}
+
+// RateLimitConfig configures RateLimit.
+type RateLimitConfig struct {
+	// Name sets the limiter's buckets apart from those of other limiters
+	// sharing the store.
+	Name  string
+	Store RateLimitStore
+	// Quota applies to every route without an override in Routes.
+	Quota models.RateLimitQuota
+	// Routes overrides Quota for routes, keyed by method and route path as
+	// in "GET /api/v1/buildings". Each of them is counted on its own.
+	Routes map[string]models.RateLimitQuota
+}
+
+// RateLimit returns an Echo middleware that limits each client to its quota
+// and rejects the requests over it with 429. A client is the API key or user
+// authenticated by an earlier middleware, or else the client IP. Every response
+// carries the client's RateLimit-* headers and a 429 carries Retry-After. When
+// the store fails the request is let through, so that the limiter never takes
+// the API down with it.
+func RateLimit(config RateLimitConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			quota, key := config.Quota, config.Name+":"+rateLimitClient(c)
+			route := c.Request().Method + " " + c.Path()
+			if override, ok := config.Routes[route]; ok {
+				quota, key = override, key+":"+route
+			}
+
+			status, err := config.Store.Take(c.Request().Context(), key, quota)
+			if err != nil {
+				logging.FromContext(c.Request().Context()).Error("Failed to take rate limit token", zap.Error(err))
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(status.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(status.Remaining))
+			header.Set(HeaderRateLimitReset, seconds(status.Reset))
+			if !status.Allowed {
+				header.Set(echo.HeaderRetryAfter, seconds(status.RetryAfter))
+				return handlers.SendError(c, http.StatusTooManyRequests, "too many requests")
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitClient identifies the client a request is counted against.
+func rateLimitClient(c echo.Context) string {
+	principal, ok := requestctx.Principal(c.Request().Context())
+	switch {
+	case ok && principal.APIKeyID != uuid.Nil:
+		return "api_key:" + principal.APIKeyID.String()
+	case ok:
+		return "user:" + principal.UserID.String()
+	default:
+		return "ip:" + c.RealIP()
+	}
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// ParseRateLimitRoutes parses per-route quotas written as comma-separated
+// "METHOD /route/path=rate:burst" entries, such as
+// "GET /api/v1/buildings=2:10,POST /api/v1/imports=0.1:1".
+func ParseRateLimitRoutes(spec string) (map[string]models.RateLimitQuota, error) {
+	routes := make(map[string]models.RateLimitQuota)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, limit, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		rate, burst, hasBurst := strings.Cut(limit, ":")
+		if !ok || !hasPath || !hasBurst {
+			return nil, fmt.Errorf("invalid rate limit route %q", entry)
+		}
+
+		var quota models.RateLimitQuota
+		var err error
+		if quota.Rate, err = strconv.ParseFloat(rate, 64); err != nil {
+			return nil, fmt.Errorf("invalid rate limit route %q: %w", entry, err)
+		}
+		if quota.Burst, err = strconv.Atoi(burst); err != nil {
+			return nil, fmt.Errorf("invalid rate limit route %q: %w", entry, err)
+		}
+		if err := quota.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid rate limit route %q: %w", entry, err)
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = quota
+	}
+	return routes, nil
+}
+
+// memoryRateLimitStore implements RateLimitStore in process memory.
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is a bucket of memoryRateLimitStore.
+type tokenBucket struct {
+	quota     models.RateLimitQuota
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryRateLimitStore creates a rate limit store that keeps its buckets in
+// memory, so that each server instance counts on its own.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*tokenBucket), lastSweep: time.Now()}
+}
+
+// Take refills the bucket named key and takes a token from it if one is left.
+// A new bucket starts full.
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, quota models.RateLimitQuota) (models.RateLimitStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(quota.Burst)}
+		s.buckets[key] = bucket
+	} else {
+		bucket.tokens = quota.Refill(bucket.tokens, now.Sub(bucket.updatedAt))
+	}
+	bucket.quota, bucket.updatedAt = quota, now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	return models.NewRateLimitStatus(quota, bucket.tokens, allowed), nil
+}
+
+// sweep drops the buckets that have refilled, at most once a minute; a full
+// bucket counts the same as a missing one.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if bucket.quota.Refill(bucket.tokens, now.Sub(bucket.updatedAt)) >= float64(bucket.quota.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// validRequestID matches the client-supplied request IDs that are kept. Others
+// are replaced so that nothing unexpected ends up in logs and headers.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID returns an Echo middleware that gives every request an ID: the
+// X-Request-ID sent by the client if it is valid, or a new UUID. The ID is sent
+// back in X-Request-ID and stored on the request context, from where the
+// logging and tracing middlewares, error responses and the audit log pick it up.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID.MatchString(requestID) {
+				requestID = uuid.NewString()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			ctx := requestctx.WithRequestID(c.Request().Context(), requestID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -2,17 +2,21 @@
 package middleware
 
 import (
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
 	"github.com/labstack/echo/v4"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Tracing returns an Echo middleware that adds OpenTelemetry tracing to requests.
+// Tracing returns an Echo middleware that adds OpenTelemetry tracing to
+// requests. Spans carry the request ID so a trace can be found from it.
 func Tracing() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			tracer := otel.Tracer("echo-server")
 			ctx, span := tracer.Start(c.Request().Context(), c.Request().Method+" "+c.Request().URL.Path)
 			defer span.End()
+			span.SetAttributes(attribute.String("request.id", requestctx.RequestID(ctx)))
 
 			c.SetRequest(c.Request().WithContext(ctx))
 			return next(c)
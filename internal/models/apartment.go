@@ -6,6 +6,7 @@ import (
 
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ApartmentType represents the type of apartment (e.g., Studio, OneBed).
@@ -19,25 +20,48 @@ const (
 	ThreeOrMoreBeds ApartmentType = "ThreeOrMoreBeds"
 )
 
+// ApartmentTypes lists every apartment type.
+var ApartmentTypes = []ApartmentType{Studio, OneBed, TwoBeds, ThreeOrMoreBeds}
+
 // String returns the string representation of ApartmentType
 func (a ApartmentType) String() string {
 	return string(a)
 }
 
-// Apartment represents an apartment listing.
+// IsValid reports whether a is a known apartment type.
+func (a ApartmentType) IsValid() bool {
+	for _, t := range ApartmentTypes {
+		if t == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Apartment represents an apartment listing. Only available apartments are
+// listed publicly.
 type Apartment struct {
-	ID               uuid.UUID     `json:"id"`
-	BuildingID       uuid.UUID     `json:"building_id"`
-	Type             ApartmentType `json:"type"`
-	Price            PriceRange    `json:"price"`
-	PromotionalPrice *int64        `json:"promotional_price,omitempty"`
-	Images           []string      `json:"images"`
-	Videos           []string      `json:"videos"`
-	LastUpdate       time.Time     `json:"last_update"`
+	ID               uuid.UUID      `json:"id" db:"id"`
+	BuildingID       uuid.UUID      `json:"building_id" db:"building_id"`
+	Type             ApartmentType  `json:"type" db:"type"`
+	Price            PriceRange     `json:"price" db:"price"`
+	PromotionalPrice *int64         `json:"promotional_price,omitempty" db:"promotional_price"`
+	Images           pq.StringArray `json:"images" db:"images"`
+	Videos           pq.StringArray `json:"videos" db:"videos"`
+	LastUpdate       time.Time      `json:"last_update" db:"last_update"`
+	Available        bool           `json:"available" db:"available"`
+	Version          int64          `json:"version" db:"version"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
 }
 
 // NewApartment creates a new Apartment with validation.
 func NewApartment(id, buildingID uuid.UUID, aptType ApartmentType, price PriceRange, promoPrice *int64, images, videos []string, lastUpdate time.Time) (Apartment, error) {
+	if images == nil {
+		images = []string{}
+	}
+	if videos == nil {
+		videos = []string{}
+	}
 	a := Apartment{
 		ID:               id,
 		BuildingID:       buildingID,
@@ -47,24 +71,20 @@ func NewApartment(id, buildingID uuid.UUID, aptType ApartmentType, price PriceRa
 		Images:           images,
 		Videos:           videos,
 		LastUpdate:       lastUpdate,
+		Version:          1,
+		UpdatedAt:        lastUpdate,
 	}
 	return a, a.Validate()
 }
 
-// Validate checks if the apartment is valid.
+// Validate checks if the apartment is valid. The price range is reported as
+// the price_from and price_to fields that clients send.
 func (a Apartment) Validate() error {
-	if a.ID == uuid.Nil {
-		return apperrors.ErrInvalidApartment
-	}
-	if a.BuildingID == uuid.Nil {
-		return apperrors.ErrInvalidApartment
-	}
-	if err := a.Price.Validate(); err != nil {
-		return err
-	}
-	if a.PromotionalPrice != nil && *a.PromotionalPrice < 0 {
-		return apperrors.ErrInvalidApartment
-	}
-	// Valid ApartmentType is enforced by type, but could add check
-	return nil
+	v := newValidation(apperrors.ErrInvalidApartment)
+	v.check(a.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(a.BuildingID != uuid.Nil, "building_id", apperrors.CodeRequired, "must not be empty")
+	v.check(a.Type.IsValid(), "type", apperrors.CodeInvalid, "must be a known apartment type")
+	v.nest("price_", a.Price.Validate())
+	v.check(a.PromotionalPrice == nil || *a.PromotionalPrice >= 0, "promotional_price", apperrors.CodeOutOfRange, "must not be negative")
+	return v.err()
 }
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKeyPrefix starts every API key, telling keys apart from access tokens.
+const APIKeyPrefix = "brk_"
+
+// APIKeyScope is a set of permissions that can be granted to an API key.
+type APIKeyScope string
+
+// API key scope constants
+const (
+	ScopeListingsRead   APIKeyScope = "listings:read"
+	ScopeInquiriesWrite APIKeyScope = "inquiries:write"
+)
+
+// scopePermissions maps each scope to the permissions it grants.
+var scopePermissions = map[APIKeyScope][]Permission{
+	ScopeListingsRead:   {PermNeighborhoodsRead, PermBuildingsRead, PermApartmentsRead},
+	ScopeInquiriesWrite: {PermInquiriesWrite},
+}
+
+// APIKeyScopes lists every scope.
+var APIKeyScopes = []APIKeyScope{ScopeListingsRead, ScopeInquiriesWrite}
+
+// IsValid reports whether s is a known scope.
+func (s APIKeyScope) IsValid() bool {
+	_, ok := scopePermissions[s]
+	return ok
+}
+
+// Grants reports whether s grants the permission.
+func (s APIKeyScope) Grants(permission Permission) bool {
+	return slices.Contains(scopePermissions[s], permission)
+}
+
+// APIKey is a long-lived credential for partner integrations. Only a hash of
+// the key is stored; the key itself is shown once when it is issued.
+type APIKey struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	TenantID   uuid.UUID      `json:"tenant_id" db:"tenant_id"`
+	Name       string         `json:"name" db:"name"`
+	Prefix     string         `json:"prefix" db:"prefix"`
+	KeyHash    string         `json:"-" db:"key_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	CreatedBy  *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NewAPIKey creates a new APIKey instance with validation.
+func NewAPIKey(id uuid.UUID, tenantID uuid.UUID, name string, prefix string, keyHash string, scopes []string, createdBy *uuid.UUID, expiresAt *time.Time, createdAt time.Time) (APIKey, error) {
+	k := APIKey{
+		ID:        id,
+		TenantID:  tenantID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+		CreatedAt: createdAt,
+	}
+	return k, k.Validate()
+}
+
+// Validate checks if the API key is valid.
+func (k APIKey) Validate() error {
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(k.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(k.TenantID != uuid.Nil, "tenant_id", apperrors.CodeRequired, "must not be empty")
+	v.check(k.Name != "", "name", apperrors.CodeRequired, "must not be empty")
+	v.check(k.Prefix != "" && k.KeyHash != "", "key", apperrors.CodeRequired, "must not be empty")
+	v.check(len(k.Scopes) > 0, "scopes", apperrors.CodeRequired, "must not be empty")
+	for i, scope := range k.Scopes {
+		v.check(APIKeyScope(scope).IsValid(), fmt.Sprintf("scopes[%d]", i), apperrors.CodeInvalid, "must be a known scope")
+	}
+	v.check(k.ExpiresAt == nil || k.ExpiresAt.After(k.CreatedAt), "expires_at", apperrors.CodeOutOfRange, "must be in the future")
+	return v.err()
+}
+
+// IsActive reports whether the key can be used at the given time.
+func (k APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction represents the kind of mutation an audit entry records.
+type AuditAction string
+
+// Audit action constants
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// Audited entity type constants
+const (
+	EntityNeighborhood        = "neighborhood"
+	EntityBuilding            = "building"
+	EntityApartment           = "apartment"
+	EntityWebhookSubscription = "webhook_subscription"
+	EntityWebhookDelivery     = "webhook_delivery"
+	EntityUser                = "user"
+	EntityAPIKey              = "api_key"
+	EntityInquiry             = "inquiry"
+	EntityOrganization        = "organization"
+)
+
+// AuditEntityTypes lists the entity types that are audited.
+var AuditEntityTypes = []string{
+	EntityNeighborhood,
+	EntityBuilding,
+	EntityApartment,
+	EntityWebhookSubscription,
+	EntityWebhookDelivery,
+	EntityUser,
+	EntityAPIKey,
+	EntityInquiry,
+	EntityOrganization,
+}
+
+// FieldChange holds the before and after value of one changed field.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditEntry records who changed which entity, when, and how.
+type AuditEntry struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	Actor      string          `json:"actor" db:"actor"`
+	RequestID  *string         `json:"request_id,omitempty" db:"request_id"`
+	Action     AuditAction     `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	Diff       json.RawMessage `json:"diff" db:"diff"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// NewAuditEntry creates an AuditEntry whose diff compares before and after.
+// Pass nil as before for creations and as after for deletions.
+func NewAuditEntry(id uuid.UUID, actor string, requestID string, action AuditAction, entityType string, entityID uuid.UUID, before, after any, createdAt time.Time) (AuditEntry, error) {
+	diff, err := DiffJSON(before, after)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+
+	entry := AuditEntry{
+		ID:         id,
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Diff:       diff,
+		CreatedAt:  createdAt,
+	}
+	if requestID != "" {
+		entry.RequestID = &requestID
+	}
+	return entry, nil
+}
+
+// DiffJSON compares the top-level JSON fields of before and after and returns
+// an object mapping every changed field to a FieldChange.
+func DiffJSON(before, after any) (json.RawMessage, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, b := range beforeFields {
+		if a := afterFields[key]; !bytes.Equal(b, a) {
+			changes[key] = FieldChange{Before: b, After: orNull(a)}
+		}
+	}
+	for key, a := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = FieldChange{Before: orNull(nil), After: a}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// jsonFields marshals v and splits the resulting object into its fields.
+func jsonFields(v any) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if v == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// orNull returns a JSON null for a missing value.
+func orNull(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return v
+}
@@ -1,16 +1,24 @@
 package models
 
 import (
+	"fmt"
+	"time"
+
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/google/uuid"
 )
 
+// MaxAddressLength caps the length of a building address.
+const MaxAddressLength = 300
+
 // Building represents a building in a neighborhood.
 type Building struct {
 	ID             uuid.UUID `json:"id" db:"id"`
 	Name           string    `json:"name" db:"name"`
 	NeighborhoodID uuid.UUID `json:"neighborhood_id" db:"neighborhood_id"`
 	Address        string    `json:"address" db:"address"`
+	Version        int64     `json:"version" db:"version"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // NewBuilding creates a new Building instance with validation.
@@ -20,23 +28,19 @@ func NewBuilding(id uuid.UUID, name string, neighborhoodID uuid.UUID, address st
 		Name:           name,
 		NeighborhoodID: neighborhoodID,
 		Address:        address,
+		Version:        1,
 	}
 	return b, b.Validate()
 }
 
 // Validate checks if the building is valid.
 func (b Building) Validate() error {
-	if b.ID == uuid.Nil {
-		return apperrors.ErrInvalidInput
-	}
-	if b.Name == "" {
-		return apperrors.ErrInvalidInput
-	}
-	if b.NeighborhoodID == uuid.Nil {
-		return apperrors.ErrInvalidInput
-	}
-	if b.Address == "" {
-		return apperrors.ErrInvalidInput
-	}
-	return nil
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(b.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(b.Name != "", "name", apperrors.CodeRequired, "must not be empty")
+	v.check(len(b.Name) <= MaxNameLength, "name", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	v.check(b.NeighborhoodID != uuid.Nil, "neighborhood_id", apperrors.CodeRequired, "must not be empty")
+	v.check(b.Address != "", "address", apperrors.CodeRequired, "must not be empty")
+	v.check(len(b.Address) <= MaxAddressLength, "address", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxAddressLength))
+	return v.err()
 }
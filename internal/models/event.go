@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies a kind of domain event.
+type EventType string
+
+// Domain event type constants
+const (
+	EventNeighborhoodCreated  EventType = "neighborhood.created"
+	EventNeighborhoodUpdated  EventType = "neighborhood.updated"
+	EventNeighborhoodDeleted  EventType = "neighborhood.deleted"
+	EventNeighborhoodRestored EventType = "neighborhood.restored"
+	EventBuildingCreated      EventType = "building.created"
+	EventBuildingUpdated      EventType = "building.updated"
+	EventBuildingDeleted      EventType = "building.deleted"
+	EventBuildingRestored     EventType = "building.restored"
+	EventApartmentCreated     EventType = "apartment.created"
+	EventApartmentUpdated     EventType = "apartment.updated"
+	EventApartmentDeleted     EventType = "apartment.deleted"
+	EventApartmentRestored    EventType = "apartment.restored"
+)
+
+// EventTypes lists every domain event type we emit.
+var EventTypes = []EventType{
+	EventNeighborhoodCreated,
+	EventNeighborhoodUpdated,
+	EventNeighborhoodDeleted,
+	EventNeighborhoodRestored,
+	EventBuildingCreated,
+	EventBuildingUpdated,
+	EventBuildingDeleted,
+	EventBuildingRestored,
+	EventApartmentCreated,
+	EventApartmentUpdated,
+	EventApartmentDeleted,
+	EventApartmentRestored,
+}
+
+// String returns the string representation of EventType
+func (e EventType) String() string {
+	return string(e)
+}
+
+// IsValid reports whether the event type is one we emit.
+func (e EventType) IsValid() bool {
+	for _, t := range EventTypes {
+		if t == e {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateType returns the entity kind the event is about (e.g. "building").
+func (e EventType) AggregateType() string {
+	aggregate, _, _ := strings.Cut(string(e), ".")
+	return aggregate
+}
+
+// DomainEvent represents a change to an aggregate, stored in the outbox
+// alongside the change itself.
+type DomainEvent struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	TenantID      uuid.UUID       `json:"tenant_id" db:"tenant_id"`
+	Sequence      int64           `json:"sequence" db:"seq"`
+	Type          EventType       `json:"type" db:"event_type"`
+	AggregateType string          `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at" db:"occurred_at"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	ParkedAt      *time.Time      `json:"parked_at,omitempty" db:"parked_at"`
+}
+
+// NewDomainEvent creates a new DomainEvent with data marshaled as its payload.
+func NewDomainEvent(id uuid.UUID, tenantID uuid.UUID, eventType EventType, aggregateID uuid.UUID, data any, occurredAt time.Time) (DomainEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+	return DomainEvent{
+		ID:            id,
+		TenantID:      tenantID,
+		Type:          eventType,
+		AggregateType: eventType.AggregateType(),
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		OccurredAt:    occurredAt,
+	}, nil
+}
@@ -0,0 +1,15 @@
+package models
+
+// BuildingExport is a building with the name of its neighborhood, as exported.
+type BuildingExport struct {
+	Building
+	Neighborhood string `json:"neighborhood" db:"neighborhood"`
+}
+
+// ApartmentExport is an apartment with the names of its building and
+// neighborhood, as exported.
+type ApartmentExport struct {
+	Apartment
+	Neighborhood string `json:"neighborhood" db:"neighborhood"`
+	Building     string `json:"building" db:"building"`
+}
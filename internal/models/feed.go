@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// FeedListing is an apartment as syndicated to listing portals, with the
+// building and neighborhood it is in.
+type FeedListing struct {
+	Apartment
+	Building       string    `json:"building" db:"building"`
+	Address        string    `json:"address" db:"address"`
+	NeighborhoodID uuid.UUID `json:"neighborhood_id" db:"neighborhood_id"`
+	Neighborhood   string    `json:"neighborhood" db:"neighborhood"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyTTL is how long an idempotency key is remembered. A key reused
+// after that starts a new request.
+const IdempotencyTTL = 24 * time.Hour
+
+// IdempotencyLease is how long a request holds its idempotency key before
+// completing. A retry after that, with the same request, runs it again, so
+// that a key whose request was cut short by a crash is not held until it
+// expires. It outlasts the longest request the API serves.
+const IdempotencyLease = 5 * time.Minute
+
+// IdempotencyRecord remembers the request a client made with an idempotency
+// key and, once it has completed, the response to replay to its retries.
+type IdempotencyRecord struct {
+	Scope       string          `db:"scope"`
+	Key         string          `db:"key"`
+	RequestHash string          `db:"request_hash"`
+	Status      *int            `db:"status"`
+	Headers     json.RawMessage `db:"headers"`
+	Body        []byte          `db:"body"`
+	CreatedAt   time.Time       `db:"created_at"`
+}
+
+// Completed reports whether the response to the request has been recorded.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ImportEntity is the kind of listing a CSV import creates or updates.
+type ImportEntity string
+
+// Import entity constants
+const (
+	ImportNeighborhoods ImportEntity = "neighborhoods"
+	ImportBuildings     ImportEntity = "buildings"
+	ImportApartments    ImportEntity = "apartments"
+)
+
+// IsValid reports whether e is a known import entity.
+func (e ImportEntity) IsValid() bool {
+	return e == ImportNeighborhoods || e == ImportBuildings || e == ImportApartments
+}
+
+// ImportStatus represents the outcome of an import.
+type ImportStatus string
+
+// Import status constants
+const (
+	// ImportValidated is a dry run whose rows are all valid.
+	ImportValidated ImportStatus = "validated"
+	// ImportCompleted is an import whose rows were all written.
+	ImportCompleted ImportStatus = "completed"
+	// ImportFailed is an import with invalid rows; nothing was written.
+	ImportFailed ImportStatus = "failed"
+)
+
+// ImportAction is what an import does, or would do, with a valid row.
+type ImportAction string
+
+// Import action constants
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+)
+
+// Import records a CSV import and how many of its rows were created, updated,
+// left unchanged or rejected. Header is the header row of the uploaded file.
+type Import struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	Entity    ImportEntity   `json:"entity" db:"entity"`
+	DryRun    bool           `json:"dry_run" db:"dry_run"`
+	Status    ImportStatus   `json:"status" db:"status"`
+	Rows      int            `json:"rows" db:"total_rows"`
+	Created   int            `json:"created" db:"created"`
+	Updated   int            `json:"updated" db:"updated"`
+	Unchanged int            `json:"unchanged" db:"unchanged"`
+	Failed    int            `json:"failed" db:"failed"`
+	Header    pq.StringArray `json:"-" db:"header"`
+	Actor     string         `json:"actor" db:"actor"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+// ImportRow reports on one row of an import: the action taken, or that would
+// be taken in a dry run, or why the row was rejected. Line is the line of the
+// file the row starts on; ID is the listing the row created or matched.
+type ImportRow struct {
+	Line   int          `json:"line"`
+	Action ImportAction `json:"action,omitempty"`
+	ID     *uuid.UUID   `json:"id,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// ImportError is a rejected row of an import, kept as it was uploaded so it can
+// be downloaded, fixed and imported again.
+type ImportError struct {
+	Line  int            `json:"line" db:"line"`
+	Cells pq.StringArray `json:"cells" db:"cells"`
+	Error string         `json:"error" db:"error"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+)
+
+// MaxInquiryMessageLength caps the length of an inquiry message.
+const MaxInquiryMessageLength = 5000
+
+// Inquiry is a prospective tenant's request for information about a building.
+type Inquiry struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	BuildingID uuid.UUID `json:"building_id" db:"building_id"`
+	Name       string    `json:"name" db:"name"`
+	Email      string    `json:"email" db:"email"`
+	Phone      string    `json:"phone,omitempty" db:"phone"`
+	Message    string    `json:"message" db:"message"`
+	Source     string    `json:"source" db:"source"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewInquiry creates a new Inquiry instance with validation.
+func NewInquiry(id uuid.UUID, buildingID uuid.UUID, name string, email string, phone string, message string, source string, createdAt time.Time) (Inquiry, error) {
+	i := Inquiry{
+		ID:         id,
+		BuildingID: buildingID,
+		Name:       strings.TrimSpace(name),
+		Email:      strings.TrimSpace(email),
+		Phone:      strings.TrimSpace(phone),
+		Message:    strings.TrimSpace(message),
+		Source:     source,
+		CreatedAt:  createdAt,
+	}
+	return i, i.Validate()
+}
+
+// Validate checks if the inquiry is valid.
+func (i Inquiry) Validate() error {
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(i.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(i.BuildingID != uuid.Nil, "building_id", apperrors.CodeRequired, "must not be empty")
+	v.check(i.Name != "", "name", apperrors.CodeRequired, "must not be empty")
+	_, err := mail.ParseAddress(i.Email)
+	v.check(err == nil, "email", apperrors.CodeInvalid, "must be a valid email address")
+	v.check(i.Message != "", "message", apperrors.CodeRequired, "must not be empty")
+	v.check(len(i.Message) <= MaxInquiryMessageLength, "message", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxInquiryMessageLength))
+	return v.err()
+}
@@ -1,29 +1,42 @@
 package models
 
 import (
+	"fmt"
+	"time"
+
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/google/uuid"
 )
 
+// MaxNameLength caps the length of a neighborhood or building name.
+const MaxNameLength = 200
+
 // Neighborhood represents a neighborhood location.
 type Neighborhood struct {
-	ID   uuid.UUID `json:"id" db:"id"`
-	Name string    `json:"name" db:"name"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Version   int64     `json:"version" db:"version"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // NewNeighborhood creates a new Neighborhood instance with validation.
 func NewNeighborhood(id uuid.UUID, name string) (Neighborhood, error) {
-	n := Neighborhood{ID: id, Name: name}
+	n := Neighborhood{ID: id, Name: name, Version: 1}
 	return n, n.Validate()
 }
 
 // Validate checks if the neighborhood is valid.
 func (n Neighborhood) Validate() error {
-	if n.ID == uuid.Nil {
-		return apperrors.ErrInvalidInput
-	}
-	if n.Name == "" {
-		return apperrors.ErrInvalidInput
-	}
-	return nil
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(n.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(n.Name != "", "name", apperrors.CodeRequired, "must not be empty")
+	v.check(len(n.Name) <= MaxNameLength, "name", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	return v.err()
+}
+
+// NeighborhoodDependents counts the buildings, and the apartments in them, that
+// deleting a neighborhood would take along.
+type NeighborhoodDependents struct {
+	Buildings  int `json:"buildings" db:"buildings"`
+	Apartments int `json:"apartments" db:"apartments"`
 }
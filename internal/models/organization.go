@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationID is the organization that owned all data before
+// multi-tenancy. It operates the platform and onboards the other organizations.
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// organizationSlugPattern matches lowercase, dash-separated slugs.
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// maxOrganizationSlugLength caps the length of an organization slug.
+const maxOrganizationSlugLength = 63
+
+// Organization is a tenant: an agency whose data is isolated from every other.
+type Organization struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Slug      string    `json:"slug" db:"slug"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewOrganization creates a new Organization instance with validation.
+func NewOrganization(id uuid.UUID, slug string, name string, createdAt time.Time) (Organization, error) {
+	o := Organization{ID: id, Slug: strings.TrimSpace(slug), Name: strings.TrimSpace(name), CreatedAt: createdAt}
+	return o, o.Validate()
+}
+
+// Validate checks if the organization is valid.
+func (o Organization) Validate() error {
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(o.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(o.Slug != "", "slug", apperrors.CodeRequired, "must not be empty")
+	v.check(o.Slug == "" || organizationSlugPattern.MatchString(o.Slug), "slug", apperrors.CodeInvalid, "must be lowercase letters and digits separated by dashes")
+	v.check(len(o.Slug) <= maxOrganizationSlugLength, "slug", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d characters", maxOrganizationSlugLength))
+	v.check(o.Name != "", "name", apperrors.CodeRequired, "must not be empty")
+	return v.err()
+}
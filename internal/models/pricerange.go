@@ -4,8 +4,8 @@ import apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 
 // PriceRange represents a range of prices with From and To values (in cents).
 type PriceRange struct {
-	From int64 `json:"from"`
-	To   int64 `json:"to"`
+	From int64 `json:"from" db:"from"`
+	To   int64 `json:"to" db:"to"`
 }
 
 // NewPriceRange creates a new PriceRange with validation.
@@ -16,11 +16,9 @@ func NewPriceRange(from, to int64) (PriceRange, error) {
 
 // Validate checks if the price range is valid (From < To and non-negative).
 func (p PriceRange) Validate() error {
-	if p.From < 0 || p.To <= 0 {
-		return apperrors.ErrInvalidPriceRange
-	}
-	if p.From >= p.To {
-		return apperrors.ErrInvalidPriceRange
-	}
-	return nil
+	v := newValidation(apperrors.ErrInvalidPriceRange)
+	v.check(p.From >= 0, "from", apperrors.CodeOutOfRange, "must not be negative")
+	v.check(p.To > 0, "to", apperrors.CodeOutOfRange, "must be positive")
+	v.check(p.From < 0 || p.To <= 0 || p.From < p.To, "to", apperrors.CodeOutOfRange, "must be greater than the starting price")
+	return v.err()
 }
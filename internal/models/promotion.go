@@ -1,6 +1,10 @@
 package models
 
-import apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+import (
+	"fmt"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+)
 
 // Promotion represents a promotional offer for rentals.
 type Promotion struct {
@@ -16,16 +20,11 @@ func NewPromotion(monthsFree uint8, conditions []string) (Promotion, error) {
 
 // Validate checks if the promotion is valid.
 func (p Promotion) Validate() error {
-	if p.MonthsFree == 0 {
-		return apperrors.ErrInvalidPromotion
-	}
-	if len(p.Conditions) == 0 {
-		return apperrors.ErrInvalidPromotion
-	}
-	for _, cond := range p.Conditions {
-		if cond == "" {
-			return apperrors.ErrInvalidPromotion
-		}
+	v := newValidation(apperrors.ErrInvalidPromotion)
+	v.check(p.MonthsFree > 0, "months_free", apperrors.CodeOutOfRange, "must be at least 1")
+	v.check(len(p.Conditions) > 0, "conditions", apperrors.CodeRequired, "must not be empty")
+	for i, cond := range p.Conditions {
+		v.check(cond != "", fmt.Sprintf("conditions[%d]", i), apperrors.CodeRequired, "must not be empty")
 	}
-	return nil
+	return v.err()
 }
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+)
+
+// RateLimitQuota is a token bucket: a client may make Burst requests at once
+// and earns Rate more every second, up to Burst.
+type RateLimitQuota struct {
+	Rate  float64
+	Burst int
+}
+
+// Validate checks that the quota lets requests through.
+func (q RateLimitQuota) Validate() error {
+	if q.Rate <= 0 || q.Burst < 1 {
+		return apperrors.ErrInvalidInput
+	}
+	return nil
+}
+
+// Refill returns the tokens in a bucket that held tokens elapsed ago.
+func (q RateLimitQuota) Refill(tokens float64, elapsed time.Duration) float64 {
+	return math.Min(float64(q.Burst), tokens+elapsed.Seconds()*q.Rate)
+}
+
+// Until returns how long a bucket holding tokens takes to hold want.
+func (q RateLimitQuota) Until(tokens float64, want float64) time.Duration {
+	if tokens >= want {
+		return 0
+	}
+	return time.Duration((want - tokens) / q.Rate * float64(time.Second))
+}
+
+// RateLimitStatus is a client's bucket once a request has been counted.
+type RateLimitStatus struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// NewRateLimitStatus describes a bucket holding tokens after a request was
+// allowed or denied.
+func NewRateLimitStatus(quota RateLimitQuota, tokens float64, allowed bool) RateLimitStatus {
+	status := RateLimitStatus{
+		Allowed:   allowed,
+		Limit:     quota.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     quota.Until(tokens, float64(quota.Burst)),
+	}
+	if !allowed {
+		status.RetryAfter = quota.Until(tokens, 1)
+	}
+	return status
+}
@@ -0,0 +1,86 @@
+package models
+
+import "slices"
+
+// Role is the set of permissions granted to a user.
+type Role string
+
+// Role constants, from most to least privileged
+const (
+	RoleAdmin   Role = "admin"
+	RoleManager Role = "manager"
+	RoleAgent   Role = "agent"
+	RoleViewer  Role = "viewer"
+)
+
+// Roles lists every role.
+var Roles = []Role{RoleAdmin, RoleManager, RoleAgent, RoleViewer}
+
+// Permission is an action that can be granted to a role.
+type Permission string
+
+// Permission constants
+const (
+	PermNeighborhoodsRead   Permission = "neighborhoods:read"
+	PermNeighborhoodsWrite  Permission = "neighborhoods:write"
+	PermNeighborhoodsDelete Permission = "neighborhoods:delete"
+	PermBuildingsRead       Permission = "buildings:read"
+	PermBuildingsWrite      Permission = "buildings:write"
+	PermBuildingsDelete     Permission = "buildings:delete"
+	PermApartmentsRead      Permission = "apartments:read"
+	PermApartmentsWrite     Permission = "apartments:write"
+	PermApartmentsDelete    Permission = "apartments:delete"
+	PermInquiriesRead       Permission = "inquiries:read"
+	PermInquiriesWrite      Permission = "inquiries:write"
+	PermWebhooksManage      Permission = "webhooks:manage"
+	PermAuditRead           Permission = "audit:read"
+	PermUsersManage         Permission = "users:manage"
+	PermAPIKeysManage       Permission = "api_keys:manage"
+	PermOrganizationsManage Permission = "organizations:manage"
+)
+
+// rolePermissions maps each role to the permissions it grants.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermNeighborhoodsRead, PermNeighborhoodsWrite, PermNeighborhoodsDelete,
+		PermBuildingsRead, PermBuildingsWrite, PermBuildingsDelete,
+		PermApartmentsRead, PermApartmentsWrite, PermApartmentsDelete,
+		PermInquiriesRead, PermInquiriesWrite,
+		PermWebhooksManage, PermAuditRead, PermUsersManage, PermAPIKeysManage,
+		PermOrganizationsManage,
+	},
+	RoleManager: {
+		PermNeighborhoodsRead, PermNeighborhoodsWrite, PermNeighborhoodsDelete,
+		PermBuildingsRead, PermBuildingsWrite, PermBuildingsDelete,
+		PermApartmentsRead, PermApartmentsWrite, PermApartmentsDelete,
+		PermInquiriesRead, PermInquiriesWrite,
+		PermWebhooksManage, PermAuditRead,
+	},
+	RoleAgent: {
+		PermNeighborhoodsRead, PermNeighborhoodsWrite,
+		PermBuildingsRead, PermBuildingsWrite,
+		PermApartmentsRead, PermApartmentsWrite,
+		PermInquiriesRead, PermInquiriesWrite,
+	},
+	RoleViewer: {
+		PermNeighborhoodsRead,
+		PermBuildingsRead,
+		PermApartmentsRead,
+	},
+}
+
+// IsValid reports whether r is a known role.
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted to r.
+func (r Role) Permissions() []Permission {
+	return slices.Clone(rolePermissions[r])
+}
+
+// Can reports whether r grants the permission.
+func (r Role) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "github.com/google/uuid"
+
+// SearchResultType is the kind of record a search result points to.
+type SearchResultType string
+
+// Search result types
+const (
+	SearchNeighborhood SearchResultType = "neighborhood"
+	SearchBuilding     SearchResultType = "building"
+	SearchAddress      SearchResultType = "address"
+)
+
+// SearchResult is one ranked match of a search. Address results point to the
+// building at that address. Snippet is HTML-escaped with matched terms wrapped
+// in <mark> tags.
+type SearchResult struct {
+	Type     SearchResultType `json:"type" db:"type"`
+	ID       uuid.UUID        `json:"id" db:"id"`
+	Title    string           `json:"title" db:"title"`
+	Subtitle string           `json:"subtitle,omitempty" db:"subtitle"`
+	Snippet  string           `json:"snippet" db:"snippet"`
+	Score    float64          `json:"score" db:"score"`
+}
+
+// Suggestion is an autocomplete match. Address suggestions point to the
+// building at that address.
+type Suggestion struct {
+	Type SearchResultType `json:"type" db:"type"`
+	ID   uuid.UUID        `json:"id" db:"id"`
+	Text string           `json:"text" db:"text"`
+}
+
+// SearchResultTypes lists every search result type.
+var SearchResultTypes = []SearchResultType{SearchNeighborhood, SearchBuilding, SearchAddress}
+
+// IsValid reports whether t is a known search result type.
+func (t SearchResultType) IsValid() bool {
+	for _, known := range SearchResultTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashItem is a deleted neighborhood, building or apartment that can still be
+// restored. Type is the audited entity type; Label is the name of a
+// neighborhood or building and the type of an apartment. ParentID is the
+// neighborhood of a building or the building of an apartment.
+type TrashItem struct {
+	Type      string     `json:"type" db:"type"`
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Label     string     `json:"label" db:"label"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	DeletedAt time.Time  `json:"deleted_at" db:"deleted_at"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"net/mail"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+)
+
+// MinPasswordLength is the shortest password accepted for a user.
+const MinPasswordLength = 8
+
+// User represents a person who can sign in to the API.
+type User struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	TenantID     uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         Role      `json:"role" db:"role"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewUser creates a new User instance with validation.
+func NewUser(id uuid.UUID, tenantID uuid.UUID, email string, passwordHash string, role Role, createdAt time.Time) (User, error) {
+	u := User{ID: id, TenantID: tenantID, Email: email, PasswordHash: passwordHash, Role: role, CreatedAt: createdAt}
+	return u, u.Validate()
+}
+
+// Validate checks if the user is valid.
+func (u User) Validate() error {
+	v := newValidation(apperrors.ErrInvalidInput)
+	v.check(u.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	v.check(u.TenantID != uuid.Nil, "tenant_id", apperrors.CodeRequired, "must not be empty")
+	_, err := mail.ParseAddress(u.Email)
+	v.check(err == nil, "email", apperrors.CodeInvalid, "must be a valid email address")
+	v.check(u.PasswordHash != "", "password", apperrors.CodeRequired, "must not be empty")
+	v.check(u.Role.IsValid(), "role", apperrors.CodeInvalid, "must be a known role")
+	return v.err()
+}
+
+// RefreshToken is a long-lived credential exchanged for new access tokens.
+// Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive reports whether the token can still be used at the given time.
+func (t RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Principal is the authenticated caller of a request: either a user, with a
+// role, or an API key, with scopes. Either way it acts within one tenant.
+type Principal struct {
+	TenantID uuid.UUID     `json:"tenant_id"`
+	UserID   uuid.UUID     `json:"user_id,omitempty"`
+	Email    string        `json:"email,omitempty"`
+	Role     Role          `json:"role,omitempty"`
+	APIKeyID uuid.UUID     `json:"api_key_id,omitempty"`
+	Scopes   []APIKeyScope `json:"scopes,omitempty"`
+}
+
+// Can reports whether the principal's role or scopes grant the permission.
+func (p Principal) Can(permission Permission) bool {
+	if p.APIKeyID != uuid.Nil {
+		for _, scope := range p.Scopes {
+			if scope.Grants(permission) {
+				return true
+			}
+		}
+		return false
+	}
+	return p.Role.Can(permission)
+}
+
+// Actor returns the name recorded for the principal in the audit log.
+func (p Principal) Actor() string {
+	if p.APIKeyID != uuid.Nil {
+		return "api_key:" + p.APIKeyID.String()
+	}
+	return p.Email
+}
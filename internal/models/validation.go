@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+)
+
+// validation collects the field errors found while validating a record, so
+// that a client learns of every rejected field at once.
+type validation struct {
+	kind   error
+	fields []apperrors.FieldError
+}
+
+// newValidation starts validating a record whose failures are of kind.
+func newValidation(kind error) *validation {
+	return &validation{kind: kind}
+}
+
+// check records a field error unless ok holds.
+func (v *validation) check(ok bool, field string, code string, message string) {
+	if !ok {
+		v.fields = append(v.fields, apperrors.FieldError{Field: field, Code: code, Message: message})
+	}
+}
+
+// nest records the field errors of a nested record's validation, prefixing
+// their fields with prefix.
+func (v *validation) nest(prefix string, err error) {
+	var validationErr *apperrors.ValidationError
+	if errors.As(apperrors.PrefixFields(prefix, err), &validationErr) {
+		v.fields = append(v.fields, validationErr.Fields...)
+	}
+}
+
+// err returns a ValidationError listing the recorded field errors, or nil if
+// there were none.
+func (v *validation) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &apperrors.ValidationError{Kind: v.kind, Fields: v.fields}
+}
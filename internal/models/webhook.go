@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// minWebhookSecretLength is the shortest secret accepted for signing payloads.
+const minWebhookSecretLength = 16
+
+// WebhookSubscription represents an external endpoint subscribed to events.
+type WebhookSubscription struct {
+	ID                  uuid.UUID      `json:"id" db:"id"`
+	URL                 string         `json:"url" db:"url"`
+	EventTypes          pq.StringArray `json:"event_types" db:"event_types"`
+	Secret              string         `json:"-" db:"secret"`
+	Active              bool           `json:"active" db:"active"`
+	ConsecutiveFailures int            `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledAt          *time.Time     `json:"disabled_at,omitempty" db:"disabled_at"`
+	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NewWebhookSubscription creates a new active WebhookSubscription with validation.
+func NewWebhookSubscription(id uuid.UUID, rawURL string, eventTypes []string, secret string, createdAt time.Time) (WebhookSubscription, error) {
+	s := WebhookSubscription{
+		ID:         id,
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  createdAt,
+	}
+	return s, s.Validate()
+}
+
+// Validate checks if the webhook subscription is valid.
+func (s WebhookSubscription) Validate() error {
+	v := newValidation(apperrors.ErrInvalidWebhook)
+	v.check(s.ID != uuid.Nil, "id", apperrors.CodeRequired, "must not be empty")
+	u, err := url.Parse(s.URL)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "url", apperrors.CodeInvalid, "must be an http or https URL")
+	v.check(len(s.EventTypes) > 0, "event_types", apperrors.CodeRequired, "must not be empty")
+	for i, t := range s.EventTypes {
+		v.check(EventType(t).IsValid(), fmt.Sprintf("event_types[%d]", i), apperrors.CodeInvalid, "must be a known event type")
+	}
+	v.check(len(s.Secret) >= minWebhookSecretLength, "secret", apperrors.CodeTooShort, fmt.Sprintf("must be at least %d characters", minWebhookSecretLength))
+	return v.err()
+}
+
+// Subscribes reports whether the subscription wants the given event type.
+func (s WebhookSubscription) Subscribes(eventType EventType) bool {
+	for _, t := range s.EventTypes {
+		if EventType(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+// Webhook delivery status constants
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery represents one event queued for a subscription.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	TenantID       uuid.UUID             `json:"-" db:"tenant_id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id" db:"subscription_id"`
+	EventType      EventType             `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	ReplayOf       *uuid.UUID            `json:"replay_of,omitempty" db:"replay_of"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+}
+
+// WebhookDeliveryAttempt records a single HTTP call made for a delivery.
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	DeliveryID     uuid.UUID `json:"delivery_id" db:"delivery_id"`
+	AttemptedAt    time.Time `json:"attempted_at" db:"attempted_at"`
+	ResponseStatus *int      `json:"response_status,omitempty" db:"response_status"`
+	Error          *string   `json:"error,omitempty" db:"error"`
+	DurationMS     int64     `json:"duration_ms" db:"duration_ms"`
+}
+
+// Succeeded reports whether the attempt received a 2xx response.
+func (a WebhookDeliveryAttempt) Succeeded() bool {
+	return a.Error == nil && a.ResponseStatus != nil && *a.ResponseStatus >= 200 && *a.ResponseStatus < 300
+}
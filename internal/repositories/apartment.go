@@ -0,0 +1,226 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// apartmentColumns selects an apartment, aliasing the price columns onto the nested PriceRange.
+const apartmentColumns = `id, building_id, type, price_from AS "price.from", price_to AS "price.to", promotional_price, images, videos, last_update, available, version, updated_at`
+
+// ApartmentRepository defines the interface for apartment data operations.
+type ApartmentRepository interface {
+	Save(ctx context.Context, apartment models.Apartment) error
+	GetByID(ctx context.Context, id string) (models.Apartment, error)
+	GetAvailable(ctx context.Context, id string) (models.Apartment, error)
+	Delete(ctx context.Context, id string, version int64) error
+	Restore(ctx context.Context, id string) (models.Apartment, error)
+	FindByType(ctx context.Context, buildingID uuid.UUID, aptType models.ApartmentType) ([]models.Apartment, error)
+	List(ctx context.Context, query ListQuery) (Page[models.Apartment], error)
+	ListAvailable(ctx context.Context, query ListQuery) (Page[models.Apartment], error)
+	LastModified(ctx context.Context) (time.Time, error)
+}
+
+// apartmentRepository implements ApartmentRepository.
+type apartmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewApartmentRepository creates a new apartment repository.
+func NewApartmentRepository(db *sqlx.DB) ApartmentRepository {
+	return &apartmentRepository{db: db}
+}
+
+// Save inserts or updates an apartment in the database. Apartment.Version is the
+// version being written: an existing row is only replaced by its next version,
+// so a change based on a stale read fails with ErrVersionConflict.
+func (r *apartmentRepository) Save(ctx context.Context, apartment models.Apartment) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO apartments (id, tenant_id, building_id, type, price_from, price_to, promotional_price, images, videos, last_update, available, version, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	          ON CONFLICT (id) DO UPDATE SET building_id = EXCLUDED.building_id, type = EXCLUDED.type, price_from = EXCLUDED.price_from,
+	          price_to = EXCLUDED.price_to, promotional_price = EXCLUDED.promotional_price, images = EXCLUDED.images,
+	          videos = EXCLUDED.videos, last_update = EXCLUDED.last_update, available = EXCLUDED.available, version = EXCLUDED.version, updated_at = EXCLUDED.updated_at
+	          WHERE apartments.tenant_id = EXCLUDED.tenant_id AND apartments.version = EXCLUDED.version - 1`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, apartment.ID, tenantID, apartment.BuildingID, apartment.Type, apartment.Price.From, apartment.Price.To,
+		apartment.PromotionalPrice, apartment.Images, apartment.Videos, apartment.LastUpdate, apartment.Available, apartment.Version, apartment.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			return apperrors.ErrInvalidInput
+		}
+		return err
+	}
+	return checkVersioned(result)
+}
+
+// GetByID retrieves an apartment by ID.
+func (r *apartmentRepository) GetByID(ctx context.Context, id string) (models.Apartment, error) {
+	return r.get(ctx, id, `SELECT `+apartmentColumns+` FROM apartments WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`)
+}
+
+// GetAvailable retrieves an apartment by ID if it is available.
+func (r *apartmentRepository) GetAvailable(ctx context.Context, id string) (models.Apartment, error) {
+	return r.get(ctx, id, `SELECT `+apartmentColumns+` FROM apartments WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL AND available`)
+}
+
+// get runs query, selecting one apartment by the id and tenant given as $1 and $2.
+func (r *apartmentRepository) get(ctx context.Context, id string, query string) (models.Apartment, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Apartment{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	var apartment models.Apartment
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &apartment, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Apartment{}, apperrors.ErrNotFound
+		}
+		return models.Apartment{}, err
+	}
+	return apartment, nil
+}
+
+// Delete moves an apartment to the trash if it is still at the given version.
+func (r *apartmentRepository) Delete(ctx context.Context, id string, version int64) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE apartments SET deleted_at = now(), version = version + 1, updated_at = now()
+	          WHERE id = $1 AND tenant_id = $2 AND version = $3 AND deleted_at IS NULL`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, parsedID, tenantID, version)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return versionMismatch(ctx, r.db, "apartments", parsedID, tenantID)
+	}
+	return nil
+}
+
+// trashApartments moves the apartments of the given buildings, except those
+// already in the trash, to the trash at deletedAt and returns them as they were
+// before.
+func trashApartments(ctx context.Context, q sqlx.ExtContext, tenantID uuid.UUID, buildingIDs []uuid.UUID, deletedAt time.Time) ([]models.Apartment, error) {
+	ids := make([]string, len(buildingIDs))
+	for i, id := range buildingIDs {
+		ids[i] = id.String()
+	}
+
+	apartments := []models.Apartment{}
+	query := `UPDATE apartments a SET deleted_at = $3, version = a.version + 1, updated_at = now()
+	          FROM apartments old
+	          WHERE old.id = a.id AND a.building_id = ANY($1::uuid[]) AND a.tenant_id = $2 AND a.deleted_at IS NULL
+	          RETURNING old.id, old.building_id, old.type, old.price_from AS "price.from", old.price_to AS "price.to", old.promotional_price,
+	          old.images, old.videos, old.last_update, old.available, old.version, old.updated_at`
+	err := sqlx.SelectContext(ctx, q, &apartments, query, pq.Array(ids), tenantID, deletedAt)
+	return apartments, err
+}
+
+// Restore takes an apartment out of the trash.
+func (r *apartmentRepository) Restore(ctx context.Context, id string) (models.Apartment, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Apartment{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	var apartment models.Apartment
+	query := `UPDATE apartments SET deleted_at = NULL, version = version + 1, updated_at = now()
+	          WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL
+	          RETURNING ` + apartmentColumns
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &apartment, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Apartment{}, apperrors.ErrNotFound
+		}
+		return models.Apartment{}, err
+	}
+	return apartment, nil
+}
+
+// FindByType retrieves the apartments of a building that are of the given type.
+func (r *apartmentRepository) FindByType(ctx context.Context, buildingID uuid.UUID, aptType models.ApartmentType) ([]models.Apartment, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apartments := []models.Apartment{}
+	query := `SELECT ` + apartmentColumns + ` FROM apartments WHERE building_id = $1 AND type = $2 AND tenant_id = $3 AND deleted_at IS NULL ORDER BY id`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &apartments, query, buildingID, aptType, tenantID)
+	return apartments, err
+}
+
+// apartmentSchema lists the fields apartments can be filtered and sorted by.
+var apartmentSchema = listSchema[models.Apartment]{
+	fields: map[string]field[models.Apartment]{
+		"id":          {column: "id", kind: uuidField},
+		"building_id": {column: "building_id", kind: uuidField},
+		"type":        {column: "type", kind: textField},
+		"price_from":  {column: "price_from", kind: intField, key: func(a models.Apartment) string { return intKey(a.Price.From) }},
+		"price_to":    {column: "price_to", kind: intField, key: func(a models.Apartment) string { return intKey(a.Price.To) }},
+		"last_update": {column: "last_update", kind: timeField, key: func(a models.Apartment) string { return timeKey(a.LastUpdate) }},
+		"available":   {column: "available", kind: boolField},
+		"updated_at":  {column: "updated_at", kind: timeField, key: func(a models.Apartment) string { return timeKey(a.UpdatedAt) }},
+	},
+	defaultSort: "-last_update",
+	id:          func(a models.Apartment) uuid.UUID { return a.ID },
+}
+
+// List retrieves a page of apartments, most recently updated first unless the query sorts otherwise.
+func (r *apartmentRepository) List(ctx context.Context, query ListQuery) (Page[models.Apartment], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Apartment]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), apartmentSchema, `SELECT `+apartmentColumns+` FROM apartments WHERE tenant_id = $1 AND deleted_at IS NULL`, []any{tenantID}, query)
+}
+
+// ListAvailable retrieves a page of available apartments, most recently
+// updated first unless the query sorts otherwise.
+func (r *apartmentRepository) ListAvailable(ctx context.Context, query ListQuery) (Page[models.Apartment], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Apartment]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), apartmentSchema, `SELECT `+apartmentColumns+` FROM apartments WHERE tenant_id = $1 AND deleted_at IS NULL AND available`, []any{tenantID}, query)
+}
+
+// LastModified returns when a apartment was last saved, deleted or restored.
+func (r *apartmentRepository) LastModified(ctx context.Context) (time.Time, error) {
+	return lastModified(ctx, r.db, "apartments")
+}
@@ -0,0 +1,126 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// apiKeyColumns lists the columns selected for an API key.
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, scopes, created_by, expires_at, revoked_at, last_used_at, created_at`
+
+// APIKeyRepository defines the interface for API key data operations.
+type APIKeyRepository interface {
+	Save(ctx context.Context, key models.APIKey) error
+	GetByID(ctx context.Context, id string) (models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	List(ctx context.Context, query ListQuery) (Page[models.APIKey], error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, granularity time.Duration) error
+}
+
+// apiKeyRepository implements APIKeyRepository.
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository.
+func NewAPIKeyRepository(db *sqlx.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Save inserts an API key in the database.
+func (r *apiKeyRepository) Save(ctx context.Context, key models.APIKey) error {
+	query := `INSERT INTO api_keys (` + apiKeyColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, key.ID, key.TenantID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedBy, key.ExpiresAt, key.RevokedAt, key.LastUsedAt, key.CreatedAt)
+	return err
+}
+
+// GetByID retrieves an API key of the tenant in ctx by ID.
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (models.APIKey, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.APIKey{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return r.get(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 AND tenant_id = $2`, parsedID, tenantID)
+}
+
+// GetByHash retrieves an API key in any tenant by the hash of its value.
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	return r.get(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash)
+}
+
+// get runs a query returning a single API key.
+func (r *apiKeyRepository) get(ctx context.Context, query string, args ...any) (models.APIKey, error) {
+	var key models.APIKey
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &key, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKey{}, apperrors.ErrNotFound
+		}
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+// apiKeySchema lists the fields API keys can be filtered and sorted by.
+var apiKeySchema = listSchema[models.APIKey]{
+	fields: map[string]field[models.APIKey]{
+		"name":       {column: "name", kind: textField, key: func(k models.APIKey) string { return k.Name }},
+		"created_at": {column: "created_at", kind: timeField, key: func(k models.APIKey) string { return timeKey(k.CreatedAt) }},
+	},
+	defaultSort: "-created_at",
+	id:          func(k models.APIKey) uuid.UUID { return k.ID },
+}
+
+// List retrieves a page of the API keys of the tenant in ctx, newest first unless the query sorts otherwise.
+func (r *apiKeyRepository) List(ctx context.Context, query ListQuery) (Page[models.APIKey], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.APIKey]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), apiKeySchema, `SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1`, []any{tenantID}, query)
+}
+
+// Revoke marks an API key as revoked. Revoking a revoked key is a no-op.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1 AND tenant_id = $3`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, id, revokedAt, tenantID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records that the key was used. The row is only written when the
+// stored value is older than granularity, so busy keys do not cause a write per request.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, granularity time.Duration) error {
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, id, usedAt, usedAt.Add(-granularity))
+	return err
+}
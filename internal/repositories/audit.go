@@ -0,0 +1,63 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditRepository defines the interface for audit log data operations.
+type AuditRepository interface {
+	Append(ctx context.Context, entry models.AuditEntry) error
+	List(ctx context.Context, query ListQuery) (Page[models.AuditEntry], error)
+}
+
+// auditRepository implements AuditRepository.
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository creates a new audit repository.
+func NewAuditRepository(db *sqlx.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Append inserts an audit entry. Call it with the context of the transaction
+// that performs the change so both commit together.
+func (r *auditRepository) Append(ctx context.Context, entry models.AuditEntry) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO audit_log (id, tenant_id, actor, request_id, action, entity_type, entity_id, diff, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = executor(ctx, r.db).ExecContext(ctx, query, entry.ID, tenantID, entry.Actor, entry.RequestID, entry.Action, entry.EntityType, entry.EntityID, entry.Diff, entry.CreatedAt)
+	return err
+}
+
+// auditSchema lists the fields audit entries can be filtered and sorted by.
+var auditSchema = listSchema[models.AuditEntry]{
+	fields: map[string]field[models.AuditEntry]{
+		"entity_type": {column: "entity_type", kind: textField},
+		"entity_id":   {column: "entity_id", kind: uuidField},
+		"actor":       {column: "actor", kind: textField},
+		"action":      {column: "action", kind: textField},
+		"created_at":  {column: "created_at", kind: timeField, key: func(e models.AuditEntry) string { return timeKey(e.CreatedAt) }},
+	},
+	defaultSort: "-created_at",
+	id:          func(e models.AuditEntry) uuid.UUID { return e.ID },
+}
+
+// List retrieves a page of audit entries, newest first unless the query sorts otherwise.
+func (r *auditRepository) List(ctx context.Context, query ListQuery) (Page[models.AuditEntry], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.AuditEntry]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), auditSchema, `SELECT id, actor, request_id, action, entity_type, entity_id, diff, created_at FROM audit_log WHERE tenant_id = $1`, []any{tenantID}, query)
+}
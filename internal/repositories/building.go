@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/models"
@@ -17,8 +18,13 @@ import (
 type BuildingRepository interface {
 	Save(ctx context.Context, building models.Building) error
 	GetByID(ctx context.Context, id string) (models.Building, error)
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context) ([]models.Building, error)
+	GetListed(ctx context.Context, id string) (models.Building, error)
+	Delete(ctx context.Context, id string, version int64) ([]models.Apartment, error)
+	Restore(ctx context.Context, id string) (models.Building, []models.Apartment, error)
+	FindByName(ctx context.Context, neighborhoodID uuid.UUID, name string) ([]models.Building, error)
+	List(ctx context.Context, query ListQuery) (Page[models.Building], error)
+	ListListed(ctx context.Context, query ListQuery) (Page[models.Building], error)
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 // buildingRepository implements BuildingRepository.
@@ -31,11 +37,20 @@ func NewBuildingRepository(db *sqlx.DB) BuildingRepository {
 	return &buildingRepository{db: db}
 }
 
-// Save inserts or updates a building in the database.
+// Save inserts or updates a building in the database. Building.Version is the
+// version being written: an existing row is only replaced by its next version,
+// so a change based on a stale read fails with ErrVersionConflict.
 func (r *buildingRepository) Save(ctx context.Context, building models.Building) error {
-	query := `INSERT INTO buildings (id, name, neighborhood_id, address) VALUES ($1, $2, $3, $4)
-	          ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, neighborhood_id = EXCLUDED.neighborhood_id, address = EXCLUDED.address`
-	_, err := r.db.ExecContext(ctx, query, building.ID, building.Name, building.NeighborhoodID, building.Address)
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO buildings (id, tenant_id, name, neighborhood_id, address, version, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, neighborhood_id = EXCLUDED.neighborhood_id, address = EXCLUDED.address,
+	          version = EXCLUDED.version, updated_at = EXCLUDED.updated_at
+	          WHERE buildings.tenant_id = EXCLUDED.tenant_id AND buildings.version = EXCLUDED.version - 1`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, building.ID, tenantID, building.Name, building.NeighborhoodID, building.Address, building.Version, building.UpdatedAt)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
@@ -43,19 +58,37 @@ func (r *buildingRepository) Save(ctx context.Context, building models.Building)
 		}
 		return err
 	}
-	return nil
+	return checkVersioned(result)
 }
 
+// listedBuilding is the condition for a building to be listed on the public
+// website: it has an available apartment.
+const listedBuilding = `EXISTS (SELECT 1 FROM apartments a
+	WHERE a.building_id = buildings.id AND a.tenant_id = buildings.tenant_id AND a.available AND a.deleted_at IS NULL)`
+
 // GetByID retrieves a building by ID.
 func (r *buildingRepository) GetByID(ctx context.Context, id string) (models.Building, error) {
+	return r.get(ctx, id, `SELECT id, name, neighborhood_id, address, version, updated_at FROM buildings WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`)
+}
+
+// GetListed retrieves a building by ID if it has an available apartment.
+func (r *buildingRepository) GetListed(ctx context.Context, id string) (models.Building, error) {
+	return r.get(ctx, id, `SELECT id, name, neighborhood_id, address, version, updated_at FROM buildings WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL AND `+listedBuilding)
+}
+
+// get runs query, selecting one building by the id and tenant given as $1 and $2.
+func (r *buildingRepository) get(ctx context.Context, id string, query string) (models.Building, error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
 		return models.Building{}, apperrors.ErrInvalidID
 	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Building{}, err
+	}
 
 	var building models.Building
-	query := `SELECT id, name, neighborhood_id, address FROM buildings WHERE id = $1`
-	err = r.db.GetContext(ctx, &building, query, parsedID)
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &building, query, parsedID, tenantID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Building{}, apperrors.ErrNotFound
@@ -65,32 +98,120 @@ func (r *buildingRepository) GetByID(ctx context.Context, id string) (models.Bui
 	return building, nil
 }
 
-// Delete removes a building by ID.
-func (r *buildingRepository) Delete(ctx context.Context, id string) error {
+// Delete moves a building and its apartments to the trash if the building is
+// still at the given version, and returns the apartments as they were before.
+func (r *buildingRepository) Delete(ctx context.Context, id string, version int64) ([]models.Apartment, error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
-		return apperrors.ErrInvalidID
+		return nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	query := `DELETE FROM buildings WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, parsedID)
+	var deletedAt time.Time
+	query := `UPDATE buildings SET deleted_at = now(), version = version + 1, updated_at = now()
+	          WHERE id = $1 AND tenant_id = $2 AND version = $3 AND deleted_at IS NULL
+	          RETURNING deleted_at`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &deletedAt, query, parsedID, tenantID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, versionMismatch(ctx, r.db, "buildings", parsedID, tenantID)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	rowsAffected, err := result.RowsAffected()
+	return trashApartments(ctx, executor(ctx, r.db), tenantID, []uuid.UUID{parsedID}, deletedAt)
+}
+
+// Restore takes a building out of the trash together with the apartments
+// trashed with it, and returns it and the restored apartments.
+func (r *buildingRepository) Restore(ctx context.Context, id string) (models.Building, []models.Apartment, error) {
+	parsedID, err := uuid.Parse(id)
 	if err != nil {
-		return err
+		return models.Building{}, nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Building{}, nil, err
+	}
+
+	var restored struct {
+		models.Building
+		DeletedAt time.Time `db:"deleted_at"`
+	}
+	query := `UPDATE buildings b SET deleted_at = NULL, version = b.version + 1, updated_at = now()
+	          FROM (SELECT id, deleted_at FROM buildings WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL FOR UPDATE) trashed
+	          WHERE b.id = trashed.id
+	          RETURNING b.id, b.name, b.neighborhood_id, b.address, b.version, b.updated_at, trashed.deleted_at`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &restored, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Building{}, nil, apperrors.ErrNotFound
+		}
+		return models.Building{}, nil, err
 	}
-	if rowsAffected == 0 {
-		return apperrors.ErrNotFound
+
+	apartments := []models.Apartment{}
+	query = `UPDATE apartments SET deleted_at = NULL, version = version + 1, updated_at = now()
+	         WHERE building_id = $1 AND tenant_id = $2 AND deleted_at = $3
+	         RETURNING ` + apartmentColumns
+	if err := sqlx.SelectContext(ctx, executor(ctx, r.db), &apartments, query, parsedID, tenantID, restored.DeletedAt); err != nil {
+		return models.Building{}, nil, err
 	}
-	return nil
+	return restored.Building, apartments, nil
 }
 
-// List retrieves all buildings.
-func (r *buildingRepository) List(ctx context.Context) ([]models.Building, error) {
-	var buildings []models.Building
-	query := `SELECT id, name, neighborhood_id, address FROM buildings ORDER BY name`
-	err := r.db.SelectContext(ctx, &buildings, query)
+// FindByName retrieves the buildings of a neighborhood whose name matches name,
+// ignoring case. Names are not unique, so there may be more than one.
+func (r *buildingRepository) FindByName(ctx context.Context, neighborhoodID uuid.UUID, name string) ([]models.Building, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buildings := []models.Building{}
+	query := `SELECT id, name, neighborhood_id, address, version, updated_at FROM buildings
+	          WHERE neighborhood_id = $1 AND lower(name) = lower($2) AND tenant_id = $3 AND deleted_at IS NULL ORDER BY id`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &buildings, query, neighborhoodID, name, tenantID)
 	return buildings, err
 }
+
+// buildingSchema lists the fields buildings can be filtered and sorted by.
+var buildingSchema = listSchema[models.Building]{
+	fields: map[string]field[models.Building]{
+		"id":              {column: "id", kind: uuidField},
+		"name":            {column: "name", kind: textField, key: func(b models.Building) string { return b.Name }},
+		"neighborhood_id": {column: "neighborhood_id", kind: uuidField},
+		"address":         {column: "address", kind: textField, key: func(b models.Building) string { return b.Address }},
+		"updated_at":      {column: "updated_at", kind: timeField, key: func(b models.Building) string { return timeKey(b.UpdatedAt) }},
+	},
+	defaultSort: "name",
+	id:          func(b models.Building) uuid.UUID { return b.ID },
+}
+
+// List retrieves a page of buildings, ordered by name unless the query sorts otherwise.
+func (r *buildingRepository) List(ctx context.Context, query ListQuery) (Page[models.Building], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Building]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), buildingSchema, `SELECT id, name, neighborhood_id, address, version, updated_at FROM buildings WHERE tenant_id = $1 AND deleted_at IS NULL`, []any{tenantID}, query)
+}
+
+// ListListed retrieves a page of the buildings that have an available
+// apartment, ordered by name unless the query sorts otherwise.
+func (r *buildingRepository) ListListed(ctx context.Context, query ListQuery) (Page[models.Building], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Building]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), buildingSchema, `SELECT id, name, neighborhood_id, address, version, updated_at FROM buildings WHERE tenant_id = $1 AND deleted_at IS NULL AND `+listedBuilding, []any{tenantID}, query)
+}
+
+// LastModified returns when a building was last saved, deleted or restored.
+func (r *buildingRepository) LastModified(ctx context.Context) (time.Time, error) {
+	return lastModified(ctx, r.db, "buildings")
+}
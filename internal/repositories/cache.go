@@ -0,0 +1,372 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// cacheChannel is the Postgres notification channel invalidations are broadcast on.
+const cacheChannel = "repository_cache"
+
+// Names of the caches an invalidation applies to.
+const (
+	neighborhoodCache = "neighborhoods"
+	buildingCache     = "buildings"
+)
+
+// Cache keeps recently read neighborhoods and buildings in memory for the
+// caching repositories it creates. Writes evict the entries they change, here
+// and, through Postgres NOTIFY, on every other instance that called Listen.
+type Cache struct {
+	db            *sqlx.DB
+	neighborhoods *lruCache[models.Neighborhood]
+	buildings     *lruCache[models.Building]
+}
+
+// NewCache creates a cache holding up to size neighborhoods and size buildings,
+// each for at most ttl.
+func NewCache(db *sqlx.DB, size int, ttl time.Duration) *Cache {
+	return &Cache{
+		db:            db,
+		neighborhoods: newLRUCache[models.Neighborhood](size, ttl),
+		buildings:     newLRUCache[models.Building](size, ttl),
+	}
+}
+
+// Neighborhoods wraps repo so that GetByID is served from the cache.
+func (c *Cache) Neighborhoods(repo NeighborhoodRepository) NeighborhoodRepository {
+	return &cachedNeighborhoodRepository{NeighborhoodRepository: repo, cache: c}
+}
+
+// Buildings wraps repo so that GetByID is served from the cache.
+func (c *Cache) Buildings(repo BuildingRepository) BuildingRepository {
+	return &cachedBuildingRepository{BuildingRepository: repo, cache: c}
+}
+
+// Listen subscribes to the invalidations broadcast by other instances and
+// applies them until ctx is cancelled. It returns once the subscription is in
+// place. The whole cache is dropped after a reconnect, since invalidations may
+// have been missed.
+func (c *Cache) Listen(ctx context.Context, dataSourceName string, logger *zap.Logger) error {
+	return Listen(ctx, dataSourceName, cacheChannel, logger, c.apply, c.purge)
+}
+
+// invalidate evicts the entry for id from the named cache, or every entry of
+// the tenant when id is empty, and broadcasts the eviction. Within a
+// transaction the notification is only delivered on commit, which also evicts
+// anything a concurrent reader cached from the old row in the meantime.
+func (c *Cache) invalidate(ctx context.Context, cache string, tenantID uuid.UUID, id string) error {
+	if parsedID, err := uuid.Parse(id); err == nil {
+		id = parsedID.String()
+	}
+	payload := cache + " " + tenantID.String() + " " + id
+	c.apply(payload)
+	_, err := executor(ctx, c.db).ExecContext(ctx, `SELECT pg_notify($1, $2)`, cacheChannel, payload)
+	return err
+}
+
+// apply evicts the entries named by an invalidation payload.
+func (c *Cache) apply(payload string) {
+	name, rest, _ := strings.Cut(payload, " ")
+	tenantID, id, _ := strings.Cut(rest, " ")
+
+	evict := c.buildings.delete
+	evictPrefix := c.buildings.deletePrefix
+	if name == neighborhoodCache {
+		evict = c.neighborhoods.delete
+		evictPrefix = c.neighborhoods.deletePrefix
+	}
+	if id == "" {
+		evictPrefix(tenantID + "/")
+		return
+	}
+	evict(tenantID + "/" + id)
+}
+
+// purge drops every cached entry.
+func (c *Cache) purge() {
+	c.neighborhoods.deletePrefix("")
+	c.buildings.deletePrefix("")
+}
+
+// cacheKey returns the cache key of the row id of the tenant in ctx. ok is
+// false when the read should bypass the cache: inside a transaction, which may
+// see its own uncommitted writes, or when the id or tenant is invalid, which
+// the repository reports.
+func cacheKey(ctx context.Context, id string) (key string, ok bool) {
+	if _, inTx := ctx.Value(txKey{}).(*sqlx.Tx); inTx {
+		return "", false
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return "", false
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return "", false
+	}
+	return tenantID.String() + "/" + parsedID.String(), true
+}
+
+// readThrough returns the cached value for id, loading and caching it on a miss.
+func readThrough[V any](ctx context.Context, cache *lruCache[V], id string, load func(ctx context.Context, id string) (V, error)) (V, error) {
+	key, ok := cacheKey(ctx, id)
+	if !ok {
+		return load(ctx, id)
+	}
+	if value, hit := cache.get(key); hit {
+		return value, nil
+	}
+
+	generation := cache.generation()
+	value, err := load(ctx, id)
+	if err != nil {
+		return value, err
+	}
+	cache.add(key, value, generation)
+	return value, nil
+}
+
+// cachedNeighborhoodRepository serves neighborhoods from a Cache and evicts
+// them when they change. Methods not declared here go straight to the
+// wrapped repository.
+type cachedNeighborhoodRepository struct {
+	NeighborhoodRepository
+	cache *Cache
+}
+
+// Save saves the neighborhood and evicts its cached copy.
+func (r *cachedNeighborhoodRepository) Save(ctx context.Context, neighborhood models.Neighborhood) error {
+	if err := r.NeighborhoodRepository.Save(ctx, neighborhood); err != nil {
+		return err
+	}
+	return r.evict(ctx, neighborhoodCache, neighborhood.ID.String())
+}
+
+// GetByID returns the neighborhood from the cache, reading it on a miss.
+func (r *cachedNeighborhoodRepository) GetByID(ctx context.Context, id string) (models.Neighborhood, error) {
+	return readThrough(ctx, r.cache.neighborhoods, id, r.NeighborhoodRepository.GetByID)
+}
+
+// Delete trashes the neighborhood and evicts it along with the buildings
+// trashed with it.
+func (r *cachedNeighborhoodRepository) Delete(ctx context.Context, id string, version int64) ([]models.Building, []models.Apartment, error) {
+	buildings, apartments, err := r.NeighborhoodRepository.Delete(ctx, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.evict(ctx, neighborhoodCache, id); err != nil {
+		return nil, nil, err
+	}
+	for _, building := range buildings {
+		if err := r.evict(ctx, buildingCache, building.ID.String()); err != nil {
+			return nil, nil, err
+		}
+	}
+	return buildings, apartments, nil
+}
+
+// Restore restores the neighborhood and evicts it and its restored buildings.
+func (r *cachedNeighborhoodRepository) Restore(ctx context.Context, id string) (models.Neighborhood, []models.Building, []models.Apartment, error) {
+	neighborhood, buildings, apartments, err := r.NeighborhoodRepository.Restore(ctx, id)
+	if err != nil {
+		return neighborhood, buildings, apartments, err
+	}
+	if err := r.evict(ctx, neighborhoodCache, neighborhood.ID.String()); err != nil {
+		return models.Neighborhood{}, nil, nil, err
+	}
+	for _, building := range buildings {
+		if err := r.evict(ctx, buildingCache, building.ID.String()); err != nil {
+			return models.Neighborhood{}, nil, nil, err
+		}
+	}
+	return neighborhood, buildings, apartments, nil
+}
+
+// MoveBuildings moves the buildings and evicts them.
+func (r *cachedNeighborhoodRepository) MoveBuildings(ctx context.Context, fromID string, toID string) ([]models.Building, error) {
+	buildings, err := r.NeighborhoodRepository.MoveBuildings(ctx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	for _, building := range buildings {
+		if err := r.evict(ctx, buildingCache, building.ID.String()); err != nil {
+			return nil, err
+		}
+	}
+	return buildings, nil
+}
+
+// evict invalidates an entry of the named cache for the tenant in ctx.
+func (r *cachedNeighborhoodRepository) evict(ctx context.Context, cache string, id string) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	return r.cache.invalidate(ctx, cache, tenantID, id)
+}
+
+// cachedBuildingRepository serves buildings from a Cache and evicts them when
+// they change. Methods not declared here go straight to the wrapped
+// repository.
+type cachedBuildingRepository struct {
+	BuildingRepository
+	cache *Cache
+}
+
+// Save saves the building and evicts its cached copy.
+func (r *cachedBuildingRepository) Save(ctx context.Context, building models.Building) error {
+	if err := r.BuildingRepository.Save(ctx, building); err != nil {
+		return err
+	}
+	return r.evict(ctx, building.ID.String())
+}
+
+// GetByID returns the building from the cache, reading it on a miss.
+func (r *cachedBuildingRepository) GetByID(ctx context.Context, id string) (models.Building, error) {
+	return readThrough(ctx, r.cache.buildings, id, r.BuildingRepository.GetByID)
+}
+
+// Delete trashes the building and evicts its cached copy.
+func (r *cachedBuildingRepository) Delete(ctx context.Context, id string, version int64) ([]models.Apartment, error) {
+	apartments, err := r.BuildingRepository.Delete(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.evict(ctx, id); err != nil {
+		return nil, err
+	}
+	return apartments, nil
+}
+
+// Restore restores the building and evicts any cached copy.
+func (r *cachedBuildingRepository) Restore(ctx context.Context, id string) (models.Building, []models.Apartment, error) {
+	building, apartments, err := r.BuildingRepository.Restore(ctx, id)
+	if err != nil {
+		return building, apartments, err
+	}
+	if err := r.evict(ctx, building.ID.String()); err != nil {
+		return models.Building{}, nil, err
+	}
+	return building, apartments, nil
+}
+
+// evict invalidates the cached building id of the tenant in ctx.
+func (r *cachedBuildingRepository) evict(ctx context.Context, id string) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+	return r.cache.invalidate(ctx, buildingCache, tenantID, id)
+}
+
+// lruCache is a size-bounded map whose entries expire after a TTL, evicting
+// the least recently used entry when full. It is safe for concurrent use.
+type lruCache[V any] struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	// evictions counts deletions so that a value read before one is not
+	// cached after it.
+	evictions uint64
+}
+
+// lruEntry is an element of lruCache.order.
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// newLRUCache creates an empty cache of the given size and TTL.
+func newLRUCache[V any](size int, ttl time.Duration) *lruCache[V] {
+	return &lruCache[V]{size: size, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// get returns the unexpired value cached under key.
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := element.Value.(*lruEntry[V])
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// generation returns a token to pass to add for a value about to be loaded.
+func (c *lruCache[V]) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evictions
+}
+
+// add caches value under key unless something was deleted since generation
+// was taken, in which case the value may already be stale.
+func (c *lruCache[V]) add(key string, value V, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.evictions != generation {
+		return
+	}
+	entry := &lruEntry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// delete removes the value cached under key.
+func (c *lruCache[V]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictions++
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// deletePrefix removes every value cached under a key starting with prefix.
+func (c *lruCache[V]) deletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictions++
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// buildingExportQuery selects the buildings of tenant $1 with the name of
+// their neighborhood. The join is wrapped so buildingSchema's columns apply.
+const buildingExportQuery = `SELECT id, name, neighborhood_id, address, version, updated_at, neighborhood FROM (
+	SELECT b.id, b.name, b.neighborhood_id, b.address, b.version, b.updated_at, n.name AS neighborhood
+	FROM buildings b JOIN neighborhoods n ON n.id = b.neighborhood_id
+	WHERE b.tenant_id = $1 AND b.deleted_at IS NULL
+) buildings WHERE TRUE`
+
+// apartmentExportQuery selects the apartments of tenant $1 with the names of
+// their building and neighborhood. The join is wrapped so apartmentSchema's
+// columns apply.
+const apartmentExportQuery = `SELECT ` + apartmentColumns + `, neighborhood, building FROM (
+	SELECT a.*, b.name AS building, n.name AS neighborhood
+	FROM apartments a JOIN buildings b ON b.id = a.building_id JOIN neighborhoods n ON n.id = b.neighborhood_id
+	WHERE a.tenant_id = $1 AND a.deleted_at IS NULL
+) apartments WHERE TRUE`
+
+// ExportRepository defines the interface for streaming listings out in bulk.
+// Each method calls fn with every row matching the query's filters, in its
+// order, and must run inside a transaction.
+type ExportRepository interface {
+	Neighborhoods(ctx context.Context, query ListQuery, fn func(models.Neighborhood) error) error
+	Buildings(ctx context.Context, query ListQuery, fn func(models.BuildingExport) error) error
+	Apartments(ctx context.Context, query ListQuery, fn func(models.ApartmentExport) error) error
+}
+
+// exportRepository implements ExportRepository.
+type exportRepository struct {
+	db *sqlx.DB
+}
+
+// NewExportRepository creates a new export repository.
+func NewExportRepository(db *sqlx.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// Neighborhoods streams neighborhoods, filtered and sorted like their list.
+func (r *exportRepository) Neighborhoods(ctx context.Context, query ListQuery, fn func(models.Neighborhood) error) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return streamRows(ctx, executor(ctx, r.db), neighborhoodSchema, `SELECT id, name, version, updated_at FROM neighborhoods WHERE tenant_id = $1 AND deleted_at IS NULL`, []any{tenantID}, query, fn)
+}
+
+// Buildings streams buildings with their neighborhood's name, filtered and
+// sorted like their list.
+func (r *exportRepository) Buildings(ctx context.Context, query ListQuery, fn func(models.BuildingExport) error) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return streamRows(ctx, executor(ctx, r.db), buildingSchema, buildingExportQuery, []any{tenantID}, query, fn)
+}
+
+// Apartments streams apartments with their building's and neighborhood's
+// names, filtered and sorted like their list.
+func (r *exportRepository) Apartments(ctx context.Context, query ListQuery, fn func(models.ApartmentExport) error) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	return streamRows(ctx, executor(ctx, r.db), apartmentSchema, apartmentExportQuery, []any{tenantID}, query, fn)
+}
@@ -0,0 +1,66 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// feedQuery selects the live, available apartments of tenant $1 with their
+// building and neighborhood, grouped by neighborhood and building so feeds read
+// in order. Only available apartments are published, as on the public API.
+const feedQuery = `SELECT ` + apartmentColumns + `, building, address, neighborhood_id, neighborhood FROM (
+	SELECT a.*, b.name AS building, b.address, n.id AS neighborhood_id, n.name AS neighborhood
+	FROM apartments a JOIN buildings b ON b.id = a.building_id JOIN neighborhoods n ON n.id = b.neighborhood_id
+	WHERE a.tenant_id = $1 AND a.deleted_at IS NULL AND a.available AND b.deleted_at IS NULL AND n.deleted_at IS NULL
+) apartments ORDER BY neighborhood, building, price_from, id`
+
+// FeedRepository defines the interface for reading the listings syndicated in feeds.
+type FeedRepository interface {
+	Listings(ctx context.Context) ([]models.FeedListing, error)
+	LastModified(ctx context.Context) (time.Time, error)
+}
+
+// feedRepository implements FeedRepository.
+type feedRepository struct {
+	db *sqlx.DB
+}
+
+// NewFeedRepository creates a new feed repository.
+func NewFeedRepository(db *sqlx.DB) FeedRepository {
+	return &feedRepository{db: db}
+}
+
+// Listings retrieves every live, available apartment with its building and
+// neighborhood.
+func (r *feedRepository) Listings(ctx context.Context) ([]models.FeedListing, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := []models.FeedListing{}
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &listings, feedQuery, tenantID)
+	return listings, err
+}
+
+// LastModified returns when a neighborhood, building or apartment was last
+// saved, deleted or restored, or the zero time if there are none at all.
+func (r *feedRepository) LastModified(ctx context.Context) (time.Time, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var lastModified sql.NullTime
+	query := `SELECT GREATEST(
+	              (SELECT max(updated_at) FROM neighborhoods WHERE tenant_id = $1),
+	              (SELECT max(updated_at) FROM buildings WHERE tenant_id = $1),
+	              (SELECT max(updated_at) FROM apartments WHERE tenant_id = $1))`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &lastModified, query, tenantID)
+	return lastModified.Time, err
+}
@@ -0,0 +1,103 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// IdempotencyRepository defines the interface for idempotency key data operations.
+type IdempotencyRepository interface {
+	Claim(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record models.IdempotencyRecord) error
+	Release(ctx context.Context, scope string, key string) error
+}
+
+// idempotencyRepository implements IdempotencyRepository.
+type idempotencyRepository struct {
+	db *sqlx.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository.
+func NewIdempotencyRepository(db *sqlx.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db, lastSweep: time.Now()}
+}
+
+// Claim records the key of a new request. When the key is already held, and
+// has not expired, it returns the held record and false instead. A key whose
+// request has not completed is taken over by the same request once its lease
+// has run out.
+func (r *idempotencyRepository) Claim(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	if err := r.sweep(ctx); err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+
+	claim := `INSERT INTO idempotency_keys (scope, key, request_hash, created_at, locked_until) VALUES ($1, $2, $3, $4, $5)
+	          ON CONFLICT (scope, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = NULL, headers = NULL, body = NULL,
+	              created_at = EXCLUDED.created_at, locked_until = EXCLUDED.locked_until
+	          WHERE idempotency_keys.created_at < $6
+	             OR (idempotency_keys.status IS NULL AND idempotency_keys.locked_until < EXCLUDED.created_at
+	                 AND idempotency_keys.request_hash = EXCLUDED.request_hash)`
+	held := `SELECT scope, key, request_hash, status, headers, body, created_at FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	lockedUntil := record.CreatedAt.Add(models.IdempotencyLease)
+	expiredBefore := record.CreatedAt.Add(-models.IdempotencyTTL)
+
+	// A held key may be released between the two statements; claim it again then.
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := executor(ctx, r.db).ExecContext(ctx, claim, record.Scope, record.Key, record.RequestHash, record.CreatedAt, lockedUntil, expiredBefore)
+		if err != nil {
+			return models.IdempotencyRecord{}, false, err
+		}
+		if claimed, err := result.RowsAffected(); err != nil || claimed > 0 {
+			return record, err == nil, err
+		}
+
+		var existing models.IdempotencyRecord
+		err = sqlx.GetContext(ctx, executor(ctx, r.db), &existing, held, record.Scope, record.Key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		return existing, false, err
+	}
+	return models.IdempotencyRecord{}, false, errors.New("idempotency key claimed and released concurrently")
+}
+
+// Complete records the response to the request that claimed the key.
+func (r *idempotencyRepository) Complete(ctx context.Context, record models.IdempotencyRecord) error {
+	query := `UPDATE idempotency_keys SET status = $3, headers = $4, body = $5 WHERE scope = $1 AND key = $2`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, record.Scope, record.Key, record.Status, record.Headers, record.Body)
+	return err
+}
+
+// Release forgets a key whose request failed, so that a retry runs it again.
+func (r *idempotencyRepository) Release(ctx context.Context, scope string, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status IS NULL`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, scope, key)
+	return err
+}
+
+// sweep deletes expired keys, at most once an hour per instance.
+func (r *idempotencyRepository) sweep(ctx context.Context) error {
+	r.mu.Lock()
+	due := time.Since(r.lastSweep) >= time.Hour
+	if due {
+		r.lastSweep = time.Now()
+	}
+	r.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	query := `DELETE FROM idempotency_keys WHERE created_at < $1`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, time.Now().Add(-models.IdempotencyTTL))
+	return err
+}
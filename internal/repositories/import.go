@@ -0,0 +1,119 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// importColumns selects an import.
+const importColumns = `id, entity, dry_run, status, total_rows, created, updated, unchanged, failed, header, actor, created_at`
+
+// ImportRepository defines the interface for import record operations.
+type ImportRepository interface {
+	Save(ctx context.Context, imp models.Import, rejected []models.ImportError) error
+	GetByID(ctx context.Context, id string) (models.Import, error)
+	ListErrors(ctx context.Context, id string) ([]models.ImportError, error)
+	List(ctx context.Context, query ListQuery) (Page[models.Import], error)
+}
+
+// importRepository implements ImportRepository.
+type importRepository struct {
+	db *sqlx.DB
+}
+
+// NewImportRepository creates a new import repository.
+func NewImportRepository(db *sqlx.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+// Save inserts an import together with its rejected rows.
+func (r *importRepository) Save(ctx context.Context, imp models.Import, rejected []models.ImportError) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO imports (id, tenant_id, entity, dry_run, status, total_rows, created, updated, unchanged, failed, header, actor, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err = executor(ctx, r.db).ExecContext(ctx, query, imp.ID, tenantID, imp.Entity, imp.DryRun, imp.Status, imp.Rows, imp.Created, imp.Updated, imp.Unchanged, imp.Failed, imp.Header, imp.Actor, imp.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO import_errors (import_id, line, cells, error) VALUES ($1, $2, $3, $4)`
+	for _, row := range rejected {
+		if _, err := executor(ctx, r.db).ExecContext(ctx, query, imp.ID, row.Line, row.Cells, row.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves an import by ID.
+func (r *importRepository) GetByID(ctx context.Context, id string) (models.Import, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Import{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Import{}, err
+	}
+
+	var imp models.Import
+	query := `SELECT ` + importColumns + ` FROM imports WHERE id = $1 AND tenant_id = $2`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &imp, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Import{}, apperrors.ErrNotFound
+		}
+		return models.Import{}, err
+	}
+	return imp, nil
+}
+
+// ListErrors retrieves the rejected rows of an import in file order.
+func (r *importRepository) ListErrors(ctx context.Context, id string) ([]models.ImportError, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []models.ImportError{}
+	query := `SELECT e.line, e.cells, e.error FROM import_errors e JOIN imports i ON i.id = e.import_id
+	          WHERE e.import_id = $1 AND i.tenant_id = $2 ORDER BY e.line`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &rows, query, parsedID, tenantID)
+	return rows, err
+}
+
+// importSchema lists the fields imports can be filtered and sorted by.
+var importSchema = listSchema[models.Import]{
+	fields: map[string]field[models.Import]{
+		"entity":     {column: "entity", kind: textField},
+		"status":     {column: "status", kind: textField},
+		"created_at": {column: "created_at", kind: timeField, key: func(i models.Import) string { return timeKey(i.CreatedAt) }},
+	},
+	defaultSort: "-created_at",
+	id:          func(i models.Import) uuid.UUID { return i.ID },
+}
+
+// List retrieves a page of imports, most recent first unless the query sorts otherwise.
+func (r *importRepository) List(ctx context.Context, query ListQuery) (Page[models.Import], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Import]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), importSchema, `SELECT `+importColumns+` FROM imports WHERE tenant_id = $1`, []any{tenantID}, query)
+}
@@ -0,0 +1,100 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// InquiryRepository defines the interface for inquiry data operations.
+type InquiryRepository interface {
+	Save(ctx context.Context, inquiry models.Inquiry) error
+	GetByID(ctx context.Context, id string) (models.Inquiry, error)
+	List(ctx context.Context, buildingID uuid.UUID, query ListQuery) (Page[models.Inquiry], error)
+}
+
+// inquiryRepository implements InquiryRepository.
+type inquiryRepository struct {
+	db *sqlx.DB
+}
+
+// NewInquiryRepository creates a new inquiry repository.
+func NewInquiryRepository(db *sqlx.DB) InquiryRepository {
+	return &inquiryRepository{db: db}
+}
+
+// Save inserts an inquiry in the database.
+func (r *inquiryRepository) Save(ctx context.Context, inquiry models.Inquiry) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO inquiries (id, tenant_id, building_id, name, email, phone, message, source, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = executor(ctx, r.db).ExecContext(ctx, query, inquiry.ID, tenantID, inquiry.BuildingID, inquiry.Name, inquiry.Email, inquiry.Phone, inquiry.Message, inquiry.Source, inquiry.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			return apperrors.ErrInvalidInput
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves an inquiry by ID.
+func (r *inquiryRepository) GetByID(ctx context.Context, id string) (models.Inquiry, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Inquiry{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	var inquiry models.Inquiry
+	query := `SELECT id, building_id, name, email, phone, message, source, created_at FROM inquiries WHERE id = $1 AND tenant_id = $2`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &inquiry, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Inquiry{}, apperrors.ErrNotFound
+		}
+		return models.Inquiry{}, err
+	}
+	return inquiry, nil
+}
+
+// inquirySchema lists the fields inquiries can be filtered and sorted by.
+var inquirySchema = listSchema[models.Inquiry]{
+	fields: map[string]field[models.Inquiry]{
+		"building_id": {column: "building_id", kind: uuidField},
+		"email":       {column: "email", kind: textField},
+		"source":      {column: "source", kind: textField},
+		"created_at":  {column: "created_at", kind: timeField, key: func(i models.Inquiry) string { return timeKey(i.CreatedAt) }},
+	},
+	defaultSort: "-created_at",
+	id:          func(i models.Inquiry) uuid.UUID { return i.ID },
+}
+
+// List retrieves a page of inquiries, newest first unless the query sorts otherwise, optionally for a single building.
+func (r *inquiryRepository) List(ctx context.Context, buildingID uuid.UUID, query ListQuery) (Page[models.Inquiry], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Inquiry]{}, err
+	}
+
+	var filter *uuid.UUID
+	if buildingID != uuid.Nil {
+		filter = &buildingID
+	}
+	return selectPage(ctx, executor(ctx, r.db), inquirySchema, `SELECT id, building_id, name, email, phone, message, source, created_at FROM inquiries
+	          WHERE tenant_id = $1 AND ($2::uuid IS NULL OR building_id = $2)`, []any{tenantID, filter}, query)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/models"
@@ -17,8 +18,15 @@ import (
 type NeighborhoodRepository interface {
 	Save(ctx context.Context, neighborhood models.Neighborhood) error
 	GetByID(ctx context.Context, id string) (models.Neighborhood, error)
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context) ([]models.Neighborhood, error)
+	GetListed(ctx context.Context, id string) (models.Neighborhood, error)
+	Delete(ctx context.Context, id string, version int64) ([]models.Building, []models.Apartment, error)
+	Restore(ctx context.Context, id string) (models.Neighborhood, []models.Building, []models.Apartment, error)
+	CountDependents(ctx context.Context, id string) (models.NeighborhoodDependents, error)
+	MoveBuildings(ctx context.Context, fromID string, toID string) ([]models.Building, error)
+	FindByName(ctx context.Context, name string) ([]models.Neighborhood, error)
+	List(ctx context.Context, query ListQuery) (Page[models.Neighborhood], error)
+	ListListed(ctx context.Context, query ListQuery) (Page[models.Neighborhood], error)
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 // neighborhoodRepository implements NeighborhoodRepository.
@@ -31,11 +39,19 @@ func NewNeighborhoodRepository(db *sqlx.DB) NeighborhoodRepository {
 	return &neighborhoodRepository{db: db}
 }
 
-// Save inserts or updates a neighborhood in the database.
+// Save inserts or updates a neighborhood in the database. Neighborhood.Version is the
+// version being written: an existing row is only replaced by its next version,
+// so a change based on a stale read fails with ErrVersionConflict.
 func (r *neighborhoodRepository) Save(ctx context.Context, neighborhood models.Neighborhood) error {
-	query := `INSERT INTO neighborhoods (id, name) VALUES ($1, $2)
-	          ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name`
-	_, err := r.db.ExecContext(ctx, query, neighborhood.ID, neighborhood.Name)
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO neighborhoods (id, tenant_id, name, version, updated_at) VALUES ($1, $2, $3, $4, $5)
+	          ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, version = EXCLUDED.version, updated_at = EXCLUDED.updated_at
+	          WHERE neighborhoods.tenant_id = EXCLUDED.tenant_id AND neighborhoods.version = EXCLUDED.version - 1`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, neighborhood.ID, tenantID, neighborhood.Name, neighborhood.Version, neighborhood.UpdatedAt)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
@@ -43,19 +59,37 @@ func (r *neighborhoodRepository) Save(ctx context.Context, neighborhood models.N
 		}
 		return err
 	}
-	return nil
+	return checkVersioned(result)
 }
 
+// listedNeighborhood is the condition for a neighborhood to be listed on the
+// public website: one of its live buildings has an available apartment.
+const listedNeighborhood = `EXISTS (SELECT 1 FROM buildings b JOIN apartments a ON a.building_id = b.id
+	WHERE b.neighborhood_id = neighborhoods.id AND b.tenant_id = neighborhoods.tenant_id AND b.deleted_at IS NULL AND a.available AND a.deleted_at IS NULL)`
+
 // GetByID retrieves a neighborhood by ID.
 func (r *neighborhoodRepository) GetByID(ctx context.Context, id string) (models.Neighborhood, error) {
+	return r.get(ctx, id, `SELECT id, name, version, updated_at FROM neighborhoods WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`)
+}
+
+// GetListed retrieves a neighborhood by ID if it has an available apartment.
+func (r *neighborhoodRepository) GetListed(ctx context.Context, id string) (models.Neighborhood, error) {
+	return r.get(ctx, id, `SELECT id, name, version, updated_at FROM neighborhoods WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL AND `+listedNeighborhood)
+}
+
+// get runs query, selecting one neighborhood by the id and tenant given as $1 and $2.
+func (r *neighborhoodRepository) get(ctx context.Context, id string, query string) (models.Neighborhood, error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
 		return models.Neighborhood{}, apperrors.ErrInvalidID
 	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
 
 	var neighborhood models.Neighborhood
-	query := `SELECT id, name FROM neighborhoods WHERE id = $1`
-	err = r.db.GetContext(ctx, &neighborhood, query, parsedID)
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &neighborhood, query, parsedID, tenantID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Neighborhood{}, apperrors.ErrNotFound
@@ -65,32 +99,189 @@ func (r *neighborhoodRepository) GetByID(ctx context.Context, id string) (models
 	return neighborhood, nil
 }
 
-// Delete removes a neighborhood by ID.
-func (r *neighborhoodRepository) Delete(ctx context.Context, id string) error {
+// Delete moves a neighborhood to the trash, with its buildings and their
+// apartments, if it is still at the given version, and returns the buildings
+// and apartments as they were before. Rows trashed together share one
+// deleted_at, which is how Restore finds them again.
+func (r *neighborhoodRepository) Delete(ctx context.Context, id string, version int64) ([]models.Building, []models.Apartment, error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
-		return apperrors.ErrInvalidID
+		return nil, nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	query := `DELETE FROM neighborhoods WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, parsedID)
+	var deletedAt time.Time
+	query := `UPDATE neighborhoods SET deleted_at = now(), version = version + 1, updated_at = now()
+	          WHERE id = $1 AND tenant_id = $2 AND version = $3 AND deleted_at IS NULL
+	          RETURNING deleted_at`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &deletedAt, query, parsedID, tenantID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, versionMismatch(ctx, r.db, "neighborhoods", parsedID, tenantID)
+	}
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	buildings := []models.Building{}
+	query = `UPDATE buildings b SET deleted_at = $3, version = b.version + 1, updated_at = now()
+	         FROM buildings old
+	         WHERE old.id = b.id AND b.neighborhood_id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL
+	         RETURNING old.id, old.name, old.neighborhood_id, old.address, old.version, old.updated_at`
+	if err := sqlx.SelectContext(ctx, executor(ctx, r.db), &buildings, query, parsedID, tenantID, deletedAt); err != nil {
+		return nil, nil, err
 	}
-	rowsAffected, err := result.RowsAffected()
+	buildingIDs := make([]uuid.UUID, len(buildings))
+	for i, building := range buildings {
+		buildingIDs[i] = building.ID
+	}
+	apartments, err := trashApartments(ctx, executor(ctx, r.db), tenantID, buildingIDs, deletedAt)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	return buildings, apartments, nil
+}
+
+// Restore takes a neighborhood out of the trash together with the buildings and
+// apartments trashed with it, and returns it and the restored buildings and
+// apartments.
+func (r *neighborhoodRepository) Restore(ctx context.Context, id string) (models.Neighborhood, []models.Building, []models.Apartment, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Neighborhood{}, nil, nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.Neighborhood{}, nil, nil, err
+	}
+
+	var restored struct {
+		models.Neighborhood
+		DeletedAt time.Time `db:"deleted_at"`
+	}
+	query := `UPDATE neighborhoods n SET deleted_at = NULL, version = n.version + 1, updated_at = now()
+	          FROM (SELECT id, deleted_at FROM neighborhoods WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL FOR UPDATE) trashed
+	          WHERE n.id = trashed.id
+	          RETURNING n.id, n.name, n.version, n.updated_at, trashed.deleted_at`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &restored, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Neighborhood{}, nil, nil, apperrors.ErrNotFound
+		}
+		return models.Neighborhood{}, nil, nil, err
+	}
+
+	buildings := []models.Building{}
+	query = `UPDATE buildings SET deleted_at = NULL, version = version + 1, updated_at = now()
+	         WHERE neighborhood_id = $1 AND tenant_id = $2 AND deleted_at = $3
+	         RETURNING id, name, neighborhood_id, address, version, updated_at`
+	if err := sqlx.SelectContext(ctx, executor(ctx, r.db), &buildings, query, parsedID, tenantID, restored.DeletedAt); err != nil {
+		return models.Neighborhood{}, nil, nil, err
 	}
-	if rowsAffected == 0 {
-		return apperrors.ErrNotFound
+
+	apartments := []models.Apartment{}
+	query = `UPDATE apartments SET deleted_at = NULL, version = version + 1, updated_at = now()
+	         WHERE tenant_id = $2 AND deleted_at = $3 AND building_id IN (SELECT id FROM buildings WHERE neighborhood_id = $1 AND tenant_id = $2)
+	         RETURNING ` + apartmentColumns
+	if err := sqlx.SelectContext(ctx, executor(ctx, r.db), &apartments, query, parsedID, tenantID, restored.DeletedAt); err != nil {
+		return models.Neighborhood{}, nil, nil, err
+	}
+	return restored.Neighborhood, buildings, apartments, nil
+}
+
+// CountDependents counts the buildings of a neighborhood and their apartments,
+// leaving out those in the trash.
+func (r *neighborhoodRepository) CountDependents(ctx context.Context, id string) (models.NeighborhoodDependents, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.NeighborhoodDependents{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.NeighborhoodDependents{}, err
+	}
+
+	var dependents models.NeighborhoodDependents
+	query := `SELECT count(DISTINCT b.id) AS buildings, count(a.id) AS apartments
+	          FROM buildings b LEFT JOIN apartments a ON a.building_id = b.id AND a.deleted_at IS NULL
+	          WHERE b.neighborhood_id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &dependents, query, parsedID, tenantID)
+	return dependents, err
+}
+
+// MoveBuildings moves every building of one neighborhood, except those in the
+// trash, to another and returns them as saved.
+func (r *neighborhoodRepository) MoveBuildings(ctx context.Context, fromID string, toID string) ([]models.Building, error) {
+	fromUUID, err := uuid.Parse(fromID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidID
 	}
-	return nil
+	toUUID, err := uuid.Parse(toID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buildings := []models.Building{}
+	query := `UPDATE buildings SET neighborhood_id = $2, version = version + 1, updated_at = now()
+	          WHERE neighborhood_id = $1 AND tenant_id = $3 AND deleted_at IS NULL
+	          RETURNING id, name, neighborhood_id, address, version, updated_at`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &buildings, query, fromUUID, toUUID, tenantID)
+	return buildings, err
 }
 
-// List retrieves all neighborhoods.
-func (r *neighborhoodRepository) List(ctx context.Context) ([]models.Neighborhood, error) {
-	var neighborhoods []models.Neighborhood
-	query := `SELECT id, name FROM neighborhoods ORDER BY name`
-	err := r.db.SelectContext(ctx, &neighborhoods, query)
+// FindByName retrieves the neighborhoods whose name matches name, ignoring case.
+// Names are not unique, so there may be more than one.
+func (r *neighborhoodRepository) FindByName(ctx context.Context, name string) ([]models.Neighborhood, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	neighborhoods := []models.Neighborhood{}
+	query := `SELECT id, name, version, updated_at FROM neighborhoods WHERE lower(name) = lower($1) AND tenant_id = $2 AND deleted_at IS NULL ORDER BY id`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &neighborhoods, query, name, tenantID)
 	return neighborhoods, err
 }
+
+// neighborhoodSchema lists the fields neighborhoods can be filtered and sorted by.
+var neighborhoodSchema = listSchema[models.Neighborhood]{
+	fields: map[string]field[models.Neighborhood]{
+		"id":         {column: "id", kind: uuidField},
+		"name":       {column: "name", kind: textField, key: func(n models.Neighborhood) string { return n.Name }},
+		"updated_at": {column: "updated_at", kind: timeField, key: func(n models.Neighborhood) string { return timeKey(n.UpdatedAt) }},
+	},
+	defaultSort: "name",
+	id:          func(n models.Neighborhood) uuid.UUID { return n.ID },
+}
+
+// List retrieves a page of neighborhoods, ordered by name unless the query sorts otherwise.
+func (r *neighborhoodRepository) List(ctx context.Context, query ListQuery) (Page[models.Neighborhood], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Neighborhood]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), neighborhoodSchema, `SELECT id, name, version, updated_at FROM neighborhoods WHERE tenant_id = $1 AND deleted_at IS NULL`, []any{tenantID}, query)
+}
+
+// ListListed retrieves a page of the neighborhoods that have an available
+// apartment, ordered by name unless the query sorts otherwise.
+func (r *neighborhoodRepository) ListListed(ctx context.Context, query ListQuery) (Page[models.Neighborhood], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.Neighborhood]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), neighborhoodSchema, `SELECT id, name, version, updated_at FROM neighborhoods WHERE tenant_id = $1 AND deleted_at IS NULL AND `+listedNeighborhood, []any{tenantID}, query)
+}
+
+// LastModified returns when a neighborhood was last saved, deleted or restored.
+func (r *neighborhoodRepository) LastModified(ctx context.Context) (time.Time, error) {
+	return lastModified(ctx, r.db, "neighborhoods")
+}
@@ -0,0 +1,69 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// NotificationRepository broadcasts messages to every instance listening on a
+// Postgres notification channel.
+type NotificationRepository interface {
+	Notify(ctx context.Context, channel string, payload string) error
+}
+
+// notificationRepository implements NotificationRepository.
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepository creates a new notification repository.
+func NewNotificationRepository(db *sqlx.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Notify sends payload on channel. Within a transaction the notification is
+// only delivered on commit, and not at all on rollback.
+func (r *notificationRepository) Notify(ctx context.Context, channel string, payload string) error {
+	_, err := executor(ctx, r.db).ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
+	return err
+}
+
+// Listen subscribes to channel and calls handle with the payload of every
+// notification until ctx is cancelled. It returns once the subscription is in
+// place. Notifications sent while the connection was down are lost, so reset
+// is called after a reconnect for the listener to drop what it may have missed.
+func Listen(ctx context.Context, dataSourceName string, channel string, logger *zap.Logger, handle func(payload string), reset func()) error {
+	listener := pq.NewListener(dataSourceName, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Notification listener connection changed", zap.String("channel", channel), zap.Int("event", int(event)), zap.Error(err))
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification := <-listener.Notify:
+				if notification == nil {
+					reset()
+					continue
+				}
+				handle(notification.Extra)
+			case <-time.After(time.Minute):
+				go listener.Ping()
+			}
+		}
+	}()
+	return nil
+}
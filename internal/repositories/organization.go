@@ -0,0 +1,92 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// OrganizationRepository defines the interface for organization data operations.
+// Organizations are the tenants themselves, so queries are not tenant-scoped.
+type OrganizationRepository interface {
+	Save(ctx context.Context, organization models.Organization) error
+	GetByID(ctx context.Context, id string) (models.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (models.Organization, error)
+	List(ctx context.Context, query ListQuery) (Page[models.Organization], error)
+}
+
+// organizationRepository implements OrganizationRepository.
+type organizationRepository struct {
+	db *sqlx.DB
+}
+
+// NewOrganizationRepository creates a new organization repository.
+func NewOrganizationRepository(db *sqlx.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// Save inserts or updates an organization in the database.
+func (r *organizationRepository) Save(ctx context.Context, organization models.Organization) error {
+	query := `INSERT INTO organizations (id, slug, name, created_at) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (id) DO UPDATE SET slug = EXCLUDED.slug, name = EXCLUDED.name`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, organization.ID, organization.Slug, organization.Name, organization.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return apperrors.ErrInvalidInput
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves an organization by ID.
+func (r *organizationRepository) GetByID(ctx context.Context, id string) (models.Organization, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Organization{}, apperrors.ErrInvalidID
+	}
+
+	return r.get(ctx, `SELECT id, slug, name, created_at FROM organizations WHERE id = $1`, parsedID)
+}
+
+// GetBySlug retrieves an organization by slug.
+func (r *organizationRepository) GetBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	return r.get(ctx, `SELECT id, slug, name, created_at FROM organizations WHERE slug = $1`, slug)
+}
+
+// get runs a query returning a single organization.
+func (r *organizationRepository) get(ctx context.Context, query string, args ...any) (models.Organization, error) {
+	var organization models.Organization
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &organization, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, apperrors.ErrNotFound
+		}
+		return models.Organization{}, err
+	}
+	return organization, nil
+}
+
+// organizationSchema lists the fields organizations can be filtered and sorted by.
+var organizationSchema = listSchema[models.Organization]{
+	fields: map[string]field[models.Organization]{
+		"slug":       {column: "slug", kind: textField, key: func(o models.Organization) string { return o.Slug }},
+		"name":       {column: "name", kind: textField, key: func(o models.Organization) string { return o.Name }},
+		"created_at": {column: "created_at", kind: timeField, key: func(o models.Organization) string { return timeKey(o.CreatedAt) }},
+	},
+	defaultSort: "name",
+	id:          func(o models.Organization) uuid.UUID { return o.ID },
+}
+
+// List retrieves a page of organizations, ordered by name unless the query sorts otherwise.
+func (r *organizationRepository) List(ctx context.Context, query ListQuery) (Page[models.Organization], error) {
+	return selectPage(ctx, executor(ctx, r.db), organizationSchema, `SELECT id, slug, name, created_at FROM organizations WHERE TRUE`, nil, query)
+}
@@ -0,0 +1,76 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// outboxLockKey is the advisory lock held by the instance dispatching the outbox.
+const outboxLockKey = 727274001
+
+// OutboxRepository defines the interface for outbox data operations.
+type OutboxRepository interface {
+	Append(ctx context.Context, event models.DomainEvent) error
+	TryLock(ctx context.Context) (bool, error)
+	ListPending(ctx context.Context, limit int) ([]models.DomainEvent, error)
+	MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error
+	RecordFailure(ctx context.Context, event models.DomainEvent) error
+}
+
+// outboxRepository implements OutboxRepository.
+type outboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewOutboxRepository creates a new outbox repository.
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Append inserts a domain event into the outbox. Call it with the context of the
+// transaction that performs the change so both commit together.
+func (r *outboxRepository) Append(ctx context.Context, event models.DomainEvent) error {
+	query := `INSERT INTO outbox (id, tenant_id, event_type, aggregate_type, aggregate_id, payload, occurred_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, event.ID, event.TenantID, event.Type, event.AggregateType, event.AggregateID, event.Payload, event.OccurredAt)
+	return err
+}
+
+// TryLock takes the transaction-scoped dispatch lock so only one instance
+// publishes at a time. It must be called inside a transaction.
+func (r *outboxRepository) TryLock(ctx context.Context) (bool, error) {
+	var locked bool
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &locked, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey)
+	return locked, err
+}
+
+// ListPending retrieves unpublished events in the order of the transactions
+// that wrote them, and in the order written within one. Events are left out
+// while any transaction older than theirs is still running, since it may yet
+// commit events that come first. Parked events are left out too.
+func (r *outboxRepository) ListPending(ctx context.Context, limit int) ([]models.DomainEvent, error) {
+	var events []models.DomainEvent
+	query := `SELECT id, tenant_id, seq, event_type, aggregate_type, aggregate_id, payload, occurred_at, published_at, attempts, last_error, next_attempt_at, parked_at
+	          FROM outbox WHERE published_at IS NULL AND parked_at IS NULL AND txid < pg_snapshot_xmin(pg_current_snapshot())
+	          ORDER BY txid, seq LIMIT $1`
+	err := sqlx.SelectContext(ctx, executor(ctx, r.db), &events, query, limit)
+	return events, err
+}
+
+// MarkPublished records that an event has been handed to every subscriber.
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	_, err := executor(ctx, r.db).ExecContext(ctx, `UPDATE outbox SET published_at = $2 WHERE id = $1`, id, publishedAt)
+	return err
+}
+
+// RecordFailure records a failed dispatch of an event: its attempts, last
+// error, and when it is retried or that it was parked.
+func (r *outboxRepository) RecordFailure(ctx context.Context, event models.DomainEvent) error {
+	query := `UPDATE outbox SET attempts = $2, last_error = $3, next_attempt_at = $4, parked_at = $5 WHERE id = $1`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, event.ID, event.Attempts, event.LastError, event.NextAttemptAt, event.ParkedAt)
+	return err
+}
@@ -0,0 +1,57 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+)
+
+// MaxPageSize caps the number of rows read per page, whatever the Limit.
+const MaxPageSize = 200
+
+// ListQuery filters, orders and pages a list. A zero Limit reads a page of
+// MaxPageSize rows; an empty Sort uses the list's default order.
+type ListQuery struct {
+	Filters []Filter
+	Sort    string
+	Limit   int
+	Cursor  string
+}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// cursor is the position of the last row of a page: the order it was read in,
+// its sort key and its ID. It is handed to clients base64-encoded so they treat
+// it as opaque.
+type cursor struct {
+	Sort string    `json:"s"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// encodeCursor returns the opaque form of c.
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor returned by encodeCursor for a list read in the
+// given order. Cursors from a list with a different sort are rejected.
+func decodeCursor(value string, sort string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor{}, apperrors.NewParamError("cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.Sort != sort {
+		return cursor{}, apperrors.NewParamError("cursor")
+	}
+	return c, nil
+}
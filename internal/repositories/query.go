@@ -0,0 +1,256 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Operator compares a field with a filter value.
+type Operator string
+
+// Filter operators
+const (
+	OpEq       Operator = "eq"
+	OpContains Operator = "contains"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+)
+
+// Filter is one filter[field][operator]=value condition. Param is the query
+// parameter it was read from, reported back when the filter is rejected.
+type Filter struct {
+	Field    string
+	Operator Operator
+	Value    string
+	Param    string
+}
+
+// fieldKind is the type of a filterable field; it decides which operators apply
+// and how values are parsed.
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	uuidField
+	intField
+	timeField
+	boolField
+)
+
+// operators lists the operators each field kind supports.
+var operators = map[fieldKind][]Operator{
+	textField: {OpEq, OpContains},
+	uuidField: {OpEq},
+	intField:  {OpEq, OpGt, OpGte, OpLt, OpLte},
+	timeField: {OpEq, OpGt, OpGte, OpLt, OpLte},
+	boolField: {OpEq},
+}
+
+// sqlOperators maps comparison operators to SQL.
+var sqlOperators = map[Operator]string{OpEq: "=", OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<="}
+
+// field is a column a list may be filtered by. Fields with a key function can
+// also be sorted by; key returns the row's value formatted for a cursor.
+type field[T any] struct {
+	column string
+	kind   fieldKind
+	key    func(T) string
+}
+
+// listSchema whitelists the fields of a list. Only columns named here ever reach
+// SQL, so filters and sorts from the query string cannot inject anything.
+type listSchema[T any] struct {
+	fields      map[string]field[T]
+	defaultSort string
+	id          func(T) uuid.UUID
+}
+
+// selectPage runs base, a SELECT whose WHERE clause is already started, with
+// the query's filters, order and keyset cursor applied, and returns the page.
+func selectPage[T any](ctx context.Context, db sqlx.QueryerContext, schema listSchema[T], base string, args []any, query ListQuery) (Page[T], error) {
+	sort, sortField, desc, err := schema.sortBy(query.Sort)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	sql, args, err := schema.filter(base, args, query.Filters)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	direction, operator := "ASC", ">"
+	if desc {
+		direction, operator = "DESC", "<"
+	}
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor, sort)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		key, err := parseValue(sortField.kind, c.Key)
+		if err != nil {
+			return Page[T]{}, apperrors.NewParamError("cursor")
+		}
+		args = append(args, key, c.ID)
+		sql += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortField.column, operator, len(args)-1, len(args))
+	}
+	sql += fmt.Sprintf(" ORDER BY %s %s, id %s", sortField.column, direction, direction)
+	limit := query.Limit
+	if limit <= 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	// Fetch one extra row to learn whether there is a next page
+	args = append(args, limit+1)
+	sql += " LIMIT $" + strconv.Itoa(len(args))
+
+	items := []T{}
+	if err := sqlx.SelectContext(ctx, db, &items, sql, args...); err != nil {
+		return Page[T]{}, err
+	}
+	if len(items) <= limit {
+		return Page[T]{Items: items}, nil
+	}
+
+	items = items[:limit]
+	last := items[len(items)-1]
+	next := encodeCursor(cursor{Sort: sort, Key: sortField.key(last), ID: schema.id(last)})
+	return Page[T]{Items: items, NextCursor: next}, nil
+}
+
+// streamBatchSize is the number of rows streamRows fetches at a time.
+const streamBatchSize = 500
+
+// streamRows runs base with the query's filters and order applied, like
+// selectPage without paging, and calls fn with each row read as R. Rows are
+// fetched streamBatchSize at a time from a server-side cursor, so memory use
+// does not grow with the result. It must run inside a transaction.
+func streamRows[T, R any](ctx context.Context, db sqlx.ExtContext, schema listSchema[T], base string, args []any, query ListQuery, fn func(R) error) error {
+	_, sortField, desc, err := schema.sortBy(query.Sort)
+	if err != nil {
+		return err
+	}
+	sql, args, err := schema.filter(base, args, query.Filters)
+	if err != nil {
+		return err
+	}
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	sql += fmt.Sprintf(" ORDER BY %s %s, id %s", sortField.column, direction, direction)
+
+	if _, err := db.ExecContext(ctx, "DECLARE stream_cursor NO SCROLL CURSOR FOR "+sql, args...); err != nil {
+		return err
+	}
+	fetch := "FETCH FORWARD " + strconv.Itoa(streamBatchSize) + " FROM stream_cursor"
+	for {
+		var rows []R
+		if err := sqlx.SelectContext(ctx, db, &rows, fetch); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < streamBatchSize {
+			break
+		}
+	}
+	_, err = db.ExecContext(ctx, "CLOSE stream_cursor")
+	return err
+}
+
+// sortBy resolves a sort parameter, or the default order when it is empty, to
+// the sort in effect, the field it orders by and whether it is descending.
+func (s listSchema[T]) sortBy(sort string) (string, field[T], bool, error) {
+	if sort == "" {
+		sort = s.defaultSort
+	}
+	name, desc := strings.CutPrefix(sort, "-")
+	sortField, ok := s.fields[name]
+	if !ok || sortField.key == nil {
+		return "", field[T]{}, false, apperrors.NewParamError("sort")
+	}
+	return sort, sortField, desc, nil
+}
+
+// filter appends a condition for each filter to sql, whose WHERE clause is
+// already started, and its value to args.
+func (s listSchema[T]) filter(sql string, args []any, filters []Filter) (string, []any, error) {
+	for _, filter := range filters {
+		condition, value, err := s.condition(filter)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, value)
+		sql += " AND " + fmt.Sprintf(condition, "$"+strconv.Itoa(len(args)))
+	}
+	return sql, args, nil
+}
+
+// condition returns the SQL condition for a filter, with %s standing for the
+// placeholder of its value, and the parsed value.
+func (s listSchema[T]) condition(filter Filter) (string, any, error) {
+	f, ok := s.fields[filter.Field]
+	if !ok {
+		return "", nil, apperrors.NewParamError(filter.Param)
+	}
+	operator := filter.Operator
+	if operator == "" {
+		operator = OpEq
+	}
+	if !slices.Contains(operators[f.kind], operator) {
+		return "", nil, apperrors.NewParamError(filter.Param)
+	}
+
+	if operator == OpContains {
+		return f.column + ` ILIKE %s`, "%" + escapeLike(filter.Value) + "%", nil
+	}
+	value, err := parseValue(f.kind, filter.Value)
+	if err != nil {
+		return "", nil, apperrors.NewParamError(filter.Param)
+	}
+	return f.column + " " + sqlOperators[operator] + " %s", value, nil
+}
+
+// parseValue converts a query string value to the Go type of the field kind.
+func parseValue(kind fieldKind, value string) (any, error) {
+	switch kind {
+	case uuidField:
+		return uuid.Parse(value)
+	case intField:
+		return strconv.ParseInt(value, 10, 64)
+	case timeField:
+		return time.Parse(time.RFC3339Nano, value)
+	case boolField:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in a literal search term.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// timeKey formats a timestamp sort key for a cursor.
+func timeKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// intKey formats an integer sort key for a cursor.
+func intKey(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,84 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// rateLimitIdle is how long a bucket goes unused before it is deleted. Any
+// quota that refills within it is full by then, the same as a missing bucket.
+const rateLimitIdle = time.Hour
+
+// RateLimitRepository keeps token buckets in Postgres so that every server
+// instance counts against the same limits.
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, quota models.RateLimitQuota) (models.RateLimitStatus, error)
+}
+
+// rateLimitRepository implements RateLimitRepository.
+type rateLimitRepository struct {
+	db *sqlx.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewRateLimitRepository creates a new rate limit repository.
+func NewRateLimitRepository(db *sqlx.DB) RateLimitRepository {
+	return &rateLimitRepository{db: db, lastSweep: time.Now()}
+}
+
+// Take refills the bucket named key and takes a token from it if one is left.
+// A new bucket starts full.
+func (r *rateLimitRepository) Take(ctx context.Context, key string, quota models.RateLimitQuota) (models.RateLimitStatus, error) {
+	if err := r.sweep(ctx); err != nil {
+		return models.RateLimitStatus{}, err
+	}
+
+	var bucket struct {
+		Tokens  float64 `db:"tokens"`
+		Allowed bool    `db:"allowed"`
+	}
+	update := `UPDATE rate_limit_buckets b SET tokens = current.tokens - CASE WHEN current.tokens >= 1 THEN 1 ELSE 0 END, updated_at = now()
+	           FROM (SELECT key, LEAST($3::double precision, tokens + EXTRACT(EPOCH FROM now() - updated_at)::double precision * $2::double precision) AS tokens
+	                 FROM rate_limit_buckets WHERE key = $1 FOR UPDATE) current
+	           WHERE b.key = current.key
+	           RETURNING b.tokens, current.tokens >= 1 AS allowed`
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &bucket, update, key, quota.Rate, quota.Burst)
+	if errors.Is(err, sql.ErrNoRows) {
+		insert := `INSERT INTO rate_limit_buckets (key, tokens) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING RETURNING tokens, true AS allowed`
+		err = sqlx.GetContext(ctx, executor(ctx, r.db), &bucket, insert, key, quota.Burst-1)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another instance created the bucket first
+			err = sqlx.GetContext(ctx, executor(ctx, r.db), &bucket, update, key, quota.Rate, quota.Burst)
+		}
+	}
+	if err != nil {
+		return models.RateLimitStatus{}, err
+	}
+	return models.NewRateLimitStatus(quota, bucket.Tokens, bucket.Allowed), nil
+}
+
+// sweep deletes idle buckets, at most once per rateLimitIdle per instance.
+func (r *rateLimitRepository) sweep(ctx context.Context) error {
+	r.mu.Lock()
+	due := time.Since(r.lastSweep) >= rateLimitIdle
+	if due {
+		r.lastSweep = time.Now()
+	}
+	r.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	query := `DELETE FROM rate_limit_buckets WHERE updated_at < $1`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, time.Now().Add(-rateLimitIdle))
+	return err
+}
@@ -0,0 +1,72 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshTokenRepository defines the interface for refresh token data operations.
+type RefreshTokenRepository interface {
+	Save(ctx context.Context, token models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error
+}
+
+// refreshTokenRepository implements RefreshTokenRepository.
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository.
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Save inserts a refresh token in the database.
+func (r *refreshTokenRepository) Save(ctx context.Context, token models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, revoked_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, token.ID, token.UserID, token.TokenHash, token.ExpiresAt, token.RevokedAt, token.CreatedAt)
+	return err
+}
+
+// GetByHash retrieves a refresh token by the hash of its value.
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	query := `SELECT id, user_id, token_hash, expires_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &token, query, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, apperrors.ErrNotFound
+		}
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// Revoke marks a refresh token as revoked. It reports false when the token
+// was already revoked, which includes a concurrent call revoking it first.
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) (bool, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		return false, err
+	}
+	revoked, err := result.RowsAffected()
+	return revoked > 0, err
+}
+
+// RevokeAllForUser revokes every active refresh token of a user.
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, userID, revokedAt)
+	return err
+}
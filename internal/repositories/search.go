@@ -0,0 +1,155 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxSearchTerms caps the number of terms taken from a query.
+	maxSearchTerms = 10
+	// highlightStart and highlightStop delimit matches in ts_headline output.
+	// They are control characters so they cannot clash with stored text, and
+	// are swapped for <mark> tags after the text is HTML-escaped.
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// headlineOptions configures ts_headline to highlight matches in the whole value.
+const headlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true"
+
+// searchQuery ranks neighborhoods and buildings of one tenant against a query.
+// A row matches when any term is a prefix of a word in it (full-text) or when a
+// term is similar to one of its words (trigrams), so misspellings still match.
+// The score adds the full-text rank to the average best word similarity of the terms.
+const searchQuery = `
+WITH q AS (SELECT to_tsquery('simple', $2) AS tsq, $3::text[] AS terms)
+SELECT 'neighborhood' AS type, n.id, n.name AS title, '' AS subtitle,
+       ts_headline('simple', n.name, q.tsq, $4) AS snippet,
+       ts_rank(n.search_vector, q.tsq) + s.name_score AS score
+FROM neighborhoods n, q,
+     LATERAL (SELECT coalesce(avg(word_similarity(t, n.name)), 0) AS name_score FROM unnest(q.terms) t) s
+WHERE n.tenant_id = $1 AND n.deleted_at IS NULL
+  AND (n.search_vector @@ q.tsq OR EXISTS (SELECT 1 FROM unnest(q.terms) t WHERE t <% n.name))
+UNION ALL
+SELECT CASE WHEN s.name_score >= s.address_score THEN 'building' ELSE 'address' END AS type,
+       b.id,
+       CASE WHEN s.name_score >= s.address_score THEN b.name ELSE b.address END AS title,
+       CASE WHEN s.name_score >= s.address_score THEN b.address ELSE b.name END AS subtitle,
+       ts_headline('simple', CASE WHEN s.name_score >= s.address_score THEN b.name ELSE b.address END, q.tsq, $4) AS snippet,
+       ts_rank(b.search_vector, q.tsq) + greatest(s.name_score, s.address_score) AS score
+FROM buildings b, q,
+     LATERAL (SELECT coalesce(avg(word_similarity(t, b.name)), 0) AS name_score,
+                     coalesce(avg(word_similarity(t, b.address)), 0) AS address_score
+              FROM unnest(q.terms) t) s
+WHERE b.tenant_id = $1 AND b.deleted_at IS NULL
+  AND (b.search_vector @@ q.tsq OR EXISTS (SELECT 1 FROM unnest(q.terms) t WHERE t <% b.name OR t <% b.address))
+ORDER BY score DESC, id
+LIMIT $5`
+
+// SearchRepository defines the interface for search operations.
+type SearchRepository interface {
+	Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+	Suggest(ctx context.Context, prefix string, types []models.SearchResultType, limit int) ([]models.Suggestion, error)
+}
+
+// searchRepository implements SearchRepository.
+type searchRepository struct {
+	db *sqlx.DB
+}
+
+// NewSearchRepository creates a new search repository.
+func NewSearchRepository(db *sqlx.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Search returns the neighborhoods, buildings and addresses of the tenant in ctx
+// best matching query, highest score first.
+func (r *searchRepository) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []models.SearchResult{}, nil
+	}
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+
+	results := []models.SearchResult{}
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &results, searchQuery,
+		tenantID, strings.Join(prefixes, " | "), pq.StringArray(terms), headlineOptions, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = highlight(results[i].Snippet)
+	}
+	return results, nil
+}
+
+// suggestSources maps suggestion types to the column they complete.
+var suggestSources = map[models.SearchResultType]string{
+	models.SearchNeighborhood: `SELECT 'neighborhood' AS type, id, name AS text FROM neighborhoods WHERE tenant_id = $1 AND deleted_at IS NULL`,
+	models.SearchBuilding:     `SELECT 'building' AS type, id, name AS text FROM buildings WHERE tenant_id = $1 AND deleted_at IS NULL`,
+	models.SearchAddress:      `SELECT 'address' AS type, id, address AS text FROM buildings WHERE tenant_id = $1 AND deleted_at IS NULL`,
+}
+
+// Suggest returns the records of the tenant in ctx with a word starting with
+// prefix, those whose text starts with it first. It serves autocomplete while
+// the in-memory index is cold.
+func (r *searchRepository) Suggest(ctx context.Context, prefix string, types []models.SearchResultType, limit int) ([]models.Suggestion, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := make([]string, 0, len(types))
+	for _, t := range types {
+		selects = append(selects, suggestSources[t])
+	}
+	query := `SELECT type, id, text FROM (` + strings.Join(selects, " UNION ALL ") + `) s
+	          WHERE lower(text) LIKE $2 OR lower(text) LIKE $3
+	          ORDER BY lower(text) NOT LIKE $2, lower(text), id LIMIT $4`
+
+	pattern := escapeLike(strings.ToLower(prefix)) + "%"
+	suggestions := []models.Suggestion{}
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &suggestions, query, tenantID, pattern, "% "+pattern, limit)
+	return suggestions, err
+}
+
+// searchTerms splits a query into lowercase words of letters and digits. Other
+// characters never reach to_tsquery, so user input cannot alter its syntax.
+// Single characters are dropped since they would prefix-match nearly everything.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) < 2 {
+			continue
+		}
+		terms = append(terms, word)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// highlight HTML-escapes a ts_headline snippet and turns its match delimiters into <mark> tags.
+func highlight(snippet string) string {
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,21 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/google/uuid"
+)
+
+// tenantScope returns the tenant ctx is scoped to. Every query on tenant-owned
+// rows filters by it, so a context without a tenant is refused rather than
+// allowed to read across tenants.
+func tenantScope(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return uuid.Nil, apperrors.ErrForbidden
+	}
+	return tenantID, nil
+}
@@ -0,0 +1,80 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// trashQuery lists the trashed neighborhoods, buildings and apartments of tenant $1.
+const trashQuery = `SELECT type, id, label, parent_id, deleted_at FROM (
+	SELECT 'neighborhood' AS type, id, name AS label, NULL::uuid AS parent_id, deleted_at
+	FROM neighborhoods WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'building', id, name, neighborhood_id, deleted_at
+	FROM buildings WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'apartment', id, type, building_id, deleted_at
+	FROM apartments WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+) trash WHERE TRUE`
+
+// TrashRepository defines the interface for reading and emptying the trash.
+type TrashRepository interface {
+	List(ctx context.Context, query ListQuery) (Page[models.TrashItem], error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// trashRepository implements TrashRepository.
+type trashRepository struct {
+	db *sqlx.DB
+}
+
+// NewTrashRepository creates a new trash repository.
+func NewTrashRepository(db *sqlx.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// trashSchema lists the fields the trash can be filtered and sorted by.
+var trashSchema = listSchema[models.TrashItem]{
+	fields: map[string]field[models.TrashItem]{
+		"type":       {column: "type", kind: textField},
+		"parent_id":  {column: "parent_id", kind: uuidField},
+		"label":      {column: "label", kind: textField, key: func(t models.TrashItem) string { return t.Label }},
+		"deleted_at": {column: "deleted_at", kind: timeField, key: func(t models.TrashItem) string { return timeKey(t.DeletedAt) }},
+	},
+	defaultSort: "-deleted_at",
+	id:          func(t models.TrashItem) uuid.UUID { return t.ID },
+}
+
+// List retrieves a page of the trash of the tenant in ctx, most recently
+// deleted first unless the query sorts otherwise.
+func (r *trashRepository) List(ctx context.Context, query ListQuery) (Page[models.TrashItem], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.TrashItem]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), trashSchema, trashQuery, []any{tenantID}, query)
+}
+
+// Purge permanently deletes the rows of every tenant trashed before
+// deletedBefore and returns how many were deleted.
+func (r *trashRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	for _, table := range []string{"apartments", "buildings", "neighborhoods"} {
+		result, err := executor(ctx, r.db).ExecContext(ctx, `DELETE FROM `+table+` WHERE deleted_at < $1`, deletedBefore)
+		if err != nil {
+			return purged, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += rowsAffected
+	}
+	return purged, nil
+}
@@ -0,0 +1,79 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxManager runs units of work inside a database transaction.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txKey is the context key under which the active transaction is stored.
+type txKey struct{}
+
+// txManager implements TxManager.
+type txManager struct {
+	db *sqlx.DB
+}
+
+// NewTxManager creates a new transaction manager.
+func NewTxManager(db *sqlx.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// WithinTx begins a transaction, stores it in the context passed to fn and
+// commits when fn succeeds. Repositories called with that context take part in
+// the transaction. Nested calls join the outer transaction.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithinSavepoint runs fn inside a savepoint of the transaction in ctx. When fn
+// fails its changes are rolled back, and the transaction can carry on. Without
+// a transaction in ctx it is WithinTx.
+func (m *txManager) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	if !ok {
+		return m.WithinTx(ctx, fn)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT within_savepoint`); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT within_savepoint`); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT within_savepoint`)
+	return err
+}
+
+// executor returns the transaction stored in ctx, or db when there is none.
+func executor(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,103 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// UserRepository defines the interface for user data operations.
+type UserRepository interface {
+	Save(ctx context.Context, user models.User) error
+	GetByID(ctx context.Context, id string) (models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	List(ctx context.Context, query ListQuery) (Page[models.User], error)
+}
+
+// userRepository implements UserRepository.
+type userRepository struct {
+	db *sqlx.DB
+}
+
+// NewUserRepository creates a new user repository.
+func NewUserRepository(db *sqlx.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// Save inserts or updates a user in the database.
+func (r *userRepository) Save(ctx context.Context, user models.User) error {
+	query := `INSERT INTO users (id, tenant_id, email, password_hash, role, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, password_hash = EXCLUDED.password_hash, role = EXCLUDED.role
+	          WHERE users.tenant_id = EXCLUDED.tenant_id`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, user.ID, user.TenantID, user.Email, user.PasswordHash, user.Role, user.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return apperrors.NewFieldError("email", apperrors.CodeTaken, "is already in use")
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a user by ID in any tenant.
+func (r *userRepository) GetByID(ctx context.Context, id string) (models.User, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.User{}, apperrors.ErrInvalidID
+	}
+
+	var user models.User
+	query := `SELECT id, tenant_id, email, password_hash, role, created_at FROM users WHERE id = $1`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &user, query, parsedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, apperrors.ErrNotFound
+		}
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email address in any tenant. Emails are
+// unique across tenants so they identify the account at login.
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	query := `SELECT id, tenant_id, email, password_hash, role, created_at FROM users WHERE email = $1`
+	err := sqlx.GetContext(ctx, executor(ctx, r.db), &user, query, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, apperrors.ErrNotFound
+		}
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// userSchema lists the fields users can be filtered and sorted by.
+var userSchema = listSchema[models.User]{
+	fields: map[string]field[models.User]{
+		"email":      {column: "email", kind: textField, key: func(u models.User) string { return u.Email }},
+		"role":       {column: "role", kind: textField},
+		"created_at": {column: "created_at", kind: timeField, key: func(u models.User) string { return timeKey(u.CreatedAt) }},
+	},
+	defaultSort: "email",
+	id:          func(u models.User) uuid.UUID { return u.ID },
+}
+
+// List retrieves a page of the users of the tenant in ctx, ordered by email unless the query sorts otherwise.
+func (r *userRepository) List(ctx context.Context, query ListQuery) (Page[models.User], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.User]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), userSchema, `SELECT id, tenant_id, email, password_hash, role, created_at FROM users WHERE tenant_id = $1`, []any{tenantID}, query)
+}
@@ -0,0 +1,57 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// checkVersioned reports ErrVersionConflict when a versioned upsert wrote no
+// row: the stored row was not at the version before the one being saved.
+func checkVersioned(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrVersionConflict
+	}
+	return nil
+}
+
+// versionMismatch explains why a versioned write to table matched no row:
+// ErrVersionConflict if the row exists at another version, ErrNotFound if it
+// does not exist.
+func versionMismatch(ctx context.Context, db *sqlx.DB, table string, id uuid.UUID, tenantID uuid.UUID) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL)`
+	if err := sqlx.GetContext(ctx, executor(ctx, db), &exists, query, id, tenantID); err != nil {
+		return err
+	}
+	if exists {
+		return apperrors.ErrVersionConflict
+	}
+	return apperrors.ErrNotFound
+}
+
+// lastModified returns the latest updated_at of the tenant's rows in table,
+// trashed rows included so that deleting a row counts as a change. It returns
+// the zero time when the table has no rows for the tenant.
+func lastModified(ctx context.Context, db *sqlx.DB, table string) (time.Time, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var updatedAt sql.NullTime
+	query := `SELECT max(updated_at) FROM ` + table + ` WHERE tenant_id = $1`
+	if err := sqlx.GetContext(ctx, executor(ctx, db), &updatedAt, query, tenantID); err != nil {
+		return time.Time{}, err
+	}
+	return updatedAt.Time, nil
+}
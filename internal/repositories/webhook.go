@@ -0,0 +1,284 @@
+// Package repositories provides data access layer implementations.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// WebhookRepository defines the interface for webhook data operations.
+type WebhookRepository interface {
+	SaveSubscription(ctx context.Context, subscription models.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListSubscriptions(ctx context.Context, query ListQuery) (Page[models.WebhookSubscription], error)
+	ListActiveSubscriptions(ctx context.Context, eventType models.EventType) ([]models.WebhookSubscription, error)
+	SaveDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, query ListQuery) (Page[models.WebhookDelivery], error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	ListAttempts(ctx context.Context, deliveryID string, query ListQuery) (Page[models.WebhookDeliveryAttempt], error)
+	RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookDeliveryAttempt) error
+	RecordSubscriptionResult(ctx context.Context, subscriptionID uuid.UUID, succeeded bool, disableAfter int) error
+}
+
+// webhookRepository implements WebhookRepository.
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository creates a new webhook repository.
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, url, event_types, secret, active, consecutive_failures, disabled_at, created_at`
+
+const webhookDeliveryColumns = `id, tenant_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_error, replay_of, created_at`
+
+// SaveSubscription inserts or updates a webhook subscription in the database.
+func (r *webhookRepository) SaveSubscription(ctx context.Context, s models.WebhookSubscription) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhook_subscriptions (tenant_id, ` + webhookSubscriptionColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          ON CONFLICT (id) DO UPDATE SET url = EXCLUDED.url, event_types = EXCLUDED.event_types, secret = EXCLUDED.secret,
+	          active = EXCLUDED.active, consecutive_failures = EXCLUDED.consecutive_failures, disabled_at = EXCLUDED.disabled_at
+	          WHERE webhook_subscriptions.tenant_id = EXCLUDED.tenant_id`
+	_, err = executor(ctx, r.db).ExecContext(ctx, query, tenantID, s.ID, s.URL, s.EventTypes, s.Secret, s.Active, s.ConsecutiveFailures, s.DisabledAt, s.CreatedAt)
+	return err
+}
+
+// GetSubscription retrieves a webhook subscription by ID.
+func (r *webhookRepository) GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.WebhookSubscription{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	var subscription models.WebhookSubscription
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1 AND tenant_id = $2`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &subscription, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookSubscription{}, apperrors.ErrNotFound
+		}
+		return models.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription removes a webhook subscription and its deliveries by ID.
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1 AND tenant_id = $2`
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, parsedID, tenantID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// webhookSubscriptionSchema lists the fields subscriptions can be filtered and sorted by.
+var webhookSubscriptionSchema = listSchema[models.WebhookSubscription]{
+	fields: map[string]field[models.WebhookSubscription]{
+		"url":        {column: "url", kind: textField, key: func(s models.WebhookSubscription) string { return s.URL }},
+		"active":     {column: "active", kind: boolField},
+		"created_at": {column: "created_at", kind: timeField, key: func(s models.WebhookSubscription) string { return timeKey(s.CreatedAt) }},
+	},
+	defaultSort: "created_at",
+	id:          func(s models.WebhookSubscription) uuid.UUID { return s.ID },
+}
+
+// ListSubscriptions retrieves a page of webhook subscriptions, oldest first unless the query sorts otherwise.
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, query ListQuery) (Page[models.WebhookSubscription], error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.WebhookSubscription]{}, err
+	}
+
+	return selectPage(ctx, executor(ctx, r.db), webhookSubscriptionSchema, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE tenant_id = $1`, []any{tenantID}, query)
+}
+
+// ListActiveSubscriptions retrieves the active subscriptions interested in an event type.
+func (r *webhookRepository) ListActiveSubscriptions(ctx context.Context, eventType models.EventType) ([]models.WebhookSubscription, error) {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []models.WebhookSubscription
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = $1 AND active AND $2 = ANY(event_types)`
+	err = sqlx.SelectContext(ctx, executor(ctx, r.db), &subscriptions, query, tenantID, eventType)
+	return subscriptions, err
+}
+
+// SaveDelivery inserts or updates a webhook delivery in the database.
+func (r *webhookRepository) SaveDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	          ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, attempts = EXCLUDED.attempts,
+	          next_attempt_at = EXCLUDED.next_attempt_at, last_error = EXCLUDED.last_error`
+	_, err = executor(ctx, r.db).ExecContext(ctx, query, d.ID, tenantID, d.SubscriptionID, d.EventType, d.Payload, d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.ReplayOf, d.CreatedAt)
+	return err
+}
+
+// GetDelivery retrieves a webhook delivery by ID.
+func (r *webhookRepository) GetDelivery(ctx context.Context, id string) (models.WebhookDelivery, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return models.WebhookDelivery{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	var delivery models.WebhookDelivery
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND tenant_id = $2`
+	err = sqlx.GetContext(ctx, executor(ctx, r.db), &delivery, query, parsedID, tenantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDelivery{}, apperrors.ErrNotFound
+		}
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// webhookDeliverySchema lists the fields deliveries can be filtered and sorted by.
+var webhookDeliverySchema = listSchema[models.WebhookDelivery]{
+	fields: map[string]field[models.WebhookDelivery]{
+		"event_type": {column: "event_type", kind: textField},
+		"status":     {column: "status", kind: textField},
+		"created_at": {column: "created_at", kind: timeField, key: func(d models.WebhookDelivery) string { return timeKey(d.CreatedAt) }},
+	},
+	defaultSort: "-created_at",
+	id:          func(d models.WebhookDelivery) uuid.UUID { return d.ID },
+}
+
+// ListDeliveries retrieves a page of the deliveries of a subscription, newest
+// first unless the query sorts otherwise.
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, query ListQuery) (Page[models.WebhookDelivery], error) {
+	parsedID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return Page[models.WebhookDelivery]{}, apperrors.ErrInvalidID
+	}
+	tenantID, err := tenantScope(ctx)
+	if err != nil {
+		return Page[models.WebhookDelivery]{}, err
+	}
+
+	base := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1 AND tenant_id = $2`
+	return selectPage(ctx, executor(ctx, r.db), webhookDeliverySchema, base, []any{parsedID, tenantID}, query)
+}
+
+// ClaimDueDeliveries claims pending deliveries whose next attempt is due,
+// across every tenant, for the delivery worker. Claimed deliveries are not due
+// again until leaseUntil, so that workers on other instances pass them over
+// while they are attempted, and take them up again if the worker dies first.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = $2
+	          FROM (SELECT d.id FROM webhook_deliveries d JOIN webhook_subscriptions s ON s.id = d.subscription_id
+	                WHERE d.status = 'pending' AND s.active AND d.next_attempt_at <= $1
+	                ORDER BY d.next_attempt_at LIMIT $3
+	                FOR UPDATE OF d SKIP LOCKED) due
+	          WHERE d.id = due.id
+	          RETURNING d.id, d.tenant_id, d.subscription_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_error, d.replay_of, d.created_at`
+	err := sqlx.SelectContext(ctx, executor(ctx, r.db), &deliveries, query, now, leaseUntil, limit)
+	return deliveries, err
+}
+
+// webhookAttemptSchema lists the fields delivery attempts can be filtered and sorted by.
+var webhookAttemptSchema = listSchema[models.WebhookDeliveryAttempt]{
+	fields: map[string]field[models.WebhookDeliveryAttempt]{
+		"response_status": {column: "response_status", kind: intField},
+		"attempted_at":    {column: "attempted_at", kind: timeField, key: func(a models.WebhookDeliveryAttempt) string { return timeKey(a.AttemptedAt) }},
+	},
+	defaultSort: "attempted_at",
+	id:          func(a models.WebhookDeliveryAttempt) uuid.UUID { return a.ID },
+}
+
+// ListAttempts retrieves a page of the attempts made for a delivery, oldest
+// first unless the query sorts otherwise.
+func (r *webhookRepository) ListAttempts(ctx context.Context, deliveryID string, query ListQuery) (Page[models.WebhookDeliveryAttempt], error) {
+	parsedID, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return Page[models.WebhookDeliveryAttempt]{}, apperrors.ErrInvalidID
+	}
+
+	base := `SELECT id, delivery_id, attempted_at, response_status, error, duration_ms FROM webhook_delivery_attempts WHERE delivery_id = $1`
+	return selectPage(ctx, executor(ctx, r.db), webhookAttemptSchema, base, []any{parsedID}, query)
+}
+
+// RecordAttempt stores an attempt and the resulting delivery state atomically.
+func (r *webhookRepository) RecordAttempt(ctx context.Context, d models.WebhookDelivery, a models.WebhookDeliveryAttempt) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO webhook_delivery_attempts (id, delivery_id, attempted_at, response_status, error, duration_ms)
+	          VALUES ($1, $2, $3, $4, $5, $6)`, a.ID, a.DeliveryID, a.AttemptedAt, a.ResponseStatus, a.Error, a.DurationMS)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5 WHERE id = $1`,
+		d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastError)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordSubscriptionResult updates the consecutive failure count of a subscription,
+// disabling it once the count reaches disableAfter.
+func (r *webhookRepository) RecordSubscriptionResult(ctx context.Context, subscriptionID uuid.UUID, succeeded bool, disableAfter int) error {
+	if succeeded {
+		_, err := executor(ctx, r.db).ExecContext(ctx, `UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = $1`, subscriptionID)
+		return err
+	}
+
+	query := `UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures + 1,
+	          active = CASE WHEN consecutive_failures + 1 >= $2 THEN FALSE ELSE active END,
+	          disabled_at = CASE WHEN consecutive_failures + 1 >= $2 AND disabled_at IS NULL THEN now() ELSE disabled_at END
+	          WHERE id = $1`
+	_, err := executor(ctx, r.db).ExecContext(ctx, query, subscriptionID, disableAfter)
+	return err
+}
@@ -0,0 +1,71 @@
+// Package requestctx carries request-scoped values through context.Context.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// AnonymousActor is the actor recorded when a request is not attributed to anyone.
+const AnonymousActor = "anonymous"
+
+// contextKey is the type of keys stored by this package.
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	principalKey
+	tenantKey
+)
+
+// WithActor returns a copy of ctx carrying the acting principal.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the acting principal stored in ctx, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal,
+// who also becomes the actor, and the principal's tenant.
+func WithPrincipal(ctx context.Context, principal models.Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey, principal)
+	ctx = WithTenant(ctx, principal.TenantID)
+	return WithActor(ctx, principal.Actor())
+}
+
+// Principal returns the authenticated principal stored in ctx.
+func Principal(ctx context.Context) (models.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(models.Principal)
+	return principal, ok
+}
+
+// WithTenant returns a copy of ctx scoped to the tenant's data.
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// TenantID returns the tenant stored in ctx.
+func TenantID(ctx context.Context) (uuid.UUID, bool) {
+	tenantID, ok := ctx.Value(tenantKey).(uuid.UUID)
+	return tenantID, ok && tenantID != uuid.Nil
+}
@@ -0,0 +1,259 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ApartmentInput holds the editable fields of an apartment.
+type ApartmentInput struct {
+	BuildingID       string   `json:"building_id"`
+	Type             string   `json:"type"`
+	PriceFrom        int64    `json:"price_from"`
+	PriceTo          int64    `json:"price_to"`
+	PromotionalPrice *int64   `json:"promotional_price"`
+	Images           []string `json:"images"`
+	Videos           []string `json:"videos"`
+	Available        bool     `json:"available"`
+}
+
+// ApartmentService handles business logic for apartments.
+type ApartmentService struct {
+	repo         repositories.ApartmentRepository
+	buildingRepo repositories.BuildingRepository
+	outbox       repositories.OutboxRepository
+	audit        repositories.AuditRepository
+	tx           repositories.TxManager
+}
+
+// NewApartmentService creates a new apartment service.
+func NewApartmentService(repo repositories.ApartmentRepository, buildingRepo repositories.BuildingRepository, outbox repositories.OutboxRepository, audit repositories.AuditRepository, tx repositories.TxManager) *ApartmentService {
+	return &ApartmentService{repo: repo, buildingRepo: buildingRepo, outbox: outbox, audit: audit, tx: tx}
+}
+
+// CreateApartment creates a new apartment.
+func (s *ApartmentService) CreateApartment(ctx context.Context, input ApartmentInput) (models.Apartment, error) {
+	apartment, err := s.build(ctx, uuid.New(), input)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, apartment); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventApartmentCreated, apartment.ID, apartment); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityApartment, apartment.ID, nil, apartment)
+	})
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return apartment, nil
+}
+
+// GetApartment retrieves an apartment by ID.
+func (s *ApartmentService) GetApartment(ctx context.Context, id string) (models.Apartment, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateApartment replaces the fields of an existing apartment. A non-zero
+// version must match the stored one.
+func (s *ApartmentService) UpdateApartment(ctx context.Context, id string, input ApartmentInput, version int64) (models.Apartment, error) {
+	apartmentUUID, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	// Check if apartment exists
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Apartment{}, err
+	}
+
+	apartment, err := s.build(ctx, apartmentUUID, input)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+	apartment.Version = existing.Version + 1
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, apartment); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventApartmentUpdated, apartment.ID, apartment); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityApartment, apartment.ID, existing, apartment)
+	})
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return apartment, nil
+}
+
+// PatchApartment applies a JSON Merge Patch, in the fields of ApartmentInput,
+// to an apartment and saves the validated result. A non-zero version must match
+// the stored one.
+func (s *ApartmentService) PatchApartment(ctx context.Context, id string, patch []byte, version int64) (models.Apartment, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Apartment{}, err
+	}
+
+	input := ApartmentInput{
+		BuildingID:       existing.BuildingID.String(),
+		Type:             string(existing.Type),
+		PriceFrom:        existing.Price.From,
+		PriceTo:          existing.Price.To,
+		PromotionalPrice: existing.PromotionalPrice,
+		Images:           existing.Images,
+		Videos:           existing.Videos,
+		Available:        existing.Available,
+	}
+	if err := applyMergePatch(&input, patch); err != nil {
+		return models.Apartment{}, err
+	}
+
+	return s.UpdateApartment(ctx, id, input, existing.Version)
+}
+
+// DeleteApartment removes an apartment by ID. A non-zero version must match the
+// stored one.
+func (s *ApartmentService) DeleteApartment(ctx context.Context, id string, version int64) error {
+	apartmentUUID, err := utils.ValidateID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, existing.Version); err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id, existing.Version); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventApartmentDeleted, apartmentUUID, deletedEntity{ID: apartmentUUID}); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditDelete, models.EntityApartment, apartmentUUID, existing, nil)
+	})
+}
+
+// RestoreApartment takes an apartment out of the trash. An apartment whose
+// building is in the trash cannot be restored on its own.
+func (s *ApartmentService) RestoreApartment(ctx context.Context, id string) (models.Apartment, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	var apartment models.Apartment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		restored, err := s.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+		apartment = restored
+		if _, err := s.buildingRepo.GetByID(ctx, apartment.BuildingID.String()); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrConflict
+			}
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventApartmentRestored, apartment.ID, apartment); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditRestore, models.EntityApartment, apartment.ID, nil, apartment)
+	})
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return apartment, nil
+}
+
+// ListApartments retrieves a page of apartments, most recently updated first.
+func (s *ApartmentService) ListApartments(ctx context.Context, params ListParams) (ListPage[models.Apartment], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Apartment], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// GetAvailableApartment retrieves an apartment by ID if it is available.
+func (s *ApartmentService) GetAvailableApartment(ctx context.Context, id string) (models.Apartment, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return s.repo.GetAvailable(ctx, id)
+}
+
+// ListAvailableApartments retrieves a page of available apartments, most
+// recently updated first.
+func (s *ApartmentService) ListAvailableApartments(ctx context.Context, params ListParams) (ListPage[models.Apartment], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Apartment], error) {
+		return s.repo.ListAvailable(ctx, query)
+	})
+}
+
+// ApartmentsLastModified returns when any apartment was last saved, deleted or
+// restored, or the zero time if there never was one.
+func (s *ApartmentService) ApartmentsLastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
+
+// build validates the input and the building it refers to and returns the apartment.
+func (s *ApartmentService) build(ctx context.Context, id uuid.UUID, input ApartmentInput) (models.Apartment, error) {
+	buildingUUID, err := utils.ValidateFieldID("building_id", input.BuildingID)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	price := models.PriceRange{From: input.PriceFrom, To: input.PriceTo}
+	apartment, err := models.NewApartment(id, buildingUUID, models.ApartmentType(input.Type), price, input.PromotionalPrice, input.Images, input.Videos, time.Now().UTC())
+	if err != nil {
+		return models.Apartment{}, err
+	}
+	apartment.Available = input.Available
+
+	// Check if building exists
+	_, err = s.buildingRepo.GetByID(ctx, input.BuildingID)
+	if err != nil {
+		return models.Apartment{}, err
+	}
+
+	return apartment, nil
+}
@@ -0,0 +1,145 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyDisplayLength is how many leading characters of a key are kept to identify it.
+	apiKeyDisplayLength = len(models.APIKeyPrefix) + 8
+	// apiKeyLastUsedGranularity is how stale last_used_at may get before it is rewritten.
+	apiKeyLastUsedGranularity = time.Minute
+)
+
+// IssuedAPIKey is returned once on issuance and includes the key itself.
+type IssuedAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyService handles issuance, revocation and verification of API keys.
+type APIKeyService struct {
+	repo  repositories.APIKeyRepository
+	audit repositories.AuditRepository
+	tx    repositories.TxManager
+}
+
+// NewAPIKeyService creates a new API key service.
+func NewAPIKeyService(repo repositories.APIKeyRepository, audit repositories.AuditRepository, tx repositories.TxManager) *APIKeyService {
+	return &APIKeyService{repo: repo, audit: audit, tx: tx}
+}
+
+// IssueKey creates an API key with the given scopes. The key is only returned
+// here; afterwards only its prefix is known.
+func (s *APIKeyService) IssueKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (IssuedAPIKey, error) {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return IssuedAPIKey{}, apperrors.ErrForbidden
+	}
+
+	secret, err := generateRefreshToken()
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+	key := models.APIKeyPrefix + secret
+
+	var createdBy *uuid.UUID
+	if principal, ok := requestctx.Principal(ctx); ok && principal.UserID != uuid.Nil {
+		createdBy = &principal.UserID
+	}
+
+	apiKey, err := models.NewAPIKey(uuid.New(), tenantID, name, key[:apiKeyDisplayLength], hashToken(key), scopes, createdBy, expiresAt, time.Now().UTC())
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, apiKey); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityAPIKey, apiKey.ID, nil, apiKey)
+	})
+	if err != nil {
+		return IssuedAPIKey{}, err
+	}
+
+	return IssuedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// GetKey retrieves an API key by ID.
+func (s *APIKeyService) GetKey(ctx context.Context, id string) (models.APIKey, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListKeys retrieves a page of API keys, newest first, including revoked and expired ones.
+func (s *APIKeyService) ListKeys(ctx context.Context, params ListParams) (ListPage[models.APIKey], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.APIKey], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// RevokeKey revokes an API key. Requests using it are rejected immediately.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id string) error {
+	keyID, err := utils.ValidateID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if err := s.repo.Revoke(ctx, keyID, now); err != nil {
+			return err
+		}
+		revoked := existing
+		if revoked.RevokedAt == nil {
+			revoked.RevokedAt = &now
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityAPIKey, keyID, existing, revoked)
+	})
+}
+
+// Authenticate checks an API key and returns its principal, recording that the key was used.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (models.Principal, error) {
+	apiKey, err := s.repo.GetByHash(ctx, hashToken(key))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	now := time.Now().UTC()
+	if !apiKey.IsActive(now) {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, apiKey.ID, now, apiKeyLastUsedGranularity); err != nil {
+		return models.Principal{}, err
+	}
+
+	scopes := make([]models.APIKeyScope, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, models.APIKeyScope(scope))
+	}
+
+	return models.Principal{TenantID: apiKey.TenantID, APIKeyID: apiKey.ID, Scopes: scopes}, nil
+}
@@ -0,0 +1,64 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// AuditService handles business logic for the audit log.
+type AuditService struct {
+	repo repositories.AuditRepository
+}
+
+// NewAuditService creates a new audit service.
+func NewAuditService(repo repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListEntries retrieves a page of audit entries, newest first, filtered by
+// entity and actor as well as by the list parameters.
+func (s *AuditService) ListEntries(ctx context.Context, entityType string, entityID string, actor string, params ListParams) (ListPage[models.AuditEntry], error) {
+	if entityType != "" && !slices.Contains(models.AuditEntityTypes, entityType) {
+		return ListPage[models.AuditEntry]{}, apperrors.NewParamError("entity_type")
+	}
+	if entityID != "" {
+		if _, err := utils.ValidateID(entityID); err != nil {
+			return ListPage[models.AuditEntry]{}, apperrors.NewParamError("entity_id")
+		}
+	}
+
+	filters := slices.Clone(params.Filters)
+	for _, filter := range []repositories.Filter{
+		{Field: "entity_type", Value: entityType, Param: "entity_type"},
+		{Field: "entity_id", Value: entityID, Param: "entity_id"},
+		{Field: "actor", Value: actor, Param: "actor"},
+	} {
+		if filter.Value != "" {
+			filters = append(filters, filter)
+		}
+	}
+	params.Filters = filters
+
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.AuditEntry], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// recordAudit appends an audit entry for a mutation, attributed to the actor and
+// request stored in ctx. Call it within the transaction that saves the change.
+func recordAudit(ctx context.Context, audit repositories.AuditRepository, action models.AuditAction, entityType string, entityID uuid.UUID, before, after any) error {
+	entry, err := models.NewAuditEntry(uuid.New(), requestctx.Actor(ctx), requestctx.RequestID(ctx), action, entityType, entityID, before, after, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return audit.Append(ctx, entry)
+}
@@ -0,0 +1,290 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// accessTokenIssuer is the issuer claim of access tokens.
+	accessTokenIssuer = "bruschirentals"
+	// maxPasswordBytes is the longest password bcrypt accepts.
+	maxPasswordBytes = 72
+)
+
+// TokenPair is returned by a successful login or refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// accessClaims are the JWT claims of an access token.
+type accessClaims struct {
+	TenantID string      `json:"tid"`
+	Email    string      `json:"email"`
+	Role     models.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// AuthService handles user authentication and token issuance.
+type AuthService struct {
+	users           repositories.UserRepository
+	refreshTokens   repositories.RefreshTokenRepository
+	tx              repositories.TxManager
+	signingKey      []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// NewAuthService creates a new auth service signing access tokens with signingKey.
+func NewAuthService(users repositories.UserRepository, refreshTokens repositories.RefreshTokenRepository, tx repositories.TxManager, signingKey string, accessTokenTTL, refreshTokenTTL time.Duration) *AuthService {
+	return &AuthService{
+		users:           users,
+		refreshTokens:   refreshTokens,
+		tx:              tx,
+		signingKey:      []byte(signingKey),
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// EnsureUser creates a user of the tenant with the given credentials and role unless
+// one already exists with that email. It is used to bootstrap the first account.
+func (s *AuthService) EnsureUser(ctx context.Context, tenantID uuid.UUID, email string, password string, role models.Role) (models.User, error) {
+	email = normalizeEmail(email)
+	existing, err := s.users.GetByEmail(ctx, email)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		return models.User{}, err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := models.NewUser(uuid.New(), tenantID, email, hash, role, time.Now().UTC())
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = s.users.Save(ctx, user)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// Login checks the credentials and issues a new token pair.
+func (s *AuthService) Login(ctx context.Context, email string, password string) (TokenPair, error) {
+	user, err := s.users.GetByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// Compare anyway so unknown emails take as long as wrong passwords
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return TokenPair{}, apperrors.ErrUnauthorized
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return TokenPair{}, apperrors.ErrUnauthorized
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting an already revoked token revokes every token of its
+// user, since it means the token was stolen or replayed. Of two concurrent
+// refreshes with the same token only one revokes it, and the other is taken
+// for a replay.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	var pair TokenPair
+	var reused bool
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		token, err := s.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrUnauthorized
+		}
+		if err != nil {
+			return err
+		}
+
+		if token.RevokedAt != nil {
+			reused = true
+			return s.refreshTokens.RevokeAllForUser(ctx, token.UserID, now)
+		}
+		if !token.IsActive(now) {
+			return apperrors.ErrUnauthorized
+		}
+
+		user, err := s.users.GetByID(ctx, token.UserID.String())
+		if err != nil {
+			return err
+		}
+
+		revoked, err := s.refreshTokens.Revoke(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			reused = true
+			return s.refreshTokens.RevokeAllForUser(ctx, token.UserID, now)
+		}
+
+		pair, err = s.issueTokens(ctx, user)
+		return err
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if reused {
+		return TokenPair{}, apperrors.ErrUnauthorized
+	}
+
+	return pair, nil
+}
+
+// Logout revokes a refresh token. Unknown tokens are ignored.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.refreshTokens.Revoke(ctx, token.ID, time.Now().UTC())
+	return err
+}
+
+// Authenticate validates a signed access token and returns its principal.
+func (s *AuthService) Authenticate(_ context.Context, accessToken string) (models.Principal, error) {
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(*jwt.Token) (any, error) {
+		return s.signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(accessTokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+
+	if !claims.Role.IsValid() {
+		return models.Principal{}, apperrors.ErrUnauthorized
+	}
+
+	return models.Principal{TenantID: tenantID, UserID: userID, Email: claims.Email, Role: claims.Role}, nil
+}
+
+// issueTokens signs an access token and stores a new refresh token for the user.
+func (s *AuthService) issueTokens(ctx context.Context, user models.User) (TokenPair, error) {
+	now := time.Now().UTC()
+	claims := accessClaims{
+		TenantID: user.TenantID.String(),
+		Email:    user.Email,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    accessTokenIssuer,
+			Subject:   user.ID.String(),
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.signingKey)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	err = s.refreshTokens.Save(ctx, models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// dummyPasswordHash is compared against when the user does not exist.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// hashPassword checks the password length and returns its bcrypt hash.
+func hashPassword(password string) (string, error) {
+	if len(password) < models.MinPasswordLength {
+		return "", apperrors.NewFieldError("password", apperrors.CodeTooShort, fmt.Sprintf("must be at least %d characters", models.MinPasswordLength))
+	}
+	if len(password) > maxPasswordBytes {
+		return "", apperrors.NewFieldError("password", apperrors.CodeTooLong, fmt.Sprintf("must be at most %d bytes", maxPasswordBytes))
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// generateRefreshToken returns a random URL-safe token.
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the hex SHA-256 of a token, as stored in the database.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail lowercases and trims an email address.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,476 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSuggestionLimit is used when no limit is requested.
+	defaultSuggestionLimit = 10
+	// maxSuggestionLimit caps the number of suggestions returned.
+	maxSuggestionLimit = 25
+	// maxPrefixLength caps the length of an autocomplete prefix.
+	maxPrefixLength = 100
+	// maxIndexScan bounds the index keys looked at per lookup, so short
+	// prefixes shared by many records stay fast.
+	maxIndexScan = 2000
+	// indexLoadTimeout bounds loading the index of one tenant.
+	indexLoadTimeout = 30 * time.Second
+	// autocompleteChannel is the Postgres notification channel index changes
+	// are broadcast on.
+	autocompleteChannel = "autocomplete_index"
+)
+
+// SuggestionSource tells where autocomplete suggestions were read from.
+type SuggestionSource string
+
+// Suggestion sources
+const (
+	SourceIndex    SuggestionSource = "index"
+	SourceDatabase SuggestionSource = "database"
+)
+
+// AutocompleteService serves typeahead suggestions from an in-memory prefix
+// index per tenant. Indexes are loaded at startup and kept fresh from domain
+// events, which the instance dispatching them broadcasts to every instance
+// that called Listen; a tenant whose index is not loaded yet is served from
+// Postgres.
+type AutocompleteService struct {
+	organizations repositories.OrganizationRepository
+	neighborhoods repositories.NeighborhoodRepository
+	buildings     repositories.BuildingRepository
+	search        repositories.SearchRepository
+	notifications repositories.NotificationRepository
+
+	mu      sync.RWMutex
+	tenants map[uuid.UUID]*tenantIndex
+}
+
+// NewAutocompleteService creates a new autocomplete service.
+func NewAutocompleteService(organizations repositories.OrganizationRepository, neighborhoods repositories.NeighborhoodRepository, buildings repositories.BuildingRepository, search repositories.SearchRepository, notifications repositories.NotificationRepository) *AutocompleteService {
+	return &AutocompleteService{
+		organizations: organizations,
+		neighborhoods: neighborhoods,
+		buildings:     buildings,
+		search:        search,
+		notifications: notifications,
+		tenants:       make(map[uuid.UUID]*tenantIndex),
+	}
+}
+
+// Suggest returns up to limit records of the tenant in ctx with a word
+// starting with prefix, optionally restricted to one type. Records whose text
+// starts with the prefix come first.
+func (s *AutocompleteService) Suggest(ctx context.Context, prefix string, resultType string, limit int) ([]models.Suggestion, SuggestionSource, error) {
+	prefix = normalizeText(prefix)
+	if prefix == "" || len(prefix) > maxPrefixLength {
+		return nil, "", apperrors.NewParamError("q")
+	}
+	types := models.SearchResultTypes
+	if resultType != "" {
+		t := models.SearchResultType(resultType)
+		if !t.IsValid() {
+			return nil, "", apperrors.NewParamError("type")
+		}
+		types = []models.SearchResultType{t}
+	}
+	if limit < 0 {
+		return nil, "", apperrors.NewParamError("limit")
+	}
+	if limit == 0 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return nil, "", apperrors.ErrForbidden
+	}
+
+	s.mu.RLock()
+	tenant, found := s.tenants[tenantID]
+	if found && tenant.ready {
+		suggestions := tenant.index.lookup(prefix, types, limit)
+		s.mu.RUnlock()
+		return suggestions, SourceIndex, nil
+	}
+	s.mu.RUnlock()
+
+	if !found {
+		logger := logging.FromContext(ctx)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), indexLoadTimeout)
+			defer cancel()
+			if err := s.load(ctx, tenantID); err != nil {
+				logger.Warn("Failed to load autocomplete index", zap.Stringer("tenant_id", tenantID), zap.Error(err))
+			}
+		}()
+	}
+	suggestions, err := s.search.Suggest(ctx, prefix, types, limit)
+	return suggestions, SourceDatabase, err
+}
+
+// Warm loads the index of every organization. Indexes already loaded are
+// kept; ones being loaded are waited for.
+func (s *AutocompleteService) Warm(ctx context.Context) error {
+	organizations, err := listAll(func(query repositories.ListQuery) (repositories.Page[models.Organization], error) {
+		return s.organizations.List(ctx, query)
+	})
+	if err != nil {
+		return err
+	}
+	for _, organization := range organizations {
+		if err := s.load(ctx, organization.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexChange is the notification of a changed neighborhood or building. It
+// names the record rather than carrying it, since notifications are small;
+// every instance reads the record itself.
+type indexChange struct {
+	Type     models.EventType `json:"type"`
+	TenantID uuid.UUID        `json:"tenant_id"`
+	ID       uuid.UUID        `json:"id"`
+}
+
+// HandleEvent broadcasts a neighborhood or building change to the index of
+// every instance, this one included. Subscribe it to the event dispatcher,
+// which runs on one instance at a time.
+func (s *AutocompleteService) HandleEvent(ctx context.Context, event models.DomainEvent) error {
+	payload, err := json.Marshal(indexChange{Type: event.Type, TenantID: event.TenantID, ID: event.AggregateID})
+	if err != nil {
+		return err
+	}
+	return s.notifications.Notify(ctx, autocompleteChannel, string(payload))
+}
+
+// Listen subscribes to the index changes broadcast by HandleEvent and applies
+// them until ctx is cancelled. It returns once the subscription is in place.
+// After a reconnect every index is dropped and loaded again on its next use,
+// since changes may have been missed.
+func (s *AutocompleteService) Listen(ctx context.Context, dataSourceName string, logger *zap.Logger) error {
+	return repositories.Listen(ctx, dataSourceName, autocompleteChannel, logger, func(payload string) {
+		var change indexChange
+		if err := json.Unmarshal([]byte(payload), &change); err != nil {
+			logger.Warn("Failed to read autocomplete index change", zap.Error(err))
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, indexLoadTimeout)
+		defer cancel()
+		if err := s.apply(ctx, change); err != nil {
+			logger.Warn("Failed to apply autocomplete index change", zap.Stringer("id", change.ID), zap.Error(err))
+		}
+	}, s.reset)
+}
+
+// apply reads a changed neighborhood or building and puts it in the index of
+// its tenant, or removes it once deleted. Tenants whose index is not loaded
+// are skipped; loading reads the change from the database.
+func (s *AutocompleteService) apply(ctx context.Context, change indexChange) error {
+	s.mu.RLock()
+	_, ok := s.tenants[change.TenantID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	ctx = requestctx.WithTenant(ctx, change.TenantID)
+	var put func(*tenantIndex)
+	switch change.Type {
+	case models.EventNeighborhoodCreated, models.EventNeighborhoodUpdated, models.EventNeighborhoodDeleted, models.EventNeighborhoodRestored:
+		ref := entryRef{models.SearchNeighborhood, change.ID}
+		neighborhood, err := s.neighborhoods.GetByID(ctx, change.ID.String())
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			put = func(t *tenantIndex) { t.remove(ref) }
+		case err != nil:
+			return err
+		default:
+			put = func(t *tenantIndex) { t.putNeighborhood(neighborhood) }
+		}
+	case models.EventBuildingCreated, models.EventBuildingUpdated, models.EventBuildingDeleted, models.EventBuildingRestored:
+		building, err := s.buildings.GetByID(ctx, change.ID.String())
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			put = func(t *tenantIndex) {
+				t.remove(entryRef{models.SearchBuilding, change.ID})
+				t.remove(entryRef{models.SearchAddress, change.ID})
+			}
+		case err != nil:
+			return err
+		default:
+			put = func(t *tenantIndex) { t.putBuilding(building) }
+		}
+	default:
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tenant, ok := s.tenants[change.TenantID]; ok {
+		put(tenant)
+	}
+	return nil
+}
+
+// reset drops every index.
+func (s *AutocompleteService) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants = make(map[uuid.UUID]*tenantIndex)
+}
+
+// load builds the index of one tenant, or waits for the load already under way.
+func (s *AutocompleteService) load(ctx context.Context, tenantID uuid.UUID) error {
+	s.mu.Lock()
+	tenant, ok := s.tenants[tenantID]
+	if ok {
+		s.mu.Unlock()
+		select {
+		case <-tenant.loaded:
+			return tenant.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	tenant = &tenantIndex{index: newPrefixIndex(), touched: make(map[entryRef]bool), loaded: make(chan struct{})}
+	s.tenants[tenantID] = tenant
+	s.mu.Unlock()
+
+	ctx = requestctx.WithTenant(ctx, tenantID)
+	neighborhoods, err := listAll(func(query repositories.ListQuery) (repositories.Page[models.Neighborhood], error) {
+		return s.neighborhoods.List(ctx, query)
+	})
+	var buildings []models.Building
+	if err == nil {
+		buildings, err = listAll(func(query repositories.ListQuery) (repositories.Page[models.Building], error) {
+			return s.buildings.List(ctx, query)
+		})
+	}
+
+	// Index the rows outside the lock, so that other tenants are served meanwhile
+	var built *prefixIndex
+	if err == nil {
+		texts := make(map[entryRef]string, len(neighborhoods)+2*len(buildings))
+		for _, neighborhood := range neighborhoods {
+			texts[entryRef{models.SearchNeighborhood, neighborhood.ID}] = neighborhood.Name
+		}
+		for _, building := range buildings {
+			texts[entryRef{models.SearchBuilding, building.ID}] = building.Name
+			texts[entryRef{models.SearchAddress, building.ID}] = building.Address
+		}
+		built = buildPrefixIndex(texts)
+	}
+
+	s.mu.Lock()
+	if err != nil {
+		// Forget the tenant so the next request retries
+		if s.tenants[tenantID] == tenant {
+			delete(s.tenants, tenantID)
+		}
+		tenant.err = err
+	} else {
+		// Changes seen while loading are newer than the rows read
+		for ref := range tenant.touched {
+			if entry, ok := tenant.index.entries[ref]; ok {
+				built.put(ref, entry.text)
+			} else {
+				built.remove(ref)
+			}
+		}
+		tenant.index = built
+		tenant.ready = true
+		tenant.touched = nil
+	}
+	s.mu.Unlock()
+	close(tenant.loaded)
+	return err
+}
+
+// tenantIndex is the prefix index of one tenant. Until ready, it records the
+// entries changed by events so the rows being loaded do not overwrite them.
+type tenantIndex struct {
+	index   *prefixIndex
+	ready   bool
+	touched map[entryRef]bool
+	loaded  chan struct{}
+	err     error
+}
+
+func (t *tenantIndex) putNeighborhood(neighborhood models.Neighborhood) {
+	t.put(entryRef{models.SearchNeighborhood, neighborhood.ID}, neighborhood.Name)
+}
+
+func (t *tenantIndex) putBuilding(building models.Building) {
+	t.put(entryRef{models.SearchBuilding, building.ID}, building.Name)
+	t.put(entryRef{models.SearchAddress, building.ID}, building.Address)
+}
+
+func (t *tenantIndex) put(ref entryRef, text string) {
+	t.index.put(ref, text)
+	if t.touched != nil {
+		t.touched[ref] = true
+	}
+}
+
+func (t *tenantIndex) remove(ref entryRef) {
+	t.index.remove(ref)
+	if t.touched != nil {
+		t.touched[ref] = true
+	}
+}
+
+// entryRef identifies an indexed record. A building is indexed twice, by name
+// and by address.
+type entryRef struct {
+	typ models.SearchResultType
+	id  uuid.UUID
+}
+
+// indexEntry is an indexed record.
+type indexEntry struct {
+	text string
+}
+
+// indexKey is the normalized text of an entry from one of its word starts, so
+// a prefix matches the beginning of any word.
+type indexKey struct {
+	key   string
+	ref   entryRef
+	first bool
+}
+
+// prefixIndex keeps the keys of its entries sorted, so the keys starting with
+// a prefix are found by binary search.
+type prefixIndex struct {
+	keys    []indexKey
+	entries map[entryRef]indexEntry
+}
+
+func newPrefixIndex() *prefixIndex {
+	return &prefixIndex{entries: make(map[entryRef]indexEntry)}
+}
+
+// buildPrefixIndex indexes the text of many records at once, sorting their
+// keys a single time.
+func buildPrefixIndex(texts map[entryRef]string) *prefixIndex {
+	p := &prefixIndex{entries: make(map[entryRef]indexEntry, len(texts))}
+	for ref, text := range texts {
+		p.entries[ref] = indexEntry{text: text}
+		for i, key := range wordSuffixes(text) {
+			p.keys = append(p.keys, indexKey{key: key, ref: ref, first: i == 0})
+		}
+	}
+	slices.SortFunc(p.keys, func(a, b indexKey) int {
+		return strings.Compare(a.key, b.key)
+	})
+	return p
+}
+
+// put adds an entry, replacing the previous text of the same record.
+func (p *prefixIndex) put(ref entryRef, text string) {
+	p.remove(ref)
+	p.entries[ref] = indexEntry{text: text}
+	for i, key := range wordSuffixes(text) {
+		at := p.search(key)
+		p.keys = append(p.keys, indexKey{})
+		copy(p.keys[at+1:], p.keys[at:])
+		p.keys[at] = indexKey{key: key, ref: ref, first: i == 0}
+	}
+}
+
+// remove drops an entry and its keys.
+func (p *prefixIndex) remove(ref entryRef) {
+	entry, ok := p.entries[ref]
+	if !ok {
+		return
+	}
+	delete(p.entries, ref)
+	for _, key := range wordSuffixes(entry.text) {
+		for at := p.search(key); at < len(p.keys) && p.keys[at].key == key; at++ {
+			if p.keys[at].ref == ref {
+				p.keys = append(p.keys[:at], p.keys[at+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// lookup returns the entries of the given types with a key starting with
+// prefix: those whose text starts with it first, then by text.
+func (p *prefixIndex) lookup(prefix string, types []models.SearchResultType, limit int) []models.Suggestion {
+	seen := make(map[entryRef]bool)
+	var first, other []entryRef
+	end := min(len(p.keys), p.search(prefix)+maxIndexScan)
+	for at := p.search(prefix); at < end && len(first) < limit; at++ {
+		key := p.keys[at]
+		if !strings.HasPrefix(key.key, prefix) {
+			break
+		}
+		if seen[key.ref] || !slices.Contains(types, key.ref.typ) {
+			continue
+		}
+		seen[key.ref] = true
+		if key.first {
+			first = append(first, key.ref)
+		} else {
+			other = append(other, key.ref)
+		}
+	}
+
+	// first is already in text order; other is in the order of the matching word
+	sort.SliceStable(other, func(i, j int) bool {
+		return normalizeText(p.entries[other[i]].text) < normalizeText(p.entries[other[j]].text)
+	})
+	refs := append(first, other...)
+	if len(refs) > limit {
+		refs = refs[:limit]
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(refs))
+	for _, ref := range refs {
+		suggestions = append(suggestions, models.Suggestion{Type: ref.typ, ID: ref.id, Text: p.entries[ref].text})
+	}
+	return suggestions
+}
+
+// search returns the position of the first key not less than key.
+func (p *prefixIndex) search(key string) int {
+	return sort.Search(len(p.keys), func(i int) bool { return p.keys[i].key >= key })
+}
+
+// wordSuffixes returns the normalized text starting at each of its words.
+func wordSuffixes(text string) []string {
+	words := strings.Fields(strings.ToLower(text))
+	suffixes := make([]string, len(words))
+	for i := range words {
+		suffixes[i] = strings.Join(words[i:], " ")
+	}
+	return suffixes
+}
+
+// normalizeText lowercases text and collapses its whitespace.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
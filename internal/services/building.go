@@ -3,7 +3,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/models"
 	"github.com/Andre385/bruschirentals-backend/internal/repositories"
 	"github.com/Andre385/bruschirentals-backend/internal/utils"
@@ -14,17 +17,20 @@ import (
 type BuildingService struct {
 	repo             repositories.BuildingRepository
 	neighborhoodRepo repositories.NeighborhoodRepository
+	outbox           repositories.OutboxRepository
+	audit            repositories.AuditRepository
+	tx               repositories.TxManager
 }
 
 // NewBuildingService creates a new building service.
-func NewBuildingService(repo repositories.BuildingRepository, neighborhoodRepo repositories.NeighborhoodRepository) *BuildingService {
-	return &BuildingService{repo: repo, neighborhoodRepo: neighborhoodRepo}
+func NewBuildingService(repo repositories.BuildingRepository, neighborhoodRepo repositories.NeighborhoodRepository, outbox repositories.OutboxRepository, audit repositories.AuditRepository, tx repositories.TxManager) *BuildingService {
+	return &BuildingService{repo: repo, neighborhoodRepo: neighborhoodRepo, outbox: outbox, audit: audit, tx: tx}
 }
 
 // CreateBuilding creates a new building.
 func (s *BuildingService) CreateBuilding(ctx context.Context, name string, neighborhoodID string, address string) (models.Building, error) {
 	// Validate neighborhood ID
-	neighborhoodUUID, err := utils.ValidateID(neighborhoodID)
+	neighborhoodUUID, err := utils.ValidateFieldID("neighborhood_id", neighborhoodID)
 	if err != nil {
 		return models.Building{}, err
 	}
@@ -40,8 +46,17 @@ func (s *BuildingService) CreateBuilding(ctx context.Context, name string, neigh
 	if err != nil {
 		return models.Building{}, err
 	}
+	building.UpdatedAt = time.Now().UTC()
 
-	err = s.repo.Save(ctx, building)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, building); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventBuildingCreated, building.ID, building); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityBuilding, building.ID, nil, building)
+	})
 	if err != nil {
 		return models.Building{}, err
 	}
@@ -64,8 +79,9 @@ func (s *BuildingService) GetBuilding(ctx context.Context, id string) (models.Bu
 	return building, nil
 }
 
-// UpdateBuilding updates an existing building.
-func (s *BuildingService) UpdateBuilding(ctx context.Context, id string, name string, neighborhoodID string, address string) (models.Building, error) {
+// UpdateBuilding updates an existing building. A non-zero version must match
+// the stored one.
+func (s *BuildingService) UpdateBuilding(ctx context.Context, id string, name string, neighborhoodID string, address string, version int64) (models.Building, error) {
 	// Validate building ID
 	buildingUUID, err := utils.ValidateID(id)
 	if err != nil {
@@ -73,13 +89,16 @@ func (s *BuildingService) UpdateBuilding(ctx context.Context, id string, name st
 	}
 
 	// Check if building exists
-	_, err = s.repo.GetByID(ctx, id)
+	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return models.Building{}, err
 	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Building{}, err
+	}
 
 	// Validate neighborhood ID
-	neighborhoodUUID, err := utils.ValidateID(neighborhoodID)
+	neighborhoodUUID, err := utils.ValidateFieldID("neighborhood_id", neighborhoodID)
 	if err != nil {
 		return models.Building{}, err
 	}
@@ -94,8 +113,18 @@ func (s *BuildingService) UpdateBuilding(ctx context.Context, id string, name st
 	if err != nil {
 		return models.Building{}, err
 	}
+	building.Version = existing.Version + 1
+	building.UpdatedAt = time.Now().UTC()
 
-	err = s.repo.Save(ctx, building)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, building); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventBuildingUpdated, building.ID, building); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityBuilding, building.ID, existing, building)
+	})
 	if err != nil {
 		return models.Building{}, err
 	}
@@ -103,17 +132,128 @@ func (s *BuildingService) UpdateBuilding(ctx context.Context, id string, name st
 	return building, nil
 }
 
-// DeleteBuilding removes a building by ID.
-func (s *BuildingService) DeleteBuilding(ctx context.Context, id string) error {
+// PatchBuilding applies a JSON Merge Patch to a building and saves the validated
+// result. A non-zero version must match the stored one.
+func (s *BuildingService) PatchBuilding(ctx context.Context, id string, patch []byte, version int64) (models.Building, error) {
 	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Building{}, err
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Building{}, err
+	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Building{}, err
+	}
+
+	fields := struct {
+		Name           string `json:"name"`
+		NeighborhoodID string `json:"neighborhood_id"`
+		Address        string `json:"address"`
+	}{Name: existing.Name, NeighborhoodID: existing.NeighborhoodID.String(), Address: existing.Address}
+	if err := applyMergePatch(&fields, patch); err != nil {
+		return models.Building{}, err
+	}
+
+	return s.UpdateBuilding(ctx, id, fields.Name, fields.NeighborhoodID, fields.Address, existing.Version)
+}
+
+// DeleteBuilding removes a building by ID. A non-zero version must match the
+// stored one.
+func (s *BuildingService) DeleteBuilding(ctx context.Context, id string, version int64) error {
+	buildingUUID, err := utils.ValidateID(id)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.Delete(ctx, id)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, existing.Version); err != nil {
+			return err
+		}
+		apartments, err := s.repo.Delete(ctx, id, existing.Version)
+		if err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventBuildingDeleted, buildingUUID, deletedEntity{ID: buildingUUID}); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, models.AuditDelete, models.EntityBuilding, buildingUUID, existing, nil); err != nil {
+			return err
+		}
+		return recordTrashed(ctx, s.outbox, s.audit, nil, apartments)
+	})
+}
+
+// RestoreBuilding takes a building out of the trash together with the
+// apartments deleted with it. A building whose neighborhood is in the trash
+// cannot be restored on its own; restore the neighborhood instead.
+func (s *BuildingService) RestoreBuilding(ctx context.Context, id string) (models.Building, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Building{}, err
+	}
+
+	var building models.Building
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		restored, apartments, err := s.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+		building = restored
+		if _, err := s.neighborhoodRepo.GetByID(ctx, building.NeighborhoodID.String()); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrConflict
+			}
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventBuildingRestored, building.ID, building); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, models.AuditRestore, models.EntityBuilding, building.ID, nil, building); err != nil {
+			return err
+		}
+		return recordRestored(ctx, s.outbox, s.audit, nil, apartments)
+	})
+	if err != nil {
+		return models.Building{}, err
+	}
+
+	return building, nil
+}
+
+// ListBuildings retrieves a page of buildings ordered by name.
+func (s *BuildingService) ListBuildings(ctx context.Context, params ListParams) (ListPage[models.Building], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Building], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// GetListedBuilding retrieves a building by ID if it has an available apartment.
+func (s *BuildingService) GetListedBuilding(ctx context.Context, id string) (models.Building, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Building{}, err
+	}
+
+	return s.repo.GetListed(ctx, id)
+}
+
+// ListListedBuildings retrieves a page of the buildings that have an available
+// apartment, ordered by name.
+func (s *BuildingService) ListListedBuildings(ctx context.Context, params ListParams) (ListPage[models.Building], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Building], error) {
+		return s.repo.ListListed(ctx, query)
+	})
 }
 
-// ListBuildings retrieves all buildings.
-func (s *BuildingService) ListBuildings(ctx context.Context) ([]models.Building, error) {
-	return s.repo.List(ctx)
+// BuildingsLastModified returns when any building was last saved, deleted or
+// restored, or the zero time if there never was one.
+func (s *BuildingService) BuildingsLastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
 }
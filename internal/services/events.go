@@ -0,0 +1,227 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventBatchSize is the number of outbox events dispatched per poll.
+	eventBatchSize = 100
+	// eventMaxAttempts is how many times an event is dispatched before it is parked.
+	eventMaxAttempts = 8
+	// eventBaseBackoff is the delay before the first retry; it doubles on every attempt.
+	eventBaseBackoff = time.Second
+)
+
+// EventHandler reacts to a published domain event. Handlers may see the same
+// event more than once and must be idempotent.
+type EventHandler func(ctx context.Context, event models.DomainEvent) error
+
+// EventDispatcher publishes outbox events, in the order they were written, to
+// in-process subscribers with at-least-once semantics.
+type EventDispatcher struct {
+	outbox repositories.OutboxRepository
+	tx     repositories.TxManager
+
+	mu       sync.RWMutex
+	handlers map[models.EventType][]EventHandler
+	all      []EventHandler
+}
+
+// NewEventDispatcher creates a new event dispatcher.
+func NewEventDispatcher(outbox repositories.OutboxRepository, tx repositories.TxManager) *EventDispatcher {
+	return &EventDispatcher{
+		outbox:   outbox,
+		tx:       tx,
+		handlers: make(map[models.EventType][]EventHandler),
+	}
+}
+
+// Subscribe registers a handler for one event type.
+func (d *EventDispatcher) Subscribe(eventType models.EventType, handler EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// SubscribeAll registers a handler for every event type.
+func (d *EventDispatcher) SubscribeAll(handler EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.all = append(d.all, handler)
+}
+
+// Run dispatches pending events every interval until the context is cancelled.
+func (d *EventDispatcher) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.DispatchPending(ctx); err != nil {
+				logger.Error("Failed to dispatch outbox events", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DispatchPending hands the next batch of unpublished events to subscribers.
+// The handlers of each event run in a savepoint of the dispatch transaction,
+// so that the changes they make are committed with the event's publication,
+// or rolled back alone when one of them fails. A failed event is retried with
+// backoff, and the events after it wait so that ordering is kept, until it is
+// parked after eventMaxAttempts: then dispatch moves on without it.
+func (d *EventDispatcher) DispatchPending(ctx context.Context) error {
+	var handlerErr error
+	err := d.tx.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := d.outbox.TryLock(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := d.outbox.ListPending(ctx, eventBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			now := time.Now().UTC()
+			if event.NextAttemptAt != nil && event.NextAttemptAt.After(now) {
+				return nil
+			}
+
+			dispatchErr := d.tx.WithinSavepoint(ctx, func(ctx context.Context) error {
+				return d.dispatch(ctx, event)
+			})
+			if dispatchErr == nil {
+				if err := d.outbox.MarkPublished(ctx, event.ID, now); err != nil {
+					return err
+				}
+				continue
+			}
+
+			message := dispatchErr.Error()
+			event.Attempts++
+			event.LastError = &message
+			if event.Attempts >= eventMaxAttempts {
+				event.NextAttemptAt, event.ParkedAt = nil, &now
+				handlerErr = fmt.Errorf("parking event %s after %d attempts: %w", event.ID, event.Attempts, dispatchErr)
+			} else {
+				next := now.Add(eventBackoff(event.Attempts))
+				event.NextAttemptAt = &next
+				handlerErr = fmt.Errorf("dispatching event %s: %w", event.ID, dispatchErr)
+			}
+			if err := d.outbox.RecordFailure(ctx, event); err != nil {
+				return err
+			}
+			if event.ParkedAt == nil {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return handlerErr
+}
+
+// eventBackoff returns the delay before retrying an event that failed attempts times.
+func eventBackoff(attempts int) time.Duration {
+	return eventBaseBackoff << (attempts - 1)
+}
+
+// dispatch calls every handler subscribed to the event, scoped to the event's tenant.
+func (d *EventDispatcher) dispatch(ctx context.Context, event models.DomainEvent) error {
+	ctx = requestctx.WithTenant(ctx, event.TenantID)
+
+	d.mu.RLock()
+	handlers := append(append([]EventHandler{}, d.handlers[event.Type]...), d.all...)
+	d.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordEvent appends a domain event for the tenant in ctx to the outbox. Call
+// it within the transaction that saves the change.
+func recordEvent(ctx context.Context, outbox repositories.OutboxRepository, eventType models.EventType, aggregateID uuid.UUID, data any) error {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return apperrors.ErrForbidden
+	}
+
+	event, err := models.NewDomainEvent(uuid.New(), tenantID, eventType, aggregateID, data, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return outbox.Append(ctx, event)
+}
+
+// deletedEntity is the event payload recorded when an entity is removed.
+type deletedEntity struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// recordTrashed records the deletion of buildings and apartments trashed with
+// their parent, as though each had been deleted on its own. Each is given as
+// it was before the deletion.
+func recordTrashed(ctx context.Context, outbox repositories.OutboxRepository, audit repositories.AuditRepository, buildings []models.Building, apartments []models.Apartment) error {
+	for _, building := range buildings {
+		if err := recordEvent(ctx, outbox, models.EventBuildingDeleted, building.ID, deletedEntity{ID: building.ID}); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, audit, models.AuditDelete, models.EntityBuilding, building.ID, building, nil); err != nil {
+			return err
+		}
+	}
+	for _, apartment := range apartments {
+		if err := recordEvent(ctx, outbox, models.EventApartmentDeleted, apartment.ID, deletedEntity{ID: apartment.ID}); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, audit, models.AuditDelete, models.EntityApartment, apartment.ID, apartment, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRestored records the restoration of buildings and apartments restored
+// with their parent.
+func recordRestored(ctx context.Context, outbox repositories.OutboxRepository, audit repositories.AuditRepository, buildings []models.Building, apartments []models.Apartment) error {
+	for _, building := range buildings {
+		if err := recordEvent(ctx, outbox, models.EventBuildingRestored, building.ID, building); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, audit, models.AuditRestore, models.EntityBuilding, building.ID, nil, building); err != nil {
+			return err
+		}
+	}
+	for _, apartment := range apartments {
+		if err := recordEvent(ctx, outbox, models.EventApartmentRestored, apartment.ID, apartment); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, audit, models.AuditRestore, models.EntityApartment, apartment.ID, nil, apartment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+)
+
+// ExportService streams listings out in bulk.
+type ExportService struct {
+	repo repositories.ExportRepository
+	tx   repositories.TxManager
+}
+
+// NewExportService creates a new export service.
+func NewExportService(repo repositories.ExportRepository, tx repositories.TxManager) *ExportService {
+	return &ExportService{repo: repo, tx: tx}
+}
+
+// ExportNeighborhoods calls fn with every neighborhood matching the filters,
+// in the list's order.
+func (s *ExportService) ExportNeighborhoods(ctx context.Context, params ListParams, fn func(models.Neighborhood) error) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Neighborhoods(ctx, exportQuery(params), fn)
+	})
+}
+
+// ExportBuildings calls fn with every building matching the filters, with its
+// neighborhood's name, in the list's order.
+func (s *ExportService) ExportBuildings(ctx context.Context, params ListParams, fn func(models.BuildingExport) error) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Buildings(ctx, exportQuery(params), fn)
+	})
+}
+
+// ExportApartments calls fn with every apartment matching the filters, with
+// its building's and neighborhood's names, in the list's order.
+func (s *ExportService) ExportApartments(ctx context.Context, params ListParams, fn func(models.ApartmentExport) error) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.Apartments(ctx, exportQuery(params), fn)
+	})
+}
+
+// exportQuery keeps the filters and sort of the list parameters. An export is
+// never paged, so Limit and Cursor are ignored.
+func exportQuery(params ListParams) repositories.ListQuery {
+	return repositories.ListQuery{Filters: params.Filters, Sort: params.Sort}
+}
@@ -0,0 +1,90 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"io"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+)
+
+// Feed is what a listings feed is rendered from: an organization's published
+// apartments. Updated is when any of them, or their buildings and
+// neighborhoods, last changed; prices are in cents of Currency.
+type Feed struct {
+	Organization models.Organization
+	Currency     string
+	Updated      time.Time
+	Listings     []models.FeedListing
+}
+
+// FeedFormat renders feeds for listing portals. A format is served under its
+// name, so adding a portal only takes a FeedFormat passed to NewFeedService.
+type FeedFormat interface {
+	// Name is the file name the feed is served at, e.g. listings.xml.
+	Name() string
+	// ContentType is the media type of the rendered feed.
+	ContentType() string
+	// Write renders the feed to w.
+	Write(w io.Writer, feed Feed) error
+}
+
+// FeedService builds listings feeds in the registered formats.
+type FeedService struct {
+	repo          repositories.FeedRepository
+	organizations repositories.OrganizationRepository
+	currency      string
+	formats       map[string]FeedFormat
+}
+
+// NewFeedService creates a new feed service serving formats, with prices in currency.
+func NewFeedService(repo repositories.FeedRepository, organizations repositories.OrganizationRepository, currency string, formats ...FeedFormat) *FeedService {
+	s := &FeedService{repo: repo, organizations: organizations, currency: currency, formats: make(map[string]FeedFormat, len(formats))}
+	for _, format := range formats {
+		s.formats[format.Name()] = format
+	}
+	return s
+}
+
+// Format returns the format served under name.
+func (s *FeedService) Format(name string) (FeedFormat, error) {
+	format, ok := s.formats[name]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return format, nil
+}
+
+// LastModified returns when the feed last changed, or the zero time if it
+// has never had listings.
+func (s *FeedService) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
+
+// GetFeed reads the current organization's feed. The modification time is read
+// before the listings: a change in between makes the feed newer than it claims,
+// so clients polling with If-Modified-Since fetch it again rather than miss it.
+func (s *FeedService) GetFeed(ctx context.Context) (Feed, error) {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return Feed{}, apperrors.ErrForbidden
+	}
+
+	organization, err := s.organizations.GetByID(ctx, tenantID.String())
+	if err != nil {
+		return Feed{}, err
+	}
+	updated, err := s.repo.LastModified(ctx)
+	if err != nil {
+		return Feed{}, err
+	}
+	listings, err := s.repo.Listings(ctx)
+	if err != nil {
+		return Feed{}, err
+	}
+	return Feed{Organization: organization, Currency: s.currency, Updated: updated, Listings: listings}, nil
+}
@@ -0,0 +1,194 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+)
+
+// formatAmount formats a price in cents as a decimal amount, e.g. 150000 as 1500.00.
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// XMLFeedFormat renders feeds as listings.xml: a listing element per
+// apartment, with decimal prices.
+type XMLFeedFormat struct{}
+
+// xmlListings is the root element of listings.xml.
+type xmlListings struct {
+	XMLName      xml.Name     `xml:"listings"`
+	Organization string       `xml:"organization,attr"`
+	Updated      string       `xml:"updated,attr,omitempty"`
+	Listings     []xmlListing `xml:"listing"`
+}
+
+// xmlListing is an apartment in listings.xml.
+type xmlListing struct {
+	ID           string          `xml:"id,attr"`
+	Updated      string          `xml:"updated,attr"`
+	Type         string          `xml:"type"`
+	Neighborhood xmlNeighborhood `xml:"neighborhood"`
+	Building     xmlBuilding     `xml:"building"`
+	Price        xmlPrice        `xml:"price"`
+	Promotion    *xmlPromotion   `xml:"promotion"`
+	Images       []string        `xml:"images>image"`
+	Videos       []string        `xml:"videos>video"`
+}
+
+type xmlNeighborhood struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:",chardata"`
+}
+
+type xmlBuilding struct {
+	ID      string `xml:"id,attr"`
+	Name    string `xml:"name"`
+	Address string `xml:"address"`
+}
+
+type xmlPrice struct {
+	Currency string `xml:"currency,attr"`
+	From     string `xml:"from,attr"`
+	To       string `xml:"to,attr"`
+}
+
+type xmlPromotion struct {
+	Currency string `xml:"currency,attr"`
+	Price    string `xml:"price,attr"`
+}
+
+// Name implements FeedFormat.
+func (XMLFeedFormat) Name() string { return "listings.xml" }
+
+// ContentType implements FeedFormat.
+func (XMLFeedFormat) ContentType() string { return "application/xml; charset=utf-8" }
+
+// Write implements FeedFormat.
+func (XMLFeedFormat) Write(w io.Writer, feed Feed) error {
+	doc := xmlListings{Organization: feed.Organization.Slug, Listings: make([]xmlListing, 0, len(feed.Listings))}
+	if !feed.Updated.IsZero() {
+		doc.Updated = feed.Updated.UTC().Format(time.RFC3339)
+	}
+	for _, l := range feed.Listings {
+		listing := xmlListing{
+			ID:           l.ID.String(),
+			Updated:      l.LastUpdate.UTC().Format(time.RFC3339),
+			Type:         l.Type.String(),
+			Neighborhood: xmlNeighborhood{ID: l.NeighborhoodID.String(), Name: l.Neighborhood},
+			Building:     xmlBuilding{ID: l.BuildingID.String(), Name: l.Building, Address: l.Address},
+			Price:        xmlPrice{Currency: feed.Currency, From: formatAmount(l.Price.From), To: formatAmount(l.Price.To)},
+			Images:       l.Images,
+			Videos:       l.Videos,
+		}
+		if l.PromotionalPrice != nil {
+			listing.Promotion = &xmlPromotion{Currency: feed.Currency, Price: formatAmount(*l.PromotionalPrice)}
+		}
+		doc.Listings = append(doc.Listings, listing)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// JSONLDFeedFormat renders feeds as listings.jsonld: a schema.org ItemList of
+// Apartment items, each contained in its building and neighborhood and
+// offered for rent at its price range and, when set, its promotional price.
+type JSONLDFeedFormat struct{}
+
+// jsonLD is a schema.org node.
+type jsonLD = map[string]any
+
+// Name implements FeedFormat.
+func (JSONLDFeedFormat) Name() string { return "listings.jsonld" }
+
+// ContentType implements FeedFormat.
+func (JSONLDFeedFormat) ContentType() string { return "application/ld+json" }
+
+// Write implements FeedFormat.
+func (JSONLDFeedFormat) Write(w io.Writer, feed Feed) error {
+	items := make([]jsonLD, 0, len(feed.Listings))
+	for i, l := range feed.Listings {
+		items = append(items, jsonLD{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"item":     jsonLDApartment(l, feed.Currency),
+		})
+	}
+
+	doc := jsonLD{
+		"@context":        "https://schema.org",
+		"@type":           "ItemList",
+		"name":            feed.Organization.Name,
+		"numberOfItems":   len(items),
+		"itemListElement": items,
+	}
+	if !feed.Updated.IsZero() {
+		doc["dateModified"] = feed.Updated.UTC().Format(time.RFC3339)
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// jsonLDApartment describes a listing as a schema.org Apartment.
+func jsonLDApartment(l models.FeedListing, currency string) jsonLD {
+	offers := []jsonLD{{
+		"@type":            "Offer",
+		"businessFunction": "http://purl.org/goodrelations/v1#LeaseOut",
+		"priceCurrency":    currency,
+		"priceSpecification": jsonLD{
+			"@type":         "UnitPriceSpecification",
+			"minPrice":      json.Number(formatAmount(l.Price.From)),
+			"maxPrice":      json.Number(formatAmount(l.Price.To)),
+			"priceCurrency": currency,
+			"unitCode":      "MON",
+		},
+	}}
+	if l.PromotionalPrice != nil {
+		offers = append(offers, jsonLD{
+			"@type":            "Offer",
+			"businessFunction": "http://purl.org/goodrelations/v1#LeaseOut",
+			"description":      "Promotional price",
+			"price":            json.Number(formatAmount(*l.PromotionalPrice)),
+			"priceCurrency":    currency,
+		})
+	}
+
+	address := jsonLD{"@type": "PostalAddress", "streetAddress": l.Address}
+	return jsonLD{
+		"@type":        "Apartment",
+		"@id":          "urn:uuid:" + l.ID.String(),
+		"identifier":   l.ID.String(),
+		"name":         l.Type.String() + " at " + l.Building,
+		"address":      address,
+		"image":        []string(l.Images),
+		"dateModified": l.LastUpdate.UTC().Format(time.RFC3339),
+		"containedInPlace": jsonLD{
+			"@type":   "ApartmentComplex",
+			"@id":     "urn:uuid:" + l.BuildingID.String(),
+			"name":    l.Building,
+			"address": address,
+			"containedInPlace": jsonLD{
+				"@type": "Place",
+				"@id":   "urn:uuid:" + l.NeighborhoodID.String(),
+				"name":  l.Neighborhood,
+			},
+		},
+		"offers": offers,
+	}
+}
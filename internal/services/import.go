@@ -0,0 +1,519 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// MaxImportRows caps the number of rows a single import may contain.
+const MaxImportRows = 10000
+
+// importField is a column an import reads from each row.
+type importField struct {
+	name     string
+	required bool
+}
+
+// importFields lists the columns read for each entity. Rows are matched to
+// existing listings by natural key: a neighborhood by name, a building by
+// neighborhood and name, an apartment by neighborhood, building and type.
+var importFields = map[models.ImportEntity][]importField{
+	models.ImportNeighborhoods: {
+		{name: "name", required: true},
+	},
+	models.ImportBuildings: {
+		{name: "neighborhood", required: true},
+		{name: "name", required: true},
+		{name: "address", required: true},
+	},
+	models.ImportApartments: {
+		{name: "neighborhood", required: true},
+		{name: "building", required: true},
+		{name: "type", required: true},
+		{name: "price_from", required: true},
+		{name: "price_to", required: true},
+		{name: "promotional_price"},
+		{name: "images"},
+		{name: "videos"},
+	},
+}
+
+// ImportResult is an import together with the report on each of its rows.
+type ImportResult struct {
+	models.Import
+	Report []models.ImportRow `json:"report"`
+}
+
+// rowError rejects a single row of an import; other errors abort the import.
+type rowError string
+
+// Error implements error.
+func (e rowError) Error() string {
+	return string(e)
+}
+
+// importRecord is one row of an uploaded file, read through the column mapping.
+// line is the line of the file the row starts on.
+type importRecord struct {
+	line    int
+	cells   []string
+	columns map[string]int
+}
+
+// get returns the trimmed cell of the named field, or "" if the row is short
+// or the optional column is absent. The ' that exports put before text that
+// spreadsheets would take for a formula is dropped.
+func (r importRecord) get(field string) string {
+	i, ok := r.columns[field]
+	if !ok || i >= len(r.cells) {
+		return ""
+	}
+	cell := strings.TrimSpace(r.cells[i])
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(cell[1])) {
+		return strings.TrimSpace(cell[1:])
+	}
+	return cell
+}
+
+// importPlan is what an import does with a valid row. key is the row's
+// natural key, used to reject rows that repeat an earlier one; apply writes
+// the row and returns the listing's ID.
+type importPlan struct {
+	key    string
+	action models.ImportAction
+	id     *uuid.UUID
+	apply  func(ctx context.Context) (uuid.UUID, error)
+}
+
+// ImportService handles business logic for CSV imports of listings.
+type ImportService struct {
+	repo             repositories.ImportRepository
+	neighborhoodRepo repositories.NeighborhoodRepository
+	buildingRepo     repositories.BuildingRepository
+	apartmentRepo    repositories.ApartmentRepository
+	neighborhoods    *NeighborhoodService
+	buildings        *BuildingService
+	apartments       *ApartmentService
+	tx               repositories.TxManager
+}
+
+// NewImportService creates a new import service. Rows are written through the
+// listing services, so imports record the same events and audit entries as the
+// rest of the API.
+func NewImportService(repo repositories.ImportRepository, neighborhoodRepo repositories.NeighborhoodRepository, buildingRepo repositories.BuildingRepository, apartmentRepo repositories.ApartmentRepository, neighborhoods *NeighborhoodService, buildings *BuildingService, apartments *ApartmentService, tx repositories.TxManager) *ImportService {
+	return &ImportService{
+		repo:             repo,
+		neighborhoodRepo: neighborhoodRepo,
+		buildingRepo:     buildingRepo,
+		apartmentRepo:    apartmentRepo,
+		neighborhoods:    neighborhoods,
+		buildings:        buildings,
+		apartments:       apartments,
+		tx:               tx,
+	}
+}
+
+// Import reads a CSV file of neighborhoods, buildings or apartments and
+// creates or updates one listing per row. mapping names the header of the
+// column holding each field when it differs from the field name. Every row is
+// validated first: if any is rejected nothing is written, and a dry run never
+// writes. Either way the import is recorded with its rejected rows.
+func (s *ImportService) Import(ctx context.Context, entity models.ImportEntity, mapping map[string]string, data io.Reader, dryRun bool) (ImportResult, error) {
+	fields, ok := importFields[entity]
+	if !ok {
+		return ImportResult{}, apperrors.NewParamError("entity")
+	}
+	for field := range mapping {
+		if !slices.ContainsFunc(fields, func(f importField) bool { return f.name == field }) {
+			return ImportResult{}, apperrors.NewParamError("mapping[" + field + "]")
+		}
+	}
+
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return ImportResult{}, apperrors.ErrInvalidInput
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	columns, err := importColumns(fields, header, mapping)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	// Read the whole file before the transaction starts, so a slow upload
+	// does not hold it open.
+	var records []importRecord
+	for {
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ImportResult{}, apperrors.ErrInvalidInput
+		}
+		if !slices.ContainsFunc(cells, func(cell string) bool { return strings.TrimSpace(cell) != "" }) {
+			continue
+		}
+		if len(records) == MaxImportRows {
+			return ImportResult{}, apperrors.ErrInvalidInput
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, importRecord{line: line, cells: cells, columns: columns})
+	}
+
+	result := ImportResult{
+		Import: models.Import{
+			ID:        uuid.New(),
+			Entity:    entity,
+			DryRun:    dryRun,
+			Rows:      len(records),
+			Header:    header,
+			Actor:     requestctx.Actor(ctx),
+			CreatedAt: time.Now().UTC(),
+		},
+		Report: []models.ImportRow{},
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var plans []importPlan
+		var rejected []models.ImportError
+		seen := make(map[string]int)
+		for _, record := range records {
+			plan, err := s.plan(ctx, entity, record)
+			if err == nil {
+				if first, ok := seen[plan.key]; ok {
+					err = rowError(fmt.Sprintf("duplicates line %d", first))
+				} else {
+					seen[plan.key] = record.line
+				}
+			}
+			var rowErr rowError
+			if errors.As(err, &rowErr) {
+				result.Failed++
+				result.Report = append(result.Report, models.ImportRow{Line: record.line, Error: rowErr.Error()})
+				rejected = append(rejected, models.ImportError{Line: record.line, Cells: record.cells, Error: rowErr.Error()})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			switch plan.action {
+			case models.ImportCreate:
+				result.Created++
+			case models.ImportUpdate:
+				result.Updated++
+			case models.ImportUnchanged:
+				result.Unchanged++
+			}
+			plans = append(plans, plan)
+			result.Report = append(result.Report, models.ImportRow{Line: record.line, Action: plan.action, ID: plan.id})
+		}
+
+		switch {
+		case result.Failed > 0:
+			result.Status = models.ImportFailed
+		case dryRun:
+			result.Status = models.ImportValidated
+		default:
+			result.Status = models.ImportCompleted
+			for i, plan := range plans {
+				if plan.apply == nil {
+					continue
+				}
+				id, err := plan.apply(ctx)
+				if err != nil {
+					return err
+				}
+				result.Report[i].ID = &id
+			}
+		}
+		return s.repo.Save(ctx, result.Import, rejected)
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	return result, nil
+}
+
+// GetImport retrieves an import by ID.
+func (s *ImportService) GetImport(ctx context.Context, id string) (models.Import, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Import{}, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ImportErrors retrieves an import and the rows it rejected.
+func (s *ImportService) ImportErrors(ctx context.Context, id string) (models.Import, []models.ImportError, error) {
+	imp, err := s.GetImport(ctx, id)
+	if err != nil {
+		return models.Import{}, nil, err
+	}
+
+	rejected, err := s.repo.ListErrors(ctx, id)
+	if err != nil {
+		return models.Import{}, nil, err
+	}
+	return imp, rejected, nil
+}
+
+// ListImports retrieves a page of imports, most recent first.
+func (s *ImportService) ListImports(ctx context.Context, params ListParams) (ListPage[models.Import], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Import], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// importColumns finds the column of each field in the header row, matching
+// the mapped header, or else the field name, regardless of case. A required
+// field without a column is reported as the mapping parameter to fix.
+func importColumns(fields []importField, header []string, mapping map[string]string) (map[string]int, error) {
+	columns := make(map[string]int, len(fields))
+	for _, field := range fields {
+		name := field.name
+		if mapped, ok := mapping[field.name]; ok {
+			name = mapped
+		}
+		i := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) })
+		if i >= 0 {
+			columns[field.name] = i
+		} else if field.required {
+			return nil, apperrors.NewParamError("mapping[" + field.name + "]")
+		}
+	}
+	return columns, nil
+}
+
+// plan validates a row and decides what importing it does.
+func (s *ImportService) plan(ctx context.Context, entity models.ImportEntity, record importRecord) (importPlan, error) {
+	switch entity {
+	case models.ImportNeighborhoods:
+		return s.planNeighborhood(ctx, record)
+	case models.ImportBuildings:
+		return s.planBuilding(ctx, record)
+	default:
+		return s.planApartment(ctx, record)
+	}
+}
+
+// planNeighborhood matches a neighborhood row by name.
+func (s *ImportService) planNeighborhood(ctx context.Context, record importRecord) (importPlan, error) {
+	name := record.get("name")
+	if _, err := models.NewNeighborhood(uuid.New(), name); err != nil {
+		return importPlan{}, rowError(err.Error())
+	}
+
+	matches, err := s.neighborhoodRepo.FindByName(ctx, name)
+	if err != nil {
+		return importPlan{}, err
+	}
+	existing, err := matchOne(matches, fmt.Sprintf("more than one neighborhood is named %q", name))
+	if err != nil {
+		return importPlan{}, err
+	}
+	plan := importPlan{key: strings.ToLower(name)}
+	switch {
+	case existing == nil:
+		plan.action = models.ImportCreate
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			neighborhood, err := s.neighborhoods.CreateNeighborhood(ctx, name)
+			return neighborhood.ID, err
+		}
+	case existing.Name == name:
+		plan.action, plan.id = models.ImportUnchanged, &existing.ID
+	default:
+		plan.action, plan.id = models.ImportUpdate, &existing.ID
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			neighborhood, err := s.neighborhoods.UpdateNeighborhood(ctx, existing.ID.String(), name, existing.Version)
+			return neighborhood.ID, err
+		}
+	}
+	return plan, nil
+}
+
+// planBuilding matches a building row by neighborhood and name.
+func (s *ImportService) planBuilding(ctx context.Context, record importRecord) (importPlan, error) {
+	neighborhood, err := s.findNeighborhood(ctx, record.get("neighborhood"))
+	if err != nil {
+		return importPlan{}, err
+	}
+	name, address := record.get("name"), record.get("address")
+	if _, err := models.NewBuilding(uuid.New(), name, neighborhood.ID, address); err != nil {
+		return importPlan{}, rowError(err.Error())
+	}
+
+	matches, err := s.buildingRepo.FindByName(ctx, neighborhood.ID, name)
+	if err != nil {
+		return importPlan{}, err
+	}
+	existing, err := matchOne(matches, fmt.Sprintf("more than one building in neighborhood %q is named %q", neighborhood.Name, name))
+	if err != nil {
+		return importPlan{}, err
+	}
+	plan := importPlan{key: neighborhood.ID.String() + "/" + strings.ToLower(name)}
+	switch {
+	case existing == nil:
+		plan.action = models.ImportCreate
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			building, err := s.buildings.CreateBuilding(ctx, name, neighborhood.ID.String(), address)
+			return building.ID, err
+		}
+	case existing.Name == name && existing.Address == address:
+		plan.action, plan.id = models.ImportUnchanged, &existing.ID
+	default:
+		plan.action, plan.id = models.ImportUpdate, &existing.ID
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			building, err := s.buildings.UpdateBuilding(ctx, existing.ID.String(), name, neighborhood.ID.String(), address, existing.Version)
+			return building.ID, err
+		}
+	}
+	return plan, nil
+}
+
+// planApartment matches an apartment row by neighborhood, building and type.
+func (s *ImportService) planApartment(ctx context.Context, record importRecord) (importPlan, error) {
+	neighborhood, err := s.findNeighborhood(ctx, record.get("neighborhood"))
+	if err != nil {
+		return importPlan{}, err
+	}
+	building, err := s.findBuilding(ctx, neighborhood, record.get("building"))
+	if err != nil {
+		return importPlan{}, err
+	}
+
+	input := ApartmentInput{
+		BuildingID: building.ID.String(),
+		Type:       record.get("type"),
+		Images:     strings.Fields(record.get("images")),
+		Videos:     strings.Fields(record.get("videos")),
+	}
+	if input.PriceFrom, err = importInt(record, "price_from"); err != nil {
+		return importPlan{}, err
+	}
+	if input.PriceTo, err = importInt(record, "price_to"); err != nil {
+		return importPlan{}, err
+	}
+	if record.get("promotional_price") != "" {
+		promotionalPrice, err := importInt(record, "promotional_price")
+		if err != nil {
+			return importPlan{}, err
+		}
+		input.PromotionalPrice = &promotionalPrice
+	}
+	price := models.PriceRange{From: input.PriceFrom, To: input.PriceTo}
+	apartment, err := models.NewApartment(uuid.New(), building.ID, models.ApartmentType(input.Type), price, input.PromotionalPrice, input.Images, input.Videos, time.Now().UTC())
+	if err != nil {
+		return importPlan{}, rowError(err.Error())
+	}
+
+	matches, err := s.apartmentRepo.FindByType(ctx, building.ID, apartment.Type)
+	if err != nil {
+		return importPlan{}, err
+	}
+	existing, err := matchOne(matches, fmt.Sprintf("building %q has more than one %s apartment", building.Name, apartment.Type))
+	if err != nil {
+		return importPlan{}, err
+	}
+	plan := importPlan{key: building.ID.String() + "/" + string(apartment.Type)}
+	switch {
+	case existing == nil:
+		plan.action = models.ImportCreate
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			apartment, err := s.apartments.CreateApartment(ctx, input)
+			return apartment.ID, err
+		}
+	case existing.Price == apartment.Price && equalPrice(existing.PromotionalPrice, apartment.PromotionalPrice) &&
+		slices.Equal(existing.Images, apartment.Images) && slices.Equal(existing.Videos, apartment.Videos):
+		plan.action, plan.id = models.ImportUnchanged, &existing.ID
+	default:
+		plan.action, plan.id = models.ImportUpdate, &existing.ID
+		input.Available = existing.Available
+		plan.apply = func(ctx context.Context) (uuid.UUID, error) {
+			apartment, err := s.apartments.UpdateApartment(ctx, existing.ID.String(), input, existing.Version)
+			return apartment.ID, err
+		}
+	}
+	return plan, nil
+}
+
+// findNeighborhood looks up the neighborhood a row refers to by name.
+func (s *ImportService) findNeighborhood(ctx context.Context, name string) (models.Neighborhood, error) {
+	matches, err := s.neighborhoodRepo.FindByName(ctx, name)
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+	neighborhood, err := matchOne(matches, fmt.Sprintf("more than one neighborhood is named %q", name))
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+	if neighborhood == nil {
+		return models.Neighborhood{}, rowError(fmt.Sprintf("neighborhood %q not found", name))
+	}
+	return *neighborhood, nil
+}
+
+// findBuilding looks up the building of a neighborhood a row refers to by name.
+func (s *ImportService) findBuilding(ctx context.Context, neighborhood models.Neighborhood, name string) (models.Building, error) {
+	matches, err := s.buildingRepo.FindByName(ctx, neighborhood.ID, name)
+	if err != nil {
+		return models.Building{}, err
+	}
+	building, err := matchOne(matches, fmt.Sprintf("more than one building in neighborhood %q is named %q", neighborhood.Name, name))
+	if err != nil {
+		return models.Building{}, err
+	}
+	if building == nil {
+		return models.Building{}, rowError(fmt.Sprintf("building %q not found in neighborhood %q", name, neighborhood.Name))
+	}
+	return *building, nil
+}
+
+// matchOne returns the only listing matching a natural key, or nil if none
+// does. Several matches reject the row with the ambiguous message.
+func matchOne[T any](matches []T, ambiguous string) (*T, error) {
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, rowError(ambiguous)
+	}
+}
+
+// importInt parses a whole-number cell such as a price in cents.
+func importInt(record importRecord, field string) (int64, error) {
+	value, err := strconv.ParseInt(record.get(field), 10, 64)
+	if err != nil {
+		return 0, rowError(field + " must be a whole number")
+	}
+	return value, nil
+}
+
+// equalPrice reports whether two optional prices are the same.
+func equalPrice(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,84 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// InquiryService handles business logic for inquiries.
+type InquiryService struct {
+	repo         repositories.InquiryRepository
+	buildingRepo repositories.BuildingRepository
+	audit        repositories.AuditRepository
+	tx           repositories.TxManager
+}
+
+// NewInquiryService creates a new inquiry service.
+func NewInquiryService(repo repositories.InquiryRepository, buildingRepo repositories.BuildingRepository, audit repositories.AuditRepository, tx repositories.TxManager) *InquiryService {
+	return &InquiryService{repo: repo, buildingRepo: buildingRepo, audit: audit, tx: tx}
+}
+
+// CreateInquiry records an inquiry about a building. The source is the actor
+// that submitted it, such as a partner's API key.
+func (s *InquiryService) CreateInquiry(ctx context.Context, buildingID string, name string, email string, phone string, message string) (models.Inquiry, error) {
+	buildingUUID, err := utils.ValidateFieldID("building_id", buildingID)
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	// Check if building exists
+	_, err = s.buildingRepo.GetByID(ctx, buildingID)
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	inquiry, err := models.NewInquiry(uuid.New(), buildingUUID, name, email, phone, message, requestctx.Actor(ctx), time.Now().UTC())
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, inquiry); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityInquiry, inquiry.ID, nil, inquiry)
+	})
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	return inquiry, nil
+}
+
+// GetInquiry retrieves an inquiry by ID.
+func (s *InquiryService) GetInquiry(ctx context.Context, id string) (models.Inquiry, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Inquiry{}, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListInquiries retrieves a page of inquiries, newest first, optionally for a single building.
+func (s *InquiryService) ListInquiries(ctx context.Context, buildingID string, params ListParams) (ListPage[models.Inquiry], error) {
+	var buildingUUID uuid.UUID
+	if buildingID != "" {
+		parsedID, err := utils.ValidateID(buildingID)
+		if err != nil {
+			return ListPage[models.Inquiry]{}, err
+		}
+		buildingUUID = parsedID
+	}
+
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Inquiry], error) {
+		return s.repo.List(ctx, buildingUUID, query)
+	})
+}
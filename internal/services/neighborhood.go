@@ -3,7 +3,10 @@ package services
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
 	"github.com/Andre385/bruschirentals-backend/internal/models"
 	"github.com/Andre385/bruschirentals-backend/internal/repositories"
 	"github.com/Andre385/bruschirentals-backend/internal/utils"
@@ -12,12 +15,15 @@ import (
 
 // NeighborhoodService handles business logic for neighborhoods.
 type NeighborhoodService struct {
-	repo repositories.NeighborhoodRepository
+	repo   repositories.NeighborhoodRepository
+	outbox repositories.OutboxRepository
+	audit  repositories.AuditRepository
+	tx     repositories.TxManager
 }
 
 // NewNeighborhoodService creates a new neighborhood service.
-func NewNeighborhoodService(repo repositories.NeighborhoodRepository) *NeighborhoodService {
-	return &NeighborhoodService{repo: repo}
+func NewNeighborhoodService(repo repositories.NeighborhoodRepository, outbox repositories.OutboxRepository, audit repositories.AuditRepository, tx repositories.TxManager) *NeighborhoodService {
+	return &NeighborhoodService{repo: repo, outbox: outbox, audit: audit, tx: tx}
 }
 
 // CreateNeighborhood creates a new neighborhood with validation.
@@ -27,8 +33,17 @@ func (s *NeighborhoodService) CreateNeighborhood(ctx context.Context, name strin
 	if err != nil {
 		return models.Neighborhood{}, err
 	}
+	neighborhood.UpdatedAt = time.Now().UTC()
 
-	err = s.repo.Save(ctx, neighborhood)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, neighborhood); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventNeighborhoodCreated, neighborhood.ID, neighborhood); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityNeighborhood, neighborhood.ID, nil, neighborhood)
+	})
 	if err != nil {
 		return models.Neighborhood{}, err
 	}
@@ -51,8 +66,9 @@ func (s *NeighborhoodService) GetNeighborhood(ctx context.Context, id string) (m
 	return neighborhood, nil
 }
 
-// UpdateNeighborhood updates an existing neighborhood with validation.
-func (s *NeighborhoodService) UpdateNeighborhood(ctx context.Context, id string, name string) (models.Neighborhood, error) {
+// UpdateNeighborhood updates an existing neighborhood with validation. A
+// non-zero version must match the stored one.
+func (s *NeighborhoodService) UpdateNeighborhood(ctx context.Context, id string, name string, version int64) (models.Neighborhood, error) {
 	// Validate ID
 	neighborhoodUUID, err := utils.ValidateID(id)
 	if err != nil {
@@ -60,17 +76,30 @@ func (s *NeighborhoodService) UpdateNeighborhood(ctx context.Context, id string,
 	}
 
 	// Check if neighborhood exists
-	_, err = s.repo.GetByID(ctx, id)
+	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return models.Neighborhood{}, err
 	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Neighborhood{}, err
+	}
 
 	neighborhood, err := models.NewNeighborhood(neighborhoodUUID, name)
 	if err != nil {
 		return models.Neighborhood{}, err
 	}
+	neighborhood.Version = existing.Version + 1
+	neighborhood.UpdatedAt = time.Now().UTC()
 
-	err = s.repo.Save(ctx, neighborhood)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, neighborhood); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, s.outbox, models.EventNeighborhoodUpdated, neighborhood.ID, neighborhood); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityNeighborhood, neighborhood.ID, existing, neighborhood)
+	})
 	if err != nil {
 		return models.Neighborhood{}, err
 	}
@@ -78,17 +107,184 @@ func (s *NeighborhoodService) UpdateNeighborhood(ctx context.Context, id string,
 	return neighborhood, nil
 }
 
-// DeleteNeighborhood removes a neighborhood by ID.
-func (s *NeighborhoodService) DeleteNeighborhood(ctx context.Context, id string) error {
+// PatchNeighborhood applies a JSON Merge Patch to a neighborhood and saves the
+// validated result. A non-zero version must match the stored one.
+func (s *NeighborhoodService) PatchNeighborhood(ctx context.Context, id string, patch []byte, version int64) (models.Neighborhood, error) {
 	_, err := utils.ValidateID(id)
 	if err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+	if err := checkVersion(version, existing.Version); err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	fields := struct {
+		Name string `json:"name"`
+	}{Name: existing.Name}
+	if err := applyMergePatch(&fields, patch); err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	return s.UpdateNeighborhood(ctx, id, fields.Name, existing.Version)
+}
+
+// DeleteNeighborhood removes a neighborhood by ID. A non-zero version must
+// match the stored one. Unless cascade is set, a neighborhood that still has
+// buildings is refused with a DependentsError counting them; with cascade its
+// buildings and their apartments go to the trash with it.
+func (s *NeighborhoodService) DeleteNeighborhood(ctx context.Context, id string, version int64, cascade bool) error {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, existing.Version); err != nil {
+			return err
+		}
+		if !cascade {
+			dependents, err := s.repo.CountDependents(ctx, id)
+			if err != nil {
+				return err
+			}
+			if dependents.Buildings > 0 {
+				return &apperrors.DependentsError{Dependents: dependents}
+			}
+		}
+		return s.delete(ctx, existing)
+	})
+}
+
+// ReassignNeighborhood moves every building of a neighborhood to another one
+// and then deletes the emptied neighborhood, all in one transaction. A non-zero
+// version must match the stored version of the deleted neighborhood.
+func (s *NeighborhoodService) ReassignNeighborhood(ctx context.Context, id string, targetID string, version int64) error {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return err
+	}
+	_, err = utils.ValidateFieldID("neighborhood_id", targetID)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(id, targetID) {
+		return apperrors.NewFieldError("neighborhood_id", apperrors.CodeInvalid, "must differ from the neighborhood reassigned")
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, existing.Version); err != nil {
+			return err
+		}
+		if _, err := s.repo.GetByID(ctx, targetID); err != nil {
+			return err
+		}
+
+		buildings, err := s.repo.MoveBuildings(ctx, id, targetID)
+		if err != nil {
+			return err
+		}
+		for _, building := range buildings {
+			before := building
+			before.NeighborhoodID = existing.ID
+			before.Version--
+			if err := recordEvent(ctx, s.outbox, models.EventBuildingUpdated, building.ID, building); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityBuilding, building.ID, before, building); err != nil {
+				return err
+			}
+		}
+		return s.delete(ctx, existing)
+	})
+}
+
+// delete moves a neighborhood to the trash and records its deletion and that of
+// the buildings and apartments trashed with it. It must run inside a
+// transaction.
+func (s *NeighborhoodService) delete(ctx context.Context, existing models.Neighborhood) error {
+	buildings, apartments, err := s.repo.Delete(ctx, existing.ID.String(), existing.Version)
+	if err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, s.outbox, models.EventNeighborhoodDeleted, existing.ID, deletedEntity{ID: existing.ID}); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, s.audit, models.AuditDelete, models.EntityNeighborhood, existing.ID, existing, nil); err != nil {
 		return err
 	}
+	return recordTrashed(ctx, s.outbox, s.audit, buildings, apartments)
+}
+
+// RestoreNeighborhood takes a neighborhood out of the trash together with the
+// buildings and apartments deleted with it.
+func (s *NeighborhoodService) RestoreNeighborhood(ctx context.Context, id string) (models.Neighborhood, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	var neighborhood models.Neighborhood
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		restored, buildings, apartments, err := s.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+		neighborhood = restored
+		if err := recordEvent(ctx, s.outbox, models.EventNeighborhoodRestored, neighborhood.ID, neighborhood); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, models.AuditRestore, models.EntityNeighborhood, neighborhood.ID, nil, neighborhood); err != nil {
+			return err
+		}
+		return recordRestored(ctx, s.outbox, s.audit, buildings, apartments)
+	})
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	return neighborhood, nil
+}
+
+// ListNeighborhoods retrieves a page of neighborhoods ordered by name.
+func (s *NeighborhoodService) ListNeighborhoods(ctx context.Context, params ListParams) (ListPage[models.Neighborhood], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Neighborhood], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// GetListedNeighborhood retrieves a neighborhood by ID if it has an available apartment.
+func (s *NeighborhoodService) GetListedNeighborhood(ctx context.Context, id string) (models.Neighborhood, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Neighborhood{}, err
+	}
+
+	return s.repo.GetListed(ctx, id)
+}
 
-	return s.repo.Delete(ctx, id)
+// ListListedNeighborhoods retrieves a page of the neighborhoods that have an available
+// apartment, ordered by name.
+func (s *NeighborhoodService) ListListedNeighborhoods(ctx context.Context, params ListParams) (ListPage[models.Neighborhood], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Neighborhood], error) {
+		return s.repo.ListListed(ctx, query)
+	})
 }
 
-// ListNeighborhoods retrieves all neighborhoods.
-func (s *NeighborhoodService) ListNeighborhoods(ctx context.Context) ([]models.Neighborhood, error) {
-	return s.repo.List(ctx)
+// NeighborhoodsLastModified returns when any neighborhood was last saved, deleted or
+// restored, or the zero time if there never was one.
+func (s *NeighborhoodService) NeighborhoodsLastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
 }
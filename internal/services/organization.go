@@ -0,0 +1,117 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// OrganizationService handles business logic for organizations (tenants).
+type OrganizationService struct {
+	repo     repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+	audit    repositories.AuditRepository
+	tx       repositories.TxManager
+}
+
+// NewOrganizationService creates a new organization service.
+func NewOrganizationService(repo repositories.OrganizationRepository, userRepo repositories.UserRepository, audit repositories.AuditRepository, tx repositories.TxManager) *OrganizationService {
+	return &OrganizationService{repo: repo, userRepo: userRepo, audit: audit, tx: tx}
+}
+
+// CreateOrganization onboards a new organization together with its first admin
+// user. Only the platform organization can create organizations.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, slug string, name string, adminEmail string, adminPassword string) (models.Organization, error) {
+	if !isPlatformTenant(ctx) {
+		return models.Organization{}, apperrors.ErrForbidden
+	}
+
+	now := time.Now().UTC()
+	organization, err := models.NewOrganization(uuid.New(), slug, name, now)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	// The admin's fields are sent as admin_email and admin_password
+	hash, err := hashPassword(adminPassword)
+	if err != nil {
+		return models.Organization{}, apperrors.PrefixFields("admin_", err)
+	}
+
+	admin, err := models.NewUser(uuid.New(), organization.ID, normalizeEmail(adminEmail), hash, models.RoleAdmin, now)
+	if err != nil {
+		return models.Organization{}, apperrors.PrefixFields("admin_", err)
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, organization); err != nil {
+			return err
+		}
+		if err := s.userRepo.Save(ctx, admin); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityOrganization, organization.ID, nil, organization)
+	})
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// GetOrganization retrieves an organization by ID. Organizations other than the
+// platform can only see themselves.
+func (s *OrganizationService) GetOrganization(ctx context.Context, id string) (models.Organization, error) {
+	organizationID, err := utils.ValidateID(id)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	if !isPlatformTenant(ctx) {
+		if tenantID, _ := requestctx.TenantID(ctx); tenantID != organizationID {
+			return models.Organization{}, apperrors.ErrNotFound
+		}
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetOrganizationBySlug retrieves an organization by slug. It is not limited to
+// the caller's organization: the public API uses it to find whose listings a
+// request is for.
+func (s *OrganizationService) GetOrganizationBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	return s.repo.GetBySlug(ctx, slug)
+}
+
+// ListOrganizations retrieves a page of every organization for the platform, and
+// only the caller's own organization for everyone else.
+func (s *OrganizationService) ListOrganizations(ctx context.Context, params ListParams) (ListPage[models.Organization], error) {
+	if isPlatformTenant(ctx) {
+		return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.Organization], error) {
+			return s.repo.List(ctx, query)
+		})
+	}
+
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return ListPage[models.Organization]{}, apperrors.ErrForbidden
+	}
+	organization, err := s.repo.GetByID(ctx, tenantID.String())
+	if err != nil {
+		return ListPage[models.Organization]{}, err
+	}
+	return ListPage[models.Organization]{Data: []models.Organization{organization}}, nil
+}
+
+// isPlatformTenant reports whether the request acts for the platform organization.
+func isPlatformTenant(ctx context.Context) bool {
+	tenantID, ok := requestctx.TenantID(ctx)
+	return ok && tenantID == models.DefaultOrganizationID
+}
@@ -0,0 +1,81 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+)
+
+const (
+	// DefaultPageSize is used when a page is requested without a limit.
+	DefaultPageSize = 50
+	// MaxPageSize caps the number of items returned per page.
+	MaxPageSize = repositories.MaxPageSize
+)
+
+// ListParams filters, sorts and selects the page of a list to return. A zero
+// Limit returns the first MaxPageSize items.
+type ListParams struct {
+	Filters []repositories.Filter
+	Sort    string
+	Limit   int
+	Cursor  string
+}
+
+// ListPage is one page of a list. NextCursor is null on the last page.
+type ListPage[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// listQuery validates the list parameters and caps the page size.
+func listQuery(params ListParams) (repositories.ListQuery, error) {
+	if params.Limit < 0 {
+		return repositories.ListQuery{}, apperrors.NewParamError("limit")
+	}
+	limit := params.Limit
+	if limit == 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return repositories.ListQuery{Filters: params.Filters, Sort: params.Sort, Limit: limit, Cursor: params.Cursor}, nil
+}
+
+// listPage converts a repository page to its response form.
+func listPage[T any](page repositories.Page[T]) ListPage[T] {
+	result := ListPage[T]{Data: page.Items}
+	if page.NextCursor != "" {
+		result.NextCursor = &page.NextCursor
+	}
+	return result
+}
+
+// fetchPage validates the list parameters and fetches the page from a repository.
+func fetchPage[T any](params ListParams, list func(repositories.ListQuery) (repositories.Page[T], error)) (ListPage[T], error) {
+	query, err := listQuery(params)
+	if err != nil {
+		return ListPage[T]{}, err
+	}
+	result, err := list(query)
+	if err != nil {
+		return ListPage[T]{}, err
+	}
+	return listPage(result), nil
+}
+
+// listAll reads a list page by page and returns every item, for internal
+// callers that need the whole list.
+func listAll[T any](list func(repositories.ListQuery) (repositories.Page[T], error)) ([]T, error) {
+	var items []T
+	query := repositories.ListQuery{Limit: MaxPageSize}
+	for {
+		page, err := list(query)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
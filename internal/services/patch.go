@@ -0,0 +1,62 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+)
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) to the editable fields
+// in target: members of the patch replace fields, null members clear them and
+// absent ones keep their stored value. The patch must be an object naming only
+// fields of target.
+func applyMergePatch[T any](target *T, patch []byte) error {
+	var changes map[string]any
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return apperrors.ErrInvalidInput
+	}
+
+	current, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var document map[string]any
+	if err := json.Unmarshal(current, &document); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(document, changes))
+	if err != nil {
+		return err
+	}
+	var result T
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return apperrors.ErrInvalidInput
+	}
+	*target = result
+	return nil
+}
+
+// mergePatch merges patch into document as RFC 7396 describes.
+func mergePatch(document any, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	fields, ok := document.(map[string]any)
+	if !ok {
+		fields = map[string]any{}
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(fields, name)
+		} else {
+			fields[name] = mergePatch(fields[name], value)
+		}
+	}
+	return fields
+}
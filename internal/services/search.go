@@ -0,0 +1,49 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"strings"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+)
+
+const (
+	// defaultSearchLimit is used when no limit is requested.
+	defaultSearchLimit = 20
+	// maxSearchLimit caps the number of search results returned.
+	maxSearchLimit = 100
+	// maxSearchQueryLength caps the length of a search query.
+	maxSearchQueryLength = 200
+)
+
+// SearchService handles full-text and fuzzy search across listings.
+type SearchService struct {
+	repo repositories.SearchRepository
+}
+
+// NewSearchService creates a new search service.
+func NewSearchService(repo repositories.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search returns ranked neighborhoods, buildings and addresses matching query.
+func (s *SearchService) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > maxSearchQueryLength {
+		return nil, apperrors.NewParamError("q")
+	}
+	if limit < 0 {
+		return nil, apperrors.NewParamError("limit")
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	return s.repo.Search(ctx, query, limit)
+}
@@ -0,0 +1,57 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// TrashService lists deleted listings and purges them once their retention
+// period is over. Restoring is done by the service of each entity.
+type TrashService struct {
+	repo      repositories.TrashRepository
+	retention time.Duration
+}
+
+// NewTrashService creates a new trash service that keeps deleted rows for retention.
+func NewTrashService(repo repositories.TrashRepository, retention time.Duration) *TrashService {
+	return &TrashService{repo: repo, retention: retention}
+}
+
+// ListTrash retrieves a page of deleted neighborhoods, buildings and
+// apartments, most recently deleted first.
+func (s *TrashService) ListTrash(ctx context.Context, params ListParams) (ListPage[models.TrashItem], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.TrashItem], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// PurgeExpired permanently deletes rows that have been in the trash longer
+// than the retention period and returns how many were deleted.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-s.retention))
+}
+
+// Run purges expired rows every interval until the context is cancelled.
+func (s *TrashService) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeExpired(ctx)
+			if err != nil {
+				logger.Error("Failed to purge trash", zap.Error(err))
+			} else if purged > 0 {
+				logger.Info("Purged trash", zap.Int64("rows", purged))
+			}
+		}
+	}
+}
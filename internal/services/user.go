@@ -0,0 +1,140 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+// RoleInfo describes a role and the permissions it grants.
+type RoleInfo struct {
+	Name        models.Role         `json:"name"`
+	Permissions []models.Permission `json:"permissions"`
+}
+
+// UserService handles business logic for user accounts and their roles.
+type UserService struct {
+	repo          repositories.UserRepository
+	refreshTokens repositories.RefreshTokenRepository
+	audit         repositories.AuditRepository
+	tx            repositories.TxManager
+}
+
+// NewUserService creates a new user service.
+func NewUserService(repo repositories.UserRepository, refreshTokens repositories.RefreshTokenRepository, audit repositories.AuditRepository, tx repositories.TxManager) *UserService {
+	return &UserService{repo: repo, refreshTokens: refreshTokens, audit: audit, tx: tx}
+}
+
+// ListRoles returns every role with its permissions.
+func (s *UserService) ListRoles() []RoleInfo {
+	roles := make([]RoleInfo, 0, len(models.Roles))
+	for _, role := range models.Roles {
+		roles = append(roles, RoleInfo{Name: role, Permissions: role.Permissions()})
+	}
+	return roles
+}
+
+// CreateUser creates a user of the caller's tenant with the given credentials and role.
+func (s *UserService) CreateUser(ctx context.Context, email string, password string, role string) (models.User, error) {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return models.User{}, apperrors.ErrForbidden
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := models.NewUser(uuid.New(), tenantID, normalizeEmail(email), hash, models.Role(role), time.Now().UTC())
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, user); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityUser, user.ID, nil, user)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// GetUser retrieves a user of the caller's tenant by ID.
+func (s *UserService) GetUser(ctx context.Context, id string) (models.User, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return s.getTenantUser(ctx, id)
+}
+
+// ListUsers retrieves a page of users ordered by email.
+func (s *UserService) ListUsers(ctx context.Context, params ListParams) (ListPage[models.User], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.User], error) {
+		return s.repo.List(ctx, query)
+	})
+}
+
+// SetRole changes the role of a user. Callers cannot change their own role, so
+// an admin cannot lock everyone out by demoting themselves. The user's refresh
+// tokens are revoked so the new role applies once their access token expires.
+func (s *UserService) SetRole(ctx context.Context, id string, role string) (models.User, error) {
+	userID, err := utils.ValidateID(id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if principal, ok := requestctx.Principal(ctx); ok && principal.UserID == userID {
+		return models.User{}, apperrors.ErrForbidden
+	}
+
+	existing, err := s.getTenantUser(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user := existing
+	user.Role = models.Role(role)
+	if err := user.Validate(); err != nil {
+		return models.User{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, user); err != nil {
+			return err
+		}
+		if err := s.refreshTokens.RevokeAllForUser(ctx, user.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityUser, user.ID, existing, user)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// getTenantUser retrieves a user by ID, reporting users of other tenants as not found.
+func (s *UserService) getTenantUser(ctx context.Context, id string) (models.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if tenantID, ok := requestctx.TenantID(ctx); !ok || user.TenantID != tenantID {
+		return models.User{}, apperrors.ErrNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,14 @@
+// Package services provides business logic layer implementations.
+package services
+
+import apperrors "github.com/Andre385/bruschirentals-backend/internal/errors"
+
+// checkVersion returns ErrVersionConflict when a change was based on version
+// expected but the record has moved on to current. A zero expected version,
+// from a request without If-Match, accepts any version.
+func checkVersion(expected int64, current int64) error {
+	if expected != 0 && expected != current {
+		return apperrors.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,434 @@
+// Package services provides business logic layer implementations.
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Andre385/bruschirentals-backend/internal/logging"
+	"github.com/Andre385/bruschirentals-backend/internal/models"
+	"github.com/Andre385/bruschirentals-backend/internal/repositories"
+	"github.com/Andre385/bruschirentals-backend/internal/requestctx"
+	"github.com/Andre385/bruschirentals-backend/internal/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Webhook delivery headers sent with every request.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	// webhookMaxAttempts is how many times a delivery is tried before it is marked failed.
+	webhookMaxAttempts = 8
+	// webhookDisableAfter is how many consecutive failed attempts disable a subscription.
+	webhookDisableAfter = 20
+	// webhookBaseBackoff is the delay before the first retry; it doubles on every attempt.
+	webhookBaseBackoff = 30 * time.Second
+	// webhookMaxBackoff caps the delay between retries.
+	webhookMaxBackoff = 6 * time.Hour
+	// webhookBatchSize is the number of due deliveries processed per poll.
+	webhookBatchSize = 50
+	// webhookRequestTimeout bounds a single delivery HTTP call.
+	webhookRequestTimeout = 10 * time.Second
+	// webhookClaimLease is how long a claimed delivery is left to its worker
+	// before another may attempt it; it outlasts a batch of timed out calls.
+	webhookClaimLease = webhookBatchSize * webhookRequestTimeout * 2
+)
+
+// WebhookEvent is the JSON envelope delivered to webhook subscribers.
+type WebhookEvent struct {
+	ID         uuid.UUID        `json:"id"`
+	Type       models.EventType `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       json.RawMessage  `json:"data"`
+}
+
+// WebhookService handles business logic for webhook subscriptions and deliveries.
+type WebhookService struct {
+	repo   repositories.WebhookRepository
+	audit  repositories.AuditRepository
+	tx     repositories.TxManager
+	client *http.Client
+}
+
+// NewWebhookService creates a new webhook service.
+func NewWebhookService(repo repositories.WebhookRepository, audit repositories.AuditRepository, tx repositories.TxManager) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		audit:  audit,
+		tx:     tx,
+		client: &http.Client{Timeout: webhookRequestTimeout},
+	}
+}
+
+// CreateSubscription creates a new webhook subscription. A random secret is
+// generated when none is given.
+func (s *WebhookService) CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (models.WebhookSubscription, error) {
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return models.WebhookSubscription{}, err
+		}
+		secret = generated
+	}
+
+	subscription, err := models.NewWebhookSubscription(uuid.New(), url, eventTypes, secret, time.Now().UTC())
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SaveSubscription(ctx, subscription); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityWebhookSubscription, subscription.ID, nil, subscription)
+	})
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription retrieves a webhook subscription by ID.
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return s.repo.GetSubscription(ctx, id)
+}
+
+// UpdateSubscription updates the URL, event types and active flag of a subscription.
+// Re-activating a subscription clears its failure count.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id string, url string, eventTypes []string, active bool) (models.WebhookSubscription, error) {
+	_, err := utils.ValidateID(id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	existing, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	subscription := existing
+	subscription.URL = url
+	subscription.EventTypes = eventTypes
+	if active && !subscription.Active {
+		subscription.ConsecutiveFailures = 0
+		subscription.DisabledAt = nil
+	}
+	subscription.Active = active
+
+	if err := subscription.Validate(); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SaveSubscription(ctx, subscription); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditUpdate, models.EntityWebhookSubscription, subscription.ID, existing, subscription)
+	})
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription removes a webhook subscription by ID.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	subscriptionUUID, err := utils.ValidateID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditDelete, models.EntityWebhookSubscription, subscriptionUUID, existing, nil)
+	})
+}
+
+// ListSubscriptions retrieves a page of webhook subscriptions, oldest first.
+func (s *WebhookService) ListSubscriptions(ctx context.Context, params ListParams) (ListPage[models.WebhookSubscription], error) {
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.WebhookSubscription], error) {
+		return s.repo.ListSubscriptions(ctx, query)
+	})
+}
+
+// ListDeliveries retrieves a page of the deliveries made for a subscription,
+// newest first.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID string, params ListParams) (ListPage[models.WebhookDelivery], error) {
+	_, err := utils.ValidateID(subscriptionID)
+	if err != nil {
+		return ListPage[models.WebhookDelivery]{}, err
+	}
+
+	// Check if subscription exists
+	_, err = s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return ListPage[models.WebhookDelivery]{}, err
+	}
+
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.WebhookDelivery], error) {
+		return s.repo.ListDeliveries(ctx, subscriptionID, query)
+	})
+}
+
+// ListAttempts retrieves a page of the HTTP attempts recorded for a delivery,
+// oldest first.
+func (s *WebhookService) ListAttempts(ctx context.Context, deliveryID string, params ListParams) (ListPage[models.WebhookDeliveryAttempt], error) {
+	_, err := utils.ValidateID(deliveryID)
+	if err != nil {
+		return ListPage[models.WebhookDeliveryAttempt]{}, err
+	}
+
+	// Check if delivery exists
+	_, err = s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return ListPage[models.WebhookDeliveryAttempt]{}, err
+	}
+
+	return fetchPage(params, func(query repositories.ListQuery) (repositories.Page[models.WebhookDeliveryAttempt], error) {
+		return s.repo.ListAttempts(ctx, deliveryID, query)
+	})
+}
+
+// ReplayDelivery queues a new delivery with the same payload as an earlier one.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, deliveryID string) (models.WebhookDelivery, error) {
+	_, err := utils.ValidateID(deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	original, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	now := time.Now().UTC()
+	replay := models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  &now,
+		ReplayOf:       &original.ID,
+		CreatedAt:      now,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SaveDelivery(ctx, replay); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.audit, models.AuditCreate, models.EntityWebhookDelivery, replay.ID, nil, replay)
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return replay, nil
+}
+
+// HandleEvent queues a delivery of a domain event for every active subscription
+// that wants it. It is registered with the EventDispatcher.
+func (s *WebhookService) HandleEvent(ctx context.Context, event models.DomainEvent) error {
+	subscriptions, err := s.repo.ListActiveSubscriptions(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(WebhookEvent{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, subscription := range subscriptions {
+		delivery := models.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+		}
+		if err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run delivers due webhooks every interval until the context is cancelled.
+func (s *WebhookService) Run(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ctx = logging.WithLogger(ctx, logger)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ProcessDueDeliveries(ctx); err != nil {
+				logger.Error("Failed to process webhook deliveries", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ProcessDueDeliveries claims the pending deliveries whose retry time has come
+// and attempts each of them. A delivery that cannot be attempted or recorded
+// is logged and left to be taken up again once its claim runs out, without
+// holding up the rest.
+func (s *WebhookService) ProcessDueDeliveries(ctx context.Context) error {
+	now := time.Now().UTC()
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, now.Add(webhookClaimLease), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		ctx := requestctx.WithTenant(ctx, delivery.TenantID)
+		subscription, err := s.repo.GetSubscription(ctx, delivery.SubscriptionID.String())
+		if err == nil {
+			err = s.attempt(ctx, subscription, delivery)
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to attempt webhook delivery", zap.Stringer("delivery_id", delivery.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// attempt sends a delivery once and records the outcome.
+func (s *WebhookService) attempt(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) error {
+	started := time.Now().UTC()
+	status, sendErr := s.send(ctx, subscription, delivery)
+
+	attempt := models.WebhookDeliveryAttempt{
+		ID:          uuid.New(),
+		DeliveryID:  delivery.ID,
+		AttemptedAt: started,
+		DurationMS:  time.Since(started).Milliseconds(),
+	}
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	if sendErr != nil {
+		message := sendErr.Error()
+		attempt.Error = &message
+	} else if !attempt.Succeeded() {
+		message := "unexpected response status " + strconv.Itoa(status)
+		attempt.Error = &message
+	}
+
+	delivery.Attempts++
+	delivery.LastError = attempt.Error
+	switch {
+	case attempt.Succeeded():
+		delivery.Status = models.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := started.Add(webhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := s.repo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		return err
+	}
+
+	return s.repo.RecordSubscriptionResult(ctx, subscription.ID, attempt.Succeeded(), webhookDisableAfter)
+}
+
+// send performs the signed HTTP POST for a delivery and returns the response status.
+func (s *WebhookService) send(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType.String())
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed by secret.
+// Receivers recompute it to verify both the payload and the timestamp header.
+func SignWebhookPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the delay before retrying after the given number of attempts.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// generateWebhookSecret returns a random 32-byte hex secret.
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -17,3 +17,16 @@ func ValidateID(id string) (uuid.UUID, error) {
 	}
 	return parsedID, nil
 }
+
+// ValidateFieldID validates and parses an ID sent in the named request field,
+// reporting a failure against that field.
+func ValidateFieldID(field string, id string) (uuid.UUID, error) {
+	if id == "" {
+		return uuid.Nil, apperrors.NewFieldError(field, apperrors.CodeRequired, "must not be empty")
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, apperrors.NewFieldError(field, apperrors.CodeInvalid, "must be a valid ID")
+	}
+	return parsedID, nil
+}
@@ -0,0 +1,14 @@
+-- Create outbox table for domain events written alongside entity changes
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY,
+    seq BIGSERIAL NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+-- Index unpublished events in publication order
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
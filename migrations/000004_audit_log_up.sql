@@ -0,0 +1,16 @@
+-- Create audit_log table recording every mutation
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    diff JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Index the filters exposed by the audit endpoint
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, created_at DESC);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
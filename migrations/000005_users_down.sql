@@ -0,0 +1,4 @@
+-- Drop refresh_tokens and users tables
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
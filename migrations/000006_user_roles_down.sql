@@ -0,0 +1,2 @@
+-- Drop role from users
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,5 @@
+-- Add role to users; existing accounts keep full access
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('admin', 'manager', 'agent', 'viewer'));
+
+UPDATE users SET role = 'admin';
@@ -0,0 +1,5 @@
+-- Drop inquiries and api_keys tables
+DROP INDEX IF EXISTS idx_inquiries_created_at;
+DROP INDEX IF EXISTS idx_inquiries_building_id;
+DROP TABLE IF EXISTS inquiries;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,29 @@
+-- Drop apartments and tenant columns, then organizations
+DROP INDEX IF EXISTS idx_apartments_tenant_id;
+DROP INDEX IF EXISTS idx_audit_log_tenant_id;
+DROP INDEX IF EXISTS idx_webhook_deliveries_tenant_id;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant_id;
+DROP INDEX IF EXISTS idx_inquiries_tenant_id;
+DROP INDEX IF EXISTS idx_api_keys_tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+DROP INDEX IF EXISTS idx_buildings_tenant_id;
+DROP INDEX IF EXISTS idx_neighborhoods_tenant_id;
+DROP INDEX IF EXISTS idx_apartments_building_id;
+DROP TABLE IF EXISTS apartments;
+
+ALTER TABLE inquiries DROP CONSTRAINT IF EXISTS inquiries_building_tenant_fkey;
+ALTER TABLE buildings DROP CONSTRAINT IF EXISTS buildings_neighborhood_tenant_fkey;
+ALTER TABLE buildings DROP CONSTRAINT IF EXISTS buildings_id_tenant_id_key;
+ALTER TABLE neighborhoods DROP CONSTRAINT IF EXISTS neighborhoods_id_tenant_id_key;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE inquiries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE buildings DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE neighborhoods DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,67 @@
+-- Create organizations table; every row of tenant data belongs to one organization
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Existing data belongs to the default (platform) organization
+INSERT INTO organizations (id, slug, name) VALUES ('00000000-0000-0000-0000-000000000001', 'bruschi', 'Bruschi Rentals');
+
+ALTER TABLE neighborhoods ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE buildings ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE users ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE inquiries ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE webhook_subscriptions ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE audit_log ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE outbox ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id) ON DELETE CASCADE;
+
+-- New rows must name their tenant explicitly
+ALTER TABLE neighborhoods ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE buildings ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE inquiries ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE audit_log ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE outbox ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- References between tenant data must stay within one tenant
+ALTER TABLE neighborhoods ADD CONSTRAINT neighborhoods_id_tenant_id_key UNIQUE (id, tenant_id);
+ALTER TABLE buildings ADD CONSTRAINT buildings_id_tenant_id_key UNIQUE (id, tenant_id);
+ALTER TABLE buildings ADD CONSTRAINT buildings_neighborhood_tenant_fkey
+    FOREIGN KEY (neighborhood_id, tenant_id) REFERENCES neighborhoods(id, tenant_id) ON DELETE CASCADE;
+ALTER TABLE inquiries ADD CONSTRAINT inquiries_building_tenant_fkey
+    FOREIGN KEY (building_id, tenant_id) REFERENCES buildings(id, tenant_id) ON DELETE CASCADE;
+
+-- Create apartments table
+CREATE TABLE apartments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    building_id UUID NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('Studio', 'OneBed', 'TwoBeds', 'ThreeOrMoreBeds')),
+    price_from BIGINT NOT NULL,
+    price_to BIGINT NOT NULL,
+    promotional_price BIGINT,
+    images TEXT[] NOT NULL DEFAULT '{}',
+    videos TEXT[] NOT NULL DEFAULT '{}',
+    last_update TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (building_id, tenant_id) REFERENCES buildings(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_apartments_building_id ON apartments(building_id);
+
+-- Index tenant_id so every scoped query can use it
+CREATE INDEX idx_neighborhoods_tenant_id ON neighborhoods(tenant_id);
+CREATE INDEX idx_buildings_tenant_id ON buildings(tenant_id);
+CREATE INDEX idx_users_tenant_id ON users(tenant_id);
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
+CREATE INDEX idx_inquiries_tenant_id ON inquiries(tenant_id);
+CREATE INDEX idx_webhook_subscriptions_tenant_id ON webhook_subscriptions(tenant_id);
+CREATE INDEX idx_webhook_deliveries_tenant_id ON webhook_deliveries(tenant_id);
+CREATE INDEX idx_audit_log_tenant_id ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX idx_apartments_tenant_id ON apartments(tenant_id);
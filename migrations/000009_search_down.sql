@@ -0,0 +1,8 @@
+-- Drop search indexes and vectors; the pg_trgm extension is left installed
+DROP INDEX IF EXISTS idx_buildings_address_trgm;
+DROP INDEX IF EXISTS idx_buildings_name_trgm;
+DROP INDEX IF EXISTS idx_neighborhoods_name_trgm;
+DROP INDEX IF EXISTS idx_buildings_search_vector;
+DROP INDEX IF EXISTS idx_neighborhoods_search_vector;
+ALTER TABLE buildings DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE neighborhoods DROP COLUMN IF EXISTS search_vector;
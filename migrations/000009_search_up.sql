@@ -0,0 +1,17 @@
+-- Enable trigram matching for typo-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full-text vectors are generated columns, so Postgres keeps them current on every write.
+-- The 'simple' configuration does not stem, which suits names and street addresses.
+ALTER TABLE neighborhoods ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED;
+ALTER TABLE buildings ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', address), 'B')) STORED;
+
+CREATE INDEX idx_neighborhoods_search_vector ON neighborhoods USING GIN (search_vector);
+CREATE INDEX idx_buildings_search_vector ON buildings USING GIN (search_vector);
+
+-- Trigram indexes back the fuzzy matching of misspelled terms
+CREATE INDEX idx_neighborhoods_name_trgm ON neighborhoods USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_buildings_name_trgm ON buildings USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_buildings_address_trgm ON buildings USING GIN (address gin_trgm_ops);
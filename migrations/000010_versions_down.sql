@@ -0,0 +1,4 @@
+-- Drop row versions
+ALTER TABLE apartments DROP COLUMN IF EXISTS version;
+ALTER TABLE buildings DROP COLUMN IF EXISTS version;
+ALTER TABLE neighborhoods DROP COLUMN IF EXISTS version;
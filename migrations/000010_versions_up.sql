@@ -0,0 +1,4 @@
+-- Count saved changes per row for optimistic concurrency control
+ALTER TABLE neighborhoods ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE buildings ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE apartments ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+-- Drop soft-deleted rows, then the columns that mark them
+DELETE FROM apartments WHERE deleted_at IS NOT NULL;
+DELETE FROM buildings WHERE deleted_at IS NOT NULL;
+DELETE FROM neighborhoods WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_apartments_deleted_at;
+DROP INDEX IF EXISTS idx_buildings_deleted_at;
+DROP INDEX IF EXISTS idx_neighborhoods_deleted_at;
+ALTER TABLE apartments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE buildings DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE neighborhoods DROP COLUMN IF EXISTS deleted_at;
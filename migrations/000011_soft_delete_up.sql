@@ -0,0 +1,9 @@
+-- Deleted listings stay in the trash until purged. Rows deleted together share
+-- one deleted_at (the deleting transaction's now()), so they are restored together.
+ALTER TABLE neighborhoods ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE buildings ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE apartments ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_neighborhoods_deleted_at ON neighborhoods(tenant_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_buildings_deleted_at ON buildings(tenant_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_apartments_deleted_at ON apartments(tenant_id, deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Drop imports and their error rows
+DROP INDEX IF EXISTS idx_imports_tenant_id;
+DROP TABLE IF EXISTS import_errors;
+DROP TABLE IF EXISTS imports;
@@ -0,0 +1,27 @@
+-- Create imports table recording every CSV import, dry runs included
+CREATE TABLE imports (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity TEXT NOT NULL CHECK (entity IN ('neighborhoods', 'buildings', 'apartments')),
+    dry_run BOOLEAN NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('validated', 'completed', 'failed')),
+    total_rows INT NOT NULL,
+    created INT NOT NULL,
+    updated INT NOT NULL,
+    unchanged INT NOT NULL,
+    failed INT NOT NULL,
+    header TEXT[] NOT NULL,
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Rows of an import that failed validation, as they were uploaded
+CREATE TABLE import_errors (
+    import_id UUID NOT NULL REFERENCES imports(id) ON DELETE CASCADE,
+    line INT NOT NULL,
+    cells TEXT[] NOT NULL,
+    error TEXT NOT NULL,
+    PRIMARY KEY (import_id, line)
+);
+
+CREATE INDEX idx_imports_tenant_id ON imports(tenant_id, created_at DESC);
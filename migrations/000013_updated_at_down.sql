@@ -0,0 +1,7 @@
+-- Drop the modification times of listing rows
+DROP INDEX IF EXISTS idx_apartments_updated_at;
+DROP INDEX IF EXISTS idx_buildings_updated_at;
+DROP INDEX IF EXISTS idx_neighborhoods_updated_at;
+ALTER TABLE apartments DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE buildings DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE neighborhoods DROP COLUMN IF EXISTS updated_at;
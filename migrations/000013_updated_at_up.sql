@@ -0,0 +1,13 @@
+-- Record when each listing row last changed, deletions and restores included,
+-- so responses can carry an accurate Last-Modified
+ALTER TABLE neighborhoods ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE buildings ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE apartments ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Apartments already knew when they were last edited or deleted
+UPDATE apartments SET updated_at = GREATEST(last_update, deleted_at);
+
+-- Serve the latest change per tenant from the index
+CREATE INDEX idx_neighborhoods_updated_at ON neighborhoods(tenant_id, updated_at DESC);
+CREATE INDEX idx_buildings_updated_at ON buildings(tenant_id, updated_at DESC);
+CREATE INDEX idx_apartments_updated_at ON apartments(tenant_id, updated_at DESC);
@@ -0,0 +1,2 @@
+-- Drop the shared rate limit buckets
+DROP TABLE IF EXISTS rate_limit_buckets;
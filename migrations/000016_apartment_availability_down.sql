@@ -0,0 +1,3 @@
+-- Drop apartment availability
+DROP INDEX IF EXISTS idx_apartments_available;
+ALTER TABLE apartments DROP COLUMN IF EXISTS available;
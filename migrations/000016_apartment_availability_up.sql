@@ -0,0 +1,6 @@
+-- Apartments are only listed publicly once marked available. Apartments that
+-- were already listed stay available; new ones start out unlisted.
+ALTER TABLE apartments ADD COLUMN available BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE apartments ALTER COLUMN available SET DEFAULT false;
+
+CREATE INDEX idx_apartments_available ON apartments(tenant_id, last_update DESC) WHERE available AND deleted_at IS NULL;
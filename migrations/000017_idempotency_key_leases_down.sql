@@ -0,0 +1,2 @@
+-- Drop idempotency key leases
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
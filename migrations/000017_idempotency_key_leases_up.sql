@@ -0,0 +1,5 @@
+-- A claimed idempotency key is leased to the request running it until
+-- locked_until, so that a retry can take over a key whose request never
+-- finished, such as when the instance running it crashed.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE idempotency_keys ALTER COLUMN locked_until DROP DEFAULT;
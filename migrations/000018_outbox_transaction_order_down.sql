@@ -0,0 +1,4 @@
+-- Drop the transaction order of outbox events
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+ALTER TABLE outbox DROP COLUMN IF EXISTS txid;
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
@@ -0,0 +1,8 @@
+-- Outbox events are published in the order of the transactions that wrote
+-- them. seq is taken when an event is written, so a transaction that commits
+-- late can leave a lower seq behind events already published; txid lets the
+-- dispatcher wait until every transaction older than an event has finished.
+ALTER TABLE outbox ADD COLUMN txid xid8 NOT NULL DEFAULT pg_current_xact_id();
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox(txid, seq) WHERE published_at IS NULL;
@@ -0,0 +1,6 @@
+-- Drop outbox dispatch failures
+DROP INDEX IF EXISTS idx_outbox_parked;
+ALTER TABLE outbox DROP COLUMN IF EXISTS parked_at;
+ALTER TABLE outbox DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE outbox DROP COLUMN IF EXISTS last_error;
+ALTER TABLE outbox DROP COLUMN IF EXISTS attempts;
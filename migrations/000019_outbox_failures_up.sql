@@ -0,0 +1,8 @@
+-- Failed dispatches of an outbox event are retried with backoff and the event
+-- is parked, no longer holding back the events after it, once they run out.
+ALTER TABLE outbox ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE outbox ADD COLUMN last_error TEXT;
+ALTER TABLE outbox ADD COLUMN next_attempt_at TIMESTAMPTZ;
+ALTER TABLE outbox ADD COLUMN parked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_outbox_parked ON outbox(parked_at) WHERE parked_at IS NOT NULL;